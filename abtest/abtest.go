@@ -0,0 +1,103 @@
+package abtest
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Arm is a variant taking part in a test
+type Arm struct {
+	// Weight is the variant's share of traffic relative to the others
+	Weight int
+	// Exports is how often the variant has been served so far
+	Exports int
+}
+
+// Assign picks the arm for a recipient. The same key always gets the
+// same arm as long as the weights do not change.
+func Assign(arms []Arm, key string) int {
+	total := 0
+	for _, arm := range arms {
+		total += arm.Weight
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	point := int(h.Sum64() % uint64(total))
+	for i, arm := range arms {
+		if point < arm.Weight {
+			return i
+		}
+		point -= arm.Weight
+	}
+	return len(arms) - 1
+}
+
+// Next picks the arm for an export without a recipient: the one furthest
+// behind its weighted share, so exports follow the weights exactly rather
+// than at random. Ties go to the earlier arm.
+func Next(arms []Arm) int {
+	best := 0
+	for i, arm := range arms {
+		if arm.Weight <= 0 {
+			continue
+		}
+		// Compare (exports+1)/weight without floating point
+		if arms[best].Weight <= 0 || (arm.Exports+1)*arms[best].Weight < (arms[best].Exports+1)*arm.Weight {
+			best = i
+		}
+	}
+	return best
+}
+
+// Confidence is the confidence level results must reach to be reported
+// as significant
+const Confidence = 0.95
+
+// MinSample is the fewest exports per arm before significance is reported;
+// the normal approximation is unreliable below it
+const MinSample = 30
+
+// Significance compares a variant's click-through rate with the control's
+type Significance struct {
+	ZScore      float64 `json:"z_score"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// Rate is the click-through rate, capped at 1 since one export can be
+// clicked more than once
+func Rate(clicks, exports int) float64 {
+	if exports <= 0 {
+		return 0
+	}
+	return math.Min(float64(clicks)/float64(exports), 1)
+}
+
+// Compare runs a two-sided two-proportion z-test of the variant's
+// click-through rate against the control's
+func Compare(controlClicks, controlExports, clicks, exports int) Significance {
+	if controlExports <= 0 || exports <= 0 {
+		return Significance{PValue: 1}
+	}
+
+	p1 := Rate(controlClicks, controlExports)
+	p2 := Rate(clicks, exports)
+	n1, n2 := float64(controlExports), float64(exports)
+	pooled := (p1*n1 + p2*n2) / (n1 + n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if se == 0 {
+		return Significance{PValue: 1}
+	}
+
+	z := (p2 - p1) / se
+	p := math.Erfc(math.Abs(z) / math.Sqrt2)
+	return Significance{
+		ZScore:      z,
+		PValue:      p,
+		Significant: p < 1-Confidence && controlExports >= MinSample && exports >= MinSample,
+	}
+}
@@ -0,0 +1,96 @@
+package abtest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	arms := []Arm{{Weight: 70}, {Weight: 30}}
+	counts := make([]int, len(arms))
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("recipient-%d@example.com", i)
+		arm := Assign(arms, key)
+		if Assign(arms, key) != arm {
+			t.Fatalf("%s got different arms", key)
+		}
+		counts[arm]++
+	}
+	if counts[0] < 6700 || counts[0] > 7300 {
+		t.Errorf("counts = %v, want about 70/30", counts)
+	}
+
+	if got := Assign([]Arm{{Weight: 0}, {Weight: 5}}, "a"); got != 1 {
+		t.Errorf("Assign = %d, want the only weighted arm", got)
+	}
+	if got := Assign([]Arm{{Weight: 0}, {Weight: 0}}, "a"); got != 0 {
+		t.Errorf("Assign without weights = %d, want the first arm", got)
+	}
+}
+
+func TestNextFollowsWeights(t *testing.T) {
+	arms := []Arm{{Weight: 2}, {Weight: 1}, {Weight: 0}}
+	var order []int
+	for i := 0; i < 6; i++ {
+		arm := Next(arms)
+		arms[arm].Exports++
+		order = append(order, arm)
+	}
+	if fmt.Sprint(order) != "[0 0 1 0 0 1]" {
+		t.Errorf("order = %v, want exports in the 2:1 ratio", order)
+	}
+	if arms[2].Exports != 0 {
+		t.Errorf("an arm without weight was served")
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		clicks, exports int
+		want            float64
+	}{
+		{5, 100, 0.05},
+		{0, 0, 0},
+		{150, 100, 1},
+	}
+	for _, tt := range tests {
+		if got := Rate(tt.clicks, tt.exports); got != tt.want {
+			t.Errorf("Rate(%d, %d) = %v, want %v", tt.clicks, tt.exports, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name                          string
+		controlClicks, controlExports int
+		clicks, exports               int
+		wantZ                         float64
+		wantSignificant               bool
+	}{
+		// 10% against 15% over 1000 exports each: z = 3.38, p = 0.0007
+		{"clear winner", 100, 1000, 150, 1000, 3.38, true},
+		{"no difference", 100, 1000, 100, 1000, 0, false},
+		{"small difference", 100, 1000, 110, 1000, 0.73, false},
+		// A large effect on too few exports is not reported
+		{"below the minimum sample", 1, 20, 10, 20, 3.19, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.controlClicks, tt.controlExports, tt.clicks, tt.exports)
+			if math.Abs(got.ZScore-tt.wantZ) > 0.01 || got.Significant != tt.wantSignificant {
+				t.Errorf("Compare = %+v, want z %.2f significant %v", got, tt.wantZ, tt.wantSignificant)
+			}
+			if got.PValue < 0 || got.PValue > 1 {
+				t.Errorf("p-value %v out of range", got.PValue)
+			}
+		})
+	}
+
+	for _, got := range []Significance{Compare(0, 0, 5, 10), Compare(0, 50, 0, 50), Compare(50, 50, 50, 50)} {
+		if got != (Significance{PValue: 1}) {
+			t.Errorf("degenerate comparison = %+v, want p = 1", got)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxUploadBytes is the largest file accepted for upload
+const MaxUploadBytes = 2 << 20
+
+// maxSourcePixels guards against decompression bombs: the image header is
+// checked before any pixel data is decoded
+const maxSourcePixels = 25_000_000
+
+// RetinaScale is how many image pixels back each CSS pixel, so logos stay
+// sharp on high-density screens
+const RetinaScale = 2
+
+// Kind describes how an uploaded image is displayed in a signature
+type Kind struct {
+	Name string
+	// MaxDisplayWidth and MaxDisplayHeight bound the size, in CSS pixels,
+	// the image is shown at
+	MaxDisplayWidth  int
+	MaxDisplayHeight int
+	// MinWidth and MinHeight are the smallest source images accepted
+	MinWidth  int
+	MinHeight int
+	// Square crops the image to its centered square
+	Square bool
+}
+
+// Kinds are the supported asset kinds keyed by name
+var Kinds = map[string]Kind{
+	"logo": {
+		Name:             "logo",
+		MaxDisplayWidth:  200,
+		MaxDisplayHeight: 80,
+		MinWidth:         64,
+		MinHeight:        16,
+	},
+	"headshot": {
+		Name:             "headshot",
+		MaxDisplayWidth:  80,
+		MaxDisplayHeight: 80,
+		MinWidth:         120,
+		MinHeight:        120,
+		Square:           true,
+	},
+	"banner": {
+		Name:             "banner",
+		MaxDisplayWidth:  600,
+		MaxDisplayHeight: 200,
+		MinWidth:         300,
+		MinHeight:        50,
+	},
+}
+
+// Errors returned for invalid uploads
+var (
+	ErrTooLarge           = fmt.Errorf("file exceeds the %d byte upload limit", MaxUploadBytes)
+	ErrUnsupportedType    = errors.New("only PNG, JPEG and GIF images are supported")
+	ErrDimensionsTooLarge = errors.New("image dimensions are too large")
+)
+
+// Image is an uploaded image prepared for use in signatures
+type Image struct {
+	// ContentType is the sniffed type of the original upload
+	ContentType string
+	Width       int
+	Height      int
+	// Display is the resized image, RetinaScale times its display size
+	Display            []byte
+	DisplayContentType string
+	// DisplayWidth and DisplayHeight are the CSS pixel dimensions to put in
+	// the <img> width and height attributes
+	DisplayWidth  int
+	DisplayHeight int
+}
+
+// Extension returns the file extension for a content type
+func Extension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	}
+	return ""
+}
+
+// Process validates an uploaded image and produces its display version
+func Process(data []byte, kind Kind) (*Image, error) {
+	if len(data) > MaxUploadBytes {
+		return nil, ErrTooLarge
+	}
+
+	// Trust the bytes rather than the client-supplied content type
+	contentType := http.DetectContentType(data)
+	if Extension(contentType) == "" {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if config.Width*config.Height > maxSourcePixels {
+		return nil, ErrDimensionsTooLarge
+	}
+	if config.Width < kind.MinWidth || config.Height < kind.MinHeight {
+		return nil, fmt.Errorf("%s must be at least %dx%d pixels", kind.Name, kind.MinWidth, kind.MinHeight)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	bounds := src.Bounds()
+	if kind.Square {
+		bounds = centeredSquare(bounds)
+	}
+
+	displayWidth, displayHeight := fit(bounds.Dx()/RetinaScale, bounds.Dy()/RetinaScale, kind.MaxDisplayWidth, kind.MaxDisplayHeight)
+	dst := image.NewNRGBA(image.Rect(0, 0, displayWidth*RetinaScale, displayHeight*RetinaScale))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	// JPEG sources stay JPEG for size, anything else may be transparent
+	var buf bytes.Buffer
+	displayContentType := "image/png"
+	if contentType == "image/jpeg" {
+		displayContentType = contentType
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 88})
+	} else {
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{
+		ContentType:        contentType,
+		Width:              config.Width,
+		Height:             config.Height,
+		Display:            buf.Bytes(),
+		DisplayContentType: displayContentType,
+		DisplayWidth:       displayWidth,
+		DisplayHeight:      displayHeight,
+	}, nil
+}
+
+// fit scales width and height down, preserving the aspect ratio, so they
+// fit within maxWidth and maxHeight; images are never scaled up
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if height > maxHeight && float64(maxHeight)/float64(height) < scale {
+		scale = float64(maxHeight) / float64(height)
+	}
+
+	w := int(float64(width)*scale + 0.5)
+	h := int(float64(height)*scale + 0.5)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+func centeredSquare(r image.Rectangle) image.Rectangle {
+	size := r.Dx()
+	if r.Dy() < size {
+		size = r.Dy()
+	}
+	x := r.Min.X + (r.Dx()-size)/2
+	y := r.Min.Y + (r.Dy()-size)/2
+	return image.Rect(x, y, x+size, y+size)
+}
@@ -0,0 +1,150 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// encode draws a width x height image and encodes it as contentType
+func encode(t *testing.T, contentType string, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/png":
+		err = png.Encode(&buf, img)
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "image/gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		t.Fatalf("unknown content type %s", contentType)
+	}
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessResizesForRetina(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		kind        string
+		width       int
+		height      int
+		// displayWidth and displayHeight are the expected CSS sizes
+		displayWidth  int
+		displayHeight int
+		displayType   string
+	}{
+		{"large logo is scaled to fit", "image/png", "logo", 800, 200, 200, 50, "image/png"},
+		{"tall logo is bounded by height", "image/png", "logo", 200, 400, 40, 80, "image/png"},
+		{"small logo is not scaled up", "image/png", "logo", 100, 40, 50, 20, "image/png"},
+		{"jpeg stays jpeg", "image/jpeg", "banner", 1200, 200, 600, 100, "image/jpeg"},
+		{"gif becomes png", "image/gif", "logo", 128, 32, 64, 16, "image/png"},
+		{"headshot is cropped square", "image/png", "headshot", 400, 300, 80, 80, "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Process(encode(t, tt.contentType, tt.width, tt.height), Kinds[tt.kind])
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if img.ContentType != tt.contentType || img.Width != tt.width || img.Height != tt.height {
+				t.Errorf("original = %s %dx%d, want %s %dx%d", img.ContentType, img.Width, img.Height, tt.contentType, tt.width, tt.height)
+			}
+			if img.DisplayWidth != tt.displayWidth || img.DisplayHeight != tt.displayHeight {
+				t.Errorf("display size = %dx%d, want %dx%d", img.DisplayWidth, img.DisplayHeight, tt.displayWidth, tt.displayHeight)
+			}
+			if img.DisplayContentType != tt.displayType {
+				t.Errorf("display type = %s, want %s", img.DisplayContentType, tt.displayType)
+			}
+
+			display, _, err := image.DecodeConfig(bytes.NewReader(img.Display))
+			if err != nil {
+				t.Fatalf("decode display image: %v", err)
+			}
+			if display.Width != tt.displayWidth*RetinaScale || display.Height != tt.displayHeight*RetinaScale {
+				t.Errorf("display image = %dx%d, want %dx%d", display.Width, display.Height, tt.displayWidth*RetinaScale, tt.displayHeight*RetinaScale)
+			}
+		})
+	}
+}
+
+func TestProcessSniffsContent(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="80"></svg>`)
+	if _, err := Process(svg, Kinds["logo"]); err != ErrUnsupportedType {
+		t.Errorf("SVG: err = %v, want ErrUnsupportedType", err)
+	}
+
+	// A PNG signature followed by garbage sniffs as PNG but cannot decode
+	corrupt := append([]byte("\x89PNG\r\n\x1a\n"), strings.Repeat("x", 64)...)
+	if _, err := Process(corrupt, Kinds["logo"]); err != ErrUnsupportedType {
+		t.Errorf("corrupt PNG: err = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestProcessLimits(t *testing.T) {
+	if _, err := Process(make([]byte, MaxUploadBytes+1), Kinds["logo"]); err != ErrTooLarge {
+		t.Errorf("oversized upload: err = %v, want ErrTooLarge", err)
+	}
+
+	_, err := Process(encode(t, "image/png", 100, 100), Kinds["headshot"])
+	if err == nil || !strings.Contains(err.Error(), "at least 120x120") {
+		t.Errorf("small headshot: err = %v, want a minimum size error", err)
+	}
+
+	// The header claims 10000x10000 pixels; it is rejected before decoding
+	if _, err := Process(pngHeader(10000, 10000), Kinds["logo"]); err != ErrDimensionsTooLarge {
+		t.Errorf("huge image: err = %v, want ErrDimensionsTooLarge", err)
+	}
+}
+
+// pngHeader returns the signature and IHDR chunk of an 8-bit RGB PNG,
+// enough for image.DecodeConfig
+func pngHeader(width, height uint32) []byte {
+	chunk := []byte("IHDR")
+	chunk = binary.BigEndian.AppendUint32(chunk, width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 2, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(chunk)-4))
+	data = append(data, chunk...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height, maxWidth, maxHeight int
+		wantWidth, wantHeight              int
+	}{
+		{400, 100, 200, 80, 200, 50},
+		{100, 400, 200, 80, 20, 80},
+		{50, 20, 200, 80, 50, 20},
+		{10000, 1, 200, 80, 200, 1},
+	}
+	for _, tt := range tests {
+		w, h := fit(tt.width, tt.height, tt.maxWidth, tt.maxHeight)
+		if w != tt.wantWidth || h != tt.wantHeight {
+			t.Errorf("fit(%d, %d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.maxWidth, tt.maxHeight, w, h, tt.wantWidth, tt.wantHeight)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"github.com/joho/godotenv"
 	"log"
+	"net/url"
+	"os"
+	"strings"
 )
 
 func LoadConfig() {
@@ -12,3 +15,60 @@ func LoadConfig() {
 		log.Println(".env file not found, using default environment variables")
 	}
 }
+
+// PublicBaseURL returns the externally reachable URL of this API, used for
+// links that end up inside exported signatures
+func PublicBaseURL() string {
+	baseURL := os.Getenv("PUBLIC_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://email-signature-backend.onrender.com"
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// ProxyIPHeader returns the header a reverse proxy puts the client IP in,
+// such as X-Forwarded-For, so clicks record the recipient rather than the
+// proxy. The header is only read from TrustedProxies, since clients can
+// send it themselves.
+func ProxyIPHeader() string {
+	return os.Getenv("PROXY_IP_HEADER")
+}
+
+// TrustedProxies returns the IP addresses and CIDR ranges of the reverse
+// proxies in TRUSTED_PROXIES, separated by commas. Forwarded headers from
+// any other address are ignored.
+func TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// ShortLinkBaseURL returns the base of tracked link URLs: the short domain
+// in SHORT_LINK_DOMAIN when set, else this API's /r path
+func ShortLinkBaseURL() string {
+	domain := os.Getenv("SHORT_LINK_DOMAIN")
+	if domain == "" {
+		return PublicBaseURL() + "/r"
+	}
+	if !strings.Contains(domain, "://") {
+		domain = "https://" + domain
+	}
+	return strings.TrimRight(domain, "/")
+}
+
+// ShortLinkHost returns the host of the short link domain, or an empty
+// string when none is configured
+func ShortLinkHost() string {
+	if os.Getenv("SHORT_LINK_DOMAIN") == "" {
+		return ""
+	}
+	u, err := url.Parse(ShortLinkBaseURL())
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,2 @@
+ALTER TABLE signatures DROP COLUMN IF EXISTS brand_kit_id;
+DROP TABLE IF EXISTS brand_kits;
@@ -0,0 +1,20 @@
+-- Brand Kits Table
+CREATE TABLE brand_kits (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    palette JSONB NOT NULL DEFAULT '{}',
+    font_stack TEXT NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    logo_width INTEGER NOT NULL DEFAULT 0,
+    logo_height INTEGER NOT NULL DEFAULT 0,
+    icon_style VARCHAR(20) NOT NULL DEFAULT 'text',
+    link_color VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    -- A brand kit belongs to exactly one user or one organization
+    CHECK ((user_id IS NULL) <> (organization_id IS NULL))
+);
+
+ALTER TABLE signatures ADD COLUMN brand_kit_id UUID REFERENCES brand_kits(id) ON DELETE SET NULL;
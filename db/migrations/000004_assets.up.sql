@@ -0,0 +1,15 @@
+-- Assets Table
+CREATE TABLE assets (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    byte_size INTEGER NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    display_width INTEGER NOT NULL,
+    display_height INTEGER NOT NULL,
+    original_key TEXT NOT NULL,
+    display_key TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
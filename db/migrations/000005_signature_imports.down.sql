@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS import_mappings;
+DROP INDEX IF EXISTS signatures_user_employee_email_idx;
+ALTER TABLE signatures DROP COLUMN IF EXISTS employee_email;
@@ -0,0 +1,14 @@
+-- Employee email identifying signatures created by roster imports
+ALTER TABLE signatures ADD COLUMN employee_email VARCHAR(255);
+CREATE UNIQUE INDEX signatures_user_employee_email_idx ON signatures (user_id, employee_email) WHERE employee_email IS NOT NULL;
+
+-- Saved roster column mappings
+CREATE TABLE import_mappings (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    columns JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
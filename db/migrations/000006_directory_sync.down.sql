@@ -0,0 +1,4 @@
+ALTER TABLE signatures DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE signatures DROP COLUMN IF EXISTS directory_id;
+DROP TABLE IF EXISTS directory_sync_runs;
+DROP TABLE IF EXISTS directories;
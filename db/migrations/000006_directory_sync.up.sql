@@ -0,0 +1,41 @@
+-- Directories Table
+CREATE TABLE directories (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    bind_dn TEXT NOT NULL DEFAULT '',
+    bind_password TEXT NOT NULL DEFAULT '',
+    base_dn TEXT NOT NULL,
+    filter TEXT NOT NULL DEFAULT '',
+    start_tls BOOLEAN NOT NULL DEFAULT FALSE,
+    attributes JSONB NOT NULL,
+    brand_kit_id UUID REFERENCES brand_kits(id) ON DELETE SET NULL,
+    interval_minutes INTEGER NOT NULL DEFAULT 60,
+    last_synced_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Directory Sync Runs Table
+CREATE TABLE directory_sync_runs (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    directory_id UUID REFERENCES directories(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP DEFAULT NOW(),
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    users INTEGER NOT NULL DEFAULT 0,
+    created INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    archived INTEGER NOT NULL DEFAULT 0,
+    restored INTEGER NOT NULL DEFAULT 0,
+    unchanged INTEGER NOT NULL DEFAULT 0,
+    changes JSONB NOT NULL DEFAULT '[]',
+    skipped JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX directory_sync_runs_directory_idx ON directory_sync_runs (directory_id, started_at DESC);
+
+-- Signatures synced from a directory, and archived ones
+ALTER TABLE signatures ADD COLUMN directory_id UUID REFERENCES directories(id) ON DELETE SET NULL;
+ALTER TABLE signatures ADD COLUMN archived_at TIMESTAMP;
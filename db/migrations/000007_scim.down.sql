@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS scim_group_members;
+DROP TABLE IF EXISTS scim_groups;
+DROP TABLE IF EXISTS employees;
+DROP TABLE IF EXISTS scim_tokens;
+ALTER TABLE signatures DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE signatures DROP COLUMN IF EXISTS template;
+ALTER TABLE organizations DROP COLUMN IF EXISTS default_brand_kit_id;
+ALTER TABLE organizations DROP COLUMN IF EXISTS default_template;
@@ -0,0 +1,55 @@
+-- Defaults for signatures generated for an organization's employees
+ALTER TABLE organizations ADD COLUMN default_template VARCHAR(50) NOT NULL DEFAULT 'basic';
+ALTER TABLE organizations ADD COLUMN default_brand_kit_id UUID REFERENCES brand_kits(id) ON DELETE SET NULL;
+
+-- Template a signature renders with when none is requested
+ALTER TABLE signatures ADD COLUMN template VARCHAR(50);
+ALTER TABLE signatures ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- SCIM Tokens Table; only a SHA-256 hash of each token is stored
+CREATE TABLE scim_tokens (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE CASCADE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+-- Employees Table, provisioned over SCIM
+CREATE TABLE employees (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL DEFAULT '',
+    user_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    given_name VARCHAR(255) NOT NULL DEFAULT '',
+    family_name VARCHAR(255) NOT NULL DEFAULT '',
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    department VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    signature_id UUID REFERENCES signatures(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (organization_id, user_name)
+);
+
+-- SCIM Groups Tables
+CREATE TABLE scim_groups (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL DEFAULT '',
+    display_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (organization_id, display_name)
+);
+
+CREATE TABLE scim_group_members (
+    group_id UUID REFERENCES scim_groups(id) ON DELETE CASCADE,
+    employee_id UUID REFERENCES employees(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, employee_id)
+);
@@ -0,0 +1,32 @@
+-- Deploy Jobs Table
+CREATE TABLE deploy_jobs (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    template VARCHAR(50) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    total INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+-- Deployments Table, one per mailbox in a job
+CREATE TABLE deployments (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    job_id UUID REFERENCES deploy_jobs(id) ON DELETE CASCADE,
+    signature_id UUID REFERENCES signatures(id) ON DELETE SET NULL,
+    mailbox VARCHAR(255) NOT NULL,
+    html TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deployed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX deployments_job_idx ON deployments (job_id);
+CREATE INDEX deployments_due_idx ON deployments (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS signatures_master_template_idx;
+ALTER TABLE signatures DROP COLUMN IF EXISTS region_overrides;
+ALTER TABLE signatures DROP COLUMN IF EXISTS master_template_id;
+DROP TABLE IF EXISTS master_templates;
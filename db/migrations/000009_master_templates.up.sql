@@ -0,0 +1,17 @@
+-- Master Templates Table
+CREATE TABLE master_templates (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    regions JSONB NOT NULL,
+    placeholder_values JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Employee signatures that render from a master template keep only their
+-- personal fields in template_data
+ALTER TABLE signatures ADD COLUMN master_template_id UUID REFERENCES master_templates(id) ON DELETE RESTRICT;
+ALTER TABLE signatures ADD COLUMN region_overrides JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX signatures_master_template_idx ON signatures (master_template_id) WHERE master_template_id IS NOT NULL;
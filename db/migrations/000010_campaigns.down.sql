@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS links_campaign_signature_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaign_signatures;
+DROP TABLE IF EXISTS campaigns;
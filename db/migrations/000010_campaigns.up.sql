@@ -0,0 +1,33 @@
+-- Campaigns Table; banners shown below targeted signatures between
+-- starts_at and ends_at
+CREATE TABLE campaigns (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    image_url TEXT NOT NULL,
+    image_width INTEGER NOT NULL,
+    image_height INTEGER NOT NULL,
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    link_url TEXT NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    audience VARCHAR(20) NOT NULL,
+    department VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX campaigns_schedule_idx ON campaigns (starts_at, ends_at);
+
+-- Signatures targeted by campaigns with the signatures audience
+CREATE TABLE campaign_signatures (
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE CASCADE,
+    signature_id UUID REFERENCES signatures(id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_id, signature_id)
+);
+
+-- Tracked banner links, one per campaign and signature
+ALTER TABLE links ADD COLUMN campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX links_campaign_signature_idx ON links (campaign_id, signature_id) WHERE campaign_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS links_variant_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS signature_variants;
@@ -0,0 +1,22 @@
+-- Signature Variants Table; A/B test arms of a signature
+CREATE TABLE signature_variants (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    signature_id UUID REFERENCES signatures(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 1,
+    template VARCHAR(50) NOT NULL DEFAULT '',
+    template_data JSONB NOT NULL DEFAULT '{}',
+    banner JSONB,
+    exports INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX signature_variants_signature_idx ON signature_variants (signature_id, created_at);
+
+-- Variant each click came from
+ALTER TABLE clicks ADD COLUMN variant_id UUID REFERENCES signature_variants(id) ON DELETE SET NULL;
+
+-- Tracked links of variant banners, one per variant
+ALTER TABLE links ADD COLUMN variant_id UUID REFERENCES signature_variants(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX links_variant_idx ON links (variant_id) WHERE variant_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE master_templates DROP COLUMN IF EXISTS content_rules;
+ALTER TABLE signatures DROP COLUMN IF EXISTS content_rules;
+DROP TABLE IF EXISTS disclaimers;
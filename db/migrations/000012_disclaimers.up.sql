@@ -0,0 +1,16 @@
+-- Disclaimers Table; reusable legal text managed per organization
+CREATE TABLE disclaimers (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX disclaimers_organization_idx ON disclaimers (organization_id);
+
+-- Conditional footer content of signatures, and of every signature based
+-- on a master template
+ALTER TABLE signatures ADD COLUMN content_rules JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE master_templates ADD COLUMN content_rules JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,5 @@
+ALTER TABLE brand_kits DROP COLUMN IF EXISTS dark_logo_height;
+ALTER TABLE brand_kits DROP COLUMN IF EXISTS dark_logo_width;
+ALTER TABLE brand_kits DROP COLUMN IF EXISTS dark_logo_url;
+ALTER TABLE brand_kits DROP COLUMN IF EXISTS dark_link_color;
+ALTER TABLE brand_kits DROP COLUMN IF EXISTS dark_palette;
@@ -0,0 +1,6 @@
+-- Dark-mode colors and logo of brand kits
+ALTER TABLE brand_kits ADD COLUMN dark_palette JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE brand_kits ADD COLUMN dark_link_color VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE brand_kits ADD COLUMN dark_logo_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE brand_kits ADD COLUMN dark_logo_width INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE brand_kits ADD COLUMN dark_logo_height INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Request details of clicks recorded by the public redirect
+ALTER TABLE clicks ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN referrer TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS organizations_link_prefix_idx;
+ALTER TABLE organizations DROP COLUMN IF EXISTS link_prefix;
+DROP INDEX IF EXISTS links_slug_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE links DROP COLUMN IF EXISTS slug;
+DROP INDEX IF EXISTS links_code_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS code;
+DROP FUNCTION IF EXISTS random_base62(INTEGER);
@@ -0,0 +1,25 @@
+-- Random base62 strings for short link codes
+CREATE FUNCTION random_base62(length INTEGER) RETURNS TEXT AS $$
+    SELECT string_agg(substr('0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz', floor(random() * 62)::INTEGER + 1, 1), '')
+    FROM generate_series(1, length)
+$$ LANGUAGE SQL VOLATILE;
+
+-- Short codes addressing links in tracked URLs; inserts that collide with
+-- an existing code are retried
+ALTER TABLE links ADD COLUMN code VARCHAR(16);
+UPDATE links SET code = random_base62(8);
+ALTER TABLE links ALTER COLUMN code SET NOT NULL;
+ALTER TABLE links ALTER COLUMN code SET DEFAULT random_base62(7);
+CREATE UNIQUE INDEX links_code_idx ON links (code);
+
+-- Vanity slugs, unique per organization
+ALTER TABLE links ADD COLUMN slug VARCHAR(64);
+ALTER TABLE links ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX links_slug_idx ON links (organization_id, slug) WHERE slug IS NOT NULL;
+
+-- Prefix of an organization's vanity URLs, e.g. /r/<prefix>/<slug>
+ALTER TABLE organizations ADD COLUMN link_prefix VARCHAR(16);
+UPDATE organizations SET link_prefix = random_base62(6);
+ALTER TABLE organizations ALTER COLUMN link_prefix SET NOT NULL;
+ALTER TABLE organizations ALTER COLUMN link_prefix SET DEFAULT random_base62(6);
+CREATE UNIQUE INDEX organizations_link_prefix_idx ON organizations (link_prefix);
@@ -0,0 +1,8 @@
+ALTER TABLE clicks DROP CONSTRAINT clicks_link_id_fkey;
+ALTER TABLE clicks ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id);
+DROP INDEX IF EXISTS links_signature_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE links DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE links DROP COLUMN IF EXISTS fallback_url;
+ALTER TABLE links DROP COLUMN IF EXISTS max_clicks;
+ALTER TABLE links DROP COLUMN IF EXISTS expires_at;
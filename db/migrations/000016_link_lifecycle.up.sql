@@ -0,0 +1,15 @@
+-- Links stop redirecting to their destination after expires_at or once
+-- they have max_clicks clicks, and serve fallback_url instead
+ALTER TABLE links ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE links ADD COLUMN max_clicks INTEGER CHECK (max_clicks > 0);
+ALTER TABLE links ADD COLUMN fallback_url TEXT;
+
+-- Archived links are hidden from listings but keep redirecting
+ALTER TABLE links ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE links ADD COLUMN updated_at TIMESTAMP DEFAULT NOW();
+
+CREATE INDEX links_signature_idx ON links (signature_id);
+
+-- Deleting a link deletes its clicks
+ALTER TABLE clicks DROP CONSTRAINT clicks_link_id_fkey;
+ALTER TABLE clicks ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS opens;
+ALTER TABLE signatures DROP COLUMN IF EXISTS track_opens;
@@ -0,0 +1,16 @@
+-- Signatures can embed a tracking pixel recording opens
+ALTER TABLE signatures ADD COLUMN track_opens BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE opens (
+    id UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    signature_id UUID NOT NULL REFERENCES signatures(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES signature_variants(id) ON DELETE SET NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    -- Image proxy that fetched the pixel, e.g. gmail or apple_mail; NULL
+    -- for opens by the recipient's mail client
+    proxy TEXT,
+    timestamp TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX opens_signature_idx ON opens (signature_id, timestamp);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS clicks_campaign_idx;
+ALTER TABLE clicks DROP COLUMN IF EXISTS campaign;
+ALTER TABLE links DROP COLUMN IF EXISTS utm;
+ALTER TABLE signatures DROP COLUMN IF EXISTS utm;
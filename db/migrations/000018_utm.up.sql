@@ -0,0 +1,8 @@
+-- UTM parameters merged into link destinations at click time; a link's
+-- own parameters win over its signature's
+ALTER TABLE signatures ADD COLUMN utm JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE links ADD COLUMN utm JSONB NOT NULL DEFAULT '{}';
+
+-- UTM campaign each click was attributed to
+ALTER TABLE clicks ADD COLUMN campaign TEXT;
+CREATE INDEX clicks_campaign_idx ON clicks (campaign) WHERE campaign IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS clicks_ip_timestamp_idx;
+ALTER TABLE clicks DROP COLUMN IF EXISTS traffic_reason;
+ALTER TABLE clicks DROP COLUMN IF EXISTS traffic;
@@ -0,0 +1,7 @@
+-- Traffic each click came from: human, bot or scanner, and the rule that
+-- decided it. Clicks recorded before classification count as human.
+ALTER TABLE clicks ADD COLUMN traffic TEXT NOT NULL DEFAULT 'human';
+ALTER TABLE clicks ADD COLUMN traffic_reason TEXT;
+
+-- Finds bursts of clicks from one address
+CREATE INDEX clicks_ip_timestamp_idx ON clicks (ip_address, timestamp);
@@ -0,0 +1,6 @@
+ALTER TABLE clicks DROP COLUMN IF EXISTS browser;
+ALTER TABLE clicks DROP COLUMN IF EXISTS os;
+ALTER TABLE clicks DROP COLUMN IF EXISTS device_type;
+ALTER TABLE clicks DROP COLUMN IF EXISTS city;
+ALTER TABLE clicks DROP COLUMN IF EXISTS region;
+ALTER TABLE clicks DROP COLUMN IF EXISTS country;
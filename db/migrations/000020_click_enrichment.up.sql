@@ -0,0 +1,7 @@
+-- Where and with what each click was made, looked up at ingest
+ALTER TABLE clicks ADD COLUMN country TEXT;
+ALTER TABLE clicks ADD COLUMN region TEXT;
+ALTER TABLE clicks ADD COLUMN city TEXT;
+ALTER TABLE clicks ADD COLUMN device_type TEXT;
+ALTER TABLE clicks ADD COLUMN os TEXT;
+ALTER TABLE clicks ADD COLUMN browser TEXT;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS opens_timestamp_idx;
+DROP INDEX IF EXISTS clicks_timestamp_idx;
+DROP TABLE IF EXISTS tracking_salts;
+ALTER TABLE organizations DROP COLUMN IF EXISTS click_retention_days;
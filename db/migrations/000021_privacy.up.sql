@@ -0,0 +1,13 @@
+-- Days an organization keeps clicks and opens; NULL falls back to
+-- CLICK_RETENTION_DAYS
+ALTER TABLE organizations ADD COLUMN click_retention_days INTEGER CHECK (click_retention_days > 0);
+
+-- Secret salt of each day for hashing IP addresses; past days are deleted
+CREATE TABLE tracking_salts (
+    day DATE PRIMARY KEY,
+    salt BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX clicks_timestamp_idx ON clicks (timestamp);
+CREATE INDEX opens_timestamp_idx ON opens (timestamp);
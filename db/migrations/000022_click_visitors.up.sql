@@ -0,0 +1,4 @@
+-- Keyed hash of the visitor's IP address and user agent with the salt of
+-- the day, so repeat clicks by one person on one day count once in
+-- unique clicks. NULL for clicks with Do-Not-Track and older clicks.
+ALTER TABLE clicks ADD COLUMN visitor_key TEXT;
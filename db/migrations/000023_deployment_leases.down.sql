@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS deployments_claimed_idx;
+ALTER TABLE deployments DROP COLUMN IF EXISTS claimed_at;
+ALTER TABLE deployments DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,6 @@
+-- The worker holding a running deployment and when it last renewed its
+-- lease; deployments whose lease expired are requeued
+ALTER TABLE deployments ADD COLUMN claimed_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN claimed_at TIMESTAMP;
+
+CREATE INDEX deployments_claimed_idx ON deployments (claimed_at) WHERE status = 'running';
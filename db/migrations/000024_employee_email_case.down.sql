@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS signatures_user_employee_email_idx;
+CREATE UNIQUE INDEX signatures_user_employee_email_idx ON signatures (user_id, employee_email) WHERE employee_email IS NOT NULL;
@@ -0,0 +1,19 @@
+-- A user's employee emails are unique whatever their case. Where two of a
+-- user's signatures differ only in the case of their email, the active or
+-- else oldest one keeps it and the others are detached from it.
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY user_id, LOWER(employee_email)
+        ORDER BY archived_at IS NOT NULL, created_at, id
+    ) AS rank
+    FROM signatures
+    WHERE employee_email IS NOT NULL
+)
+UPDATE signatures SET employee_email = NULL
+FROM ranked
+WHERE signatures.id = ranked.id AND ranked.rank > 1;
+
+UPDATE signatures SET employee_email = LOWER(employee_email) WHERE employee_email <> LOWER(employee_email);
+
+DROP INDEX IF EXISTS signatures_user_employee_email_idx;
+CREATE UNIQUE INDEX signatures_user_employee_email_idx ON signatures (user_id, LOWER(employee_email)) WHERE employee_email IS NOT NULL;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS clicks_ip_timestamp_idx ON clicks (ip_address, timestamp);
@@ -0,0 +1,125 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SignatureDeployer installs signature HTML in a mailbox
+type SignatureDeployer interface {
+	// Name is the provider name deploy jobs refer to
+	Name() string
+	Deploy(ctx context.Context, mailbox, html string) error
+}
+
+// Providers
+const (
+	ProviderGmail    = "gmail"
+	ProviderExchange = "exchange"
+)
+
+// Deployers are the configured deployers keyed by provider name
+var Deployers = map[string]SignatureDeployer{}
+
+// Register makes a deployer available to deploy jobs
+func Register(deployer SignatureDeployer) {
+	Deployers[deployer.Name()] = deployer
+}
+
+// Providers returns the names of the configured providers
+func Providers() []string {
+	names := make([]string, 0, len(Deployers))
+	for name := range Deployers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Setup registers the providers configured in the environment. Gmail needs
+// a service account key with domain-wide delegation in
+// GMAIL_SERVICE_ACCOUNT_FILE; Exchange Online needs an app registration in
+// EXCHANGE_TENANT_ID, EXCHANGE_CLIENT_ID and EXCHANGE_CLIENT_SECRET. The
+// API and token URLs can be overridden to point at local fakes.
+func Setup() {
+	if keyFile := os.Getenv("GMAIL_SERVICE_ACCOUNT_FILE"); keyFile != "" {
+		tokens, err := NewServiceAccountFromFile(keyFile, os.Getenv("GMAIL_TOKEN_URL"))
+		if err != nil {
+			log.Fatalf("Failed to load Gmail service account: %v", err)
+		}
+		Register(&GmailDeployer{BaseURL: os.Getenv("GMAIL_API_BASE_URL"), Tokens: tokens})
+	}
+
+	if clientID := os.Getenv("EXCHANGE_CLIENT_ID"); clientID != "" {
+		tenantID := os.Getenv("EXCHANGE_TENANT_ID")
+		tokenURL := os.Getenv("EXCHANGE_TOKEN_URL")
+		if tokenURL == "" {
+			tokenURL = fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenantID)
+		}
+		Register(&ExchangeDeployer{
+			BaseURL:  os.Getenv("EXCHANGE_API_BASE_URL"),
+			TenantID: tenantID,
+			Tokens: &ClientCredentials{
+				TokenURL:     tokenURL,
+				ClientID:     clientID,
+				ClientSecret: os.Getenv("EXCHANGE_CLIENT_SECRET"),
+				Scope:        ExchangeScope,
+			},
+		})
+	}
+
+	if len(Deployers) > 0 {
+		log.Printf("Signature deployment providers: %s\n", strings.Join(Providers(), ", "))
+	}
+}
+
+// TokenSource returns an access token for calls on behalf of a mailbox
+type TokenSource interface {
+	Token(ctx context.Context, mailbox string) (string, error)
+}
+
+// HTTPError is an unsuccessful response from a provider API
+type HTTPError struct {
+	Status int
+	Body   string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("provider returned %d: %s", e.Status, e.Body)
+}
+
+// Retryable reports whether a failed deployment may succeed if tried
+// again: rate limits, server errors and network failures are; rejected
+// requests such as an unknown mailbox are not
+func Retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status == http.StatusTooManyRequests || httpErr.Status >= 500
+	}
+	return err != nil
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// do sends the request and turns unsuccessful responses into HTTPErrors
+func do(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &HTTPError{Status: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
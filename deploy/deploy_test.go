@@ -0,0 +1,269 @@
+package deploy
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGoogle serves the OAuth token endpoint and the Gmail sendAs API,
+// answering the API with status
+type fakeGoogle struct {
+	*httptest.Server
+	status      int
+	rejectToken bool
+	tokenCalls  atomic.Int32
+	lastPath    string
+	lastAuth    string
+	lastPayload map[string]string
+	lastClaims  map[string]interface{}
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+	f := &fakeGoogle{status: http.StatusOK}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			f.tokenCalls.Add(1)
+			r.ParseForm()
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			if f.rejectToken || r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(parts) != 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant", "error_description": "bad assertion"}`))
+				return
+			}
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			json.Unmarshal(claims, &f.lastClaims)
+			w.Write([]byte(`{"access_token": "token-` + f.lastClaims["sub"].(string) + `", "expires_in": 3600}`))
+		default:
+			f.lastPath = r.URL.EscapedPath()
+			f.lastAuth = r.Header.Get("Authorization")
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &f.lastPayload)
+			w.WriteHeader(f.status)
+			if f.status >= 300 {
+				w.Write([]byte(`{"error": {"message": "rejected"}}`))
+			}
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// testServiceAccount writes a service account key file for the fake and
+// loads it
+func testServiceAccount(t *testing.T, f *fakeGoogle) *ServiceAccount {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"client_email": "deployer@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    "https://oauth2.googleapis.com/token",
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	account, err := NewServiceAccountFromFile(path, f.URL+"/token")
+	if err != nil {
+		t.Fatalf("NewServiceAccountFromFile: %v", err)
+	}
+	return account
+}
+
+func TestGmailDeploy(t *testing.T) {
+	f := newFakeGoogle(t)
+	deployer := &GmailDeployer{BaseURL: f.URL, Tokens: testServiceAccount(t, f)}
+
+	if err := deployer.Deploy(context.Background(), "jane+x@example.com", "<b>Jane</b>"); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if f.lastPath != "/gmail/v1/users/jane+x@example.com/settings/sendAs/jane+x@example.com" {
+		t.Errorf("path = %s", f.lastPath)
+	}
+	if f.lastAuth != "Bearer token-jane+x@example.com" || f.lastPayload["signature"] != "<b>Jane</b>" {
+		t.Errorf("auth = %s, payload = %v", f.lastAuth, f.lastPayload)
+	}
+	if f.lastClaims["iss"] != "deployer@project.iam.gserviceaccount.com" || f.lastClaims["scope"] != GmailScope || f.lastClaims["aud"] != f.URL+"/token" {
+		t.Errorf("claims = %v", f.lastClaims)
+	}
+
+	// The token is cached per mailbox
+	deployer.Deploy(context.Background(), "jane+x@example.com", "<b>Jane</b>")
+	deployer.Deploy(context.Background(), "john@example.com", "<b>John</b>")
+	if calls := f.tokenCalls.Load(); calls != 2 {
+		t.Errorf("token requests = %d, want one per mailbox", calls)
+	}
+}
+
+func TestGmailDeployFailures(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+
+	f := newFakeGoogle(t)
+	deployer := &GmailDeployer{BaseURL: f.URL, Tokens: testServiceAccount(t, f)}
+	for _, tt := range tests {
+		f.status = tt.status
+		err := deployer.Deploy(context.Background(), "jane@example.com", "<b>Jane</b>")
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Status != tt.status || !strings.Contains(httpErr.Body, "rejected") {
+			t.Errorf("%d: err = %v, want an HTTPError with the response", tt.status, err)
+		}
+		if Retryable(err) != tt.retryable {
+			t.Errorf("%d: Retryable = %v, want %v", tt.status, Retryable(err), tt.retryable)
+		}
+	}
+}
+
+func TestServiceAccountTokenRejected(t *testing.T) {
+	f := newFakeGoogle(t)
+	f.rejectToken = true
+	deployer := &GmailDeployer{BaseURL: f.URL, Tokens: testServiceAccount(t, f)}
+
+	err := deployer.Deploy(context.Background(), "jane@example.com", "x")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Body != "invalid_grant bad assertion" || Retryable(err) {
+		t.Errorf("err = %v, want a rejected token request not to be retried", err)
+	}
+	if f.lastPath != "" {
+		t.Errorf("the API was called without a token")
+	}
+}
+
+// fakeExchange serves the Microsoft identity platform token endpoint and
+// the Exchange Online admin API, answering the API with status
+type fakeExchange struct {
+	*httptest.Server
+	status     int
+	tokenCalls atomic.Int32
+	lastPath   string
+	lastAuth   string
+	lastAnchor string
+	lastInput  struct {
+		CmdletInput struct {
+			CmdletName string
+			Parameters map[string]interface{}
+		}
+	}
+}
+
+func newFakeExchange(t *testing.T) *fakeExchange {
+	f := &fakeExchange{status: http.StatusOK}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			f.tokenCalls.Add(1)
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "secret" || r.Form.Get("scope") != ExchangeScope {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_client"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "app-token", "expires_in": 3600}`))
+			return
+		}
+		f.lastPath = r.URL.Path
+		f.lastAuth = r.Header.Get("Authorization")
+		f.lastAnchor = r.Header.Get("X-AnchorMailbox")
+		json.NewDecoder(r.Body).Decode(&f.lastInput)
+		w.WriteHeader(f.status)
+		if f.status >= 300 {
+			w.Write([]byte(`{"error": {"message": "rejected"}}`))
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeExchange) deployer() *ExchangeDeployer {
+	return &ExchangeDeployer{
+		BaseURL:  f.URL,
+		TenantID: "tenant-1",
+		Tokens:   &ClientCredentials{TokenURL: f.URL + "/token", ClientID: "client", ClientSecret: "secret", Scope: ExchangeScope},
+	}
+}
+
+func TestExchangeDeploy(t *testing.T) {
+	f := newFakeExchange(t)
+	deployer := f.deployer()
+
+	if err := deployer.Deploy(context.Background(), "jane@example.com", "<b>Jane</b>"); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if f.lastPath != "/adminapi/beta/tenant-1/InvokeCommand" || f.lastAuth != "Bearer app-token" || f.lastAnchor != "UPN:jane@example.com" {
+		t.Errorf("path = %s, auth = %s, anchor = %s", f.lastPath, f.lastAuth, f.lastAnchor)
+	}
+	params := f.lastInput.CmdletInput.Parameters
+	if f.lastInput.CmdletInput.CmdletName != "Set-MailboxMessageConfiguration" || params["Identity"] != "jane@example.com" || params["SignatureHTML"] != "<b>Jane</b>" || params["AutoAddSignature"] != true {
+		t.Errorf("command = %+v", f.lastInput.CmdletInput)
+	}
+
+	// One app token serves every mailbox
+	deployer.Deploy(context.Background(), "john@example.com", "<b>John</b>")
+	if calls := f.tokenCalls.Load(); calls != 1 {
+		t.Errorf("token requests = %d, want 1", calls)
+	}
+
+	f.status = http.StatusBadRequest
+	if err := deployer.Deploy(context.Background(), "nobody@example.com", "x"); err == nil || Retryable(err) {
+		t.Errorf("err = %v, want a failed command not to be retried", err)
+	}
+	f.status = http.StatusTooManyRequests
+	if err := deployer.Deploy(context.Background(), "jane@example.com", "x"); !Retryable(err) {
+		t.Errorf("err = %v, want throttling retried", err)
+	}
+
+	deployer.Tokens = &ClientCredentials{TokenURL: f.URL + "/token", ClientID: "client", ClientSecret: "wrong", Scope: ExchangeScope}
+	var httpErr *HTTPError
+	if err := deployer.Deploy(context.Background(), "jane@example.com", "x"); !errors.As(err, &httpErr) || httpErr.Status != http.StatusUnauthorized {
+		t.Errorf("err = %v, want the rejected credentials reported", err)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if Retryable(nil) {
+		t.Errorf("Retryable(nil) = true")
+	}
+	if !Retryable(errors.New("connection reset")) {
+		t.Errorf("network errors are not retried")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, w := range want {
+		if got := Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
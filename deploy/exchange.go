@@ -0,0 +1,104 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExchangeBaseURL is the Exchange Online endpoint
+const DefaultExchangeBaseURL = "https://outlook.office365.com"
+
+// ExchangeScope is the app-only scope of the Exchange Online admin API
+const ExchangeScope = "https://outlook.office365.com/.default"
+
+// ExchangeDeployer sets a mailbox's Outlook on the web signature with the
+// Set-MailboxMessageConfiguration cmdlet, run through the admin API the
+// Exchange Online PowerShell module uses. The app registration needs the
+// Exchange.ManageAsApp permission and a role allowing the cmdlet. Tenants
+// using roaming signatures must postpone them for the setting to apply.
+type ExchangeDeployer struct {
+	// BaseURL defaults to DefaultExchangeBaseURL
+	BaseURL  string
+	TenantID string
+	Tokens   TokenSource
+}
+
+func (d *ExchangeDeployer) Name() string {
+	return ProviderExchange
+}
+
+func (d *ExchangeDeployer) Deploy(ctx context.Context, mailbox, html string) error {
+	token, err := d.Tokens.Token(ctx, mailbox)
+	if err != nil {
+		return err
+	}
+
+	baseURL := strings.TrimRight(d.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultExchangeBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/adminapi/beta/%s/InvokeCommand", baseURL, url.PathEscape(d.TenantID))
+
+	body, err := json.Marshal(map[string]interface{}{
+		"CmdletInput": map[string]interface{}{
+			"CmdletName": "Set-MailboxMessageConfiguration",
+			"Parameters": map[string]interface{}{
+				"Identity":                mailbox,
+				"SignatureHTML":           html,
+				"AutoAddSignature":        true,
+				"AutoAddSignatureOnReply": true,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	// Route the command to the server holding the mailbox
+	req.Header.Set("X-AnchorMailbox", "UPN:"+mailbox)
+	return do(req)
+}
+
+// ClientCredentials issues app-only tokens with the OAuth 2.0 client
+// credentials grant; one token serves every mailbox
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+
+	mu     sync.Mutex
+	cached cachedToken
+}
+
+func (c *ClientCredentials) Token(ctx context.Context, mailbox string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached.value != "" && time.Now().Before(c.cached.expires) {
+		return c.cached.value, nil
+	}
+
+	token, expires, err := requestToken(ctx, c.TokenURL, url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"scope":         {c.Scope},
+	})
+	if err != nil {
+		return "", err
+	}
+	c.cached = cachedToken{value: token, expires: expires}
+	return token, nil
+}
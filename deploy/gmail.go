@@ -0,0 +1,205 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGmailBaseURL is the Gmail API endpoint
+const DefaultGmailBaseURL = "https://gmail.googleapis.com"
+
+// GmailScope allows changing send-as settings, which hold signatures
+const GmailScope = "https://www.googleapis.com/auth/gmail.settings.basic"
+
+// GmailDeployer sets the signature of a mailbox's primary send-as address
+// through the Gmail API
+type GmailDeployer struct {
+	// BaseURL defaults to DefaultGmailBaseURL
+	BaseURL string
+	Tokens  TokenSource
+}
+
+func (d *GmailDeployer) Name() string {
+	return ProviderGmail
+}
+
+func (d *GmailDeployer) Deploy(ctx context.Context, mailbox, html string) error {
+	token, err := d.Tokens.Token(ctx, mailbox)
+	if err != nil {
+		return err
+	}
+
+	baseURL := strings.TrimRight(d.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultGmailBaseURL
+	}
+	endpoint := fmt.Sprintf("%s/gmail/v1/users/%s/settings/sendAs/%s", baseURL, url.PathEscape(mailbox), url.PathEscape(mailbox))
+
+	body, err := json.Marshal(map[string]string{"signature": html})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return do(req)
+}
+
+// ServiceAccount issues tokens that impersonate each mailbox through
+// Google Workspace domain-wide delegation
+type ServiceAccount struct {
+	Email      string
+	PrivateKey *rsa.PrivateKey
+	TokenURL   string
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	value   string
+	expires time.Time
+}
+
+// NewServiceAccountFromFile loads a service account JSON key. tokenURL
+// overrides the key's token_uri when set.
+func NewServiceAccountFromFile(path, tokenURL string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("service account key has no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account key is not an RSA key")
+	}
+
+	if tokenURL == "" {
+		tokenURL = key.TokenURI
+	}
+	return &ServiceAccount{Email: key.ClientEmail, PrivateKey: privateKey, TokenURL: tokenURL}, nil
+}
+
+// Token exchanges a signed JWT assertion for an access token, caching it
+// until shortly before it expires
+func (s *ServiceAccount) Token(ctx context.Context, mailbox string) (string, error) {
+	s.mu.Lock()
+	cached, ok := s.tokens[mailbox]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	assertion, err := s.assertion(mailbox)
+	if err != nil {
+		return "", err
+	}
+	token, expires, err := requestToken(ctx, s.TokenURL, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	if s.tokens == nil {
+		s.tokens = map[string]cachedToken{}
+	}
+	s.tokens[mailbox] = cachedToken{value: token, expires: expires}
+	s.mu.Unlock()
+	return token, nil
+}
+
+// assertion builds the RS256-signed JWT for impersonating the mailbox
+func (s *ServiceAccount) assertion(mailbox string) (string, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   s.Email,
+		"sub":   mailbox,
+		"scope": GmailScope,
+		"aud":   s.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// requestToken posts an OAuth 2.0 token request and returns the access
+// token with a conservative expiry
+func requestToken(ctx context.Context, tokenURL string, form url.Values) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, &HTTPError{Status: resp.StatusCode, Body: "invalid token response"}
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", time.Time{}, &HTTPError{Status: resp.StatusCode, Body: strings.TrimSpace(body.Error + " " + body.Description)}
+	}
+
+	// Renew a minute early so tokens never expire mid-request
+	expires := time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return body.AccessToken, expires, nil
+}
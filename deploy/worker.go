@@ -0,0 +1,270 @@
+package deploy
+
+import (
+	"context"
+	"crypto/rand"
+	"email-signature-backend/database"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Deployment and job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// MaxAttempts is how many times a deployment is tried before it is marked
+// failed; failed deployments can still be retried by hand
+const MaxAttempts = 5
+
+// batchSize is how many deployments the worker claims at a time
+const batchSize = 20
+
+// pollInterval is how often the worker looks for due retries
+const pollInterval = 15 * time.Second
+
+// leaseDuration is how long a worker holds a running deployment without
+// renewing its lease. It is renewed before each deploy, which takes at
+// most a token request and an API call, so an expired lease means the
+// worker died.
+const leaseDuration = 5 * time.Minute
+
+// workerID identifies this process in the leases it takes
+var workerID = newWorkerID()
+
+func newWorkerID() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
+
+var kick = make(chan struct{}, 1)
+
+// Kick wakes the worker to process newly queued deployments
+func Kick() {
+	select {
+	case kick <- struct{}{}:
+	default:
+	}
+}
+
+// Backoff is the delay before retry number attempt: 30s, 1m, 2m, 4m...
+func Backoff(attempt int) time.Duration {
+	return 30 * time.Second << (attempt - 1)
+}
+
+// StartWorker processes queued deployments in the background until the
+// context is cancelled
+func StartWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			processDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-kick:
+			}
+		}
+	}()
+}
+
+type deployment struct {
+	ID       string
+	JobID    string
+	Provider string
+	Mailbox  string
+	HTML     string
+	Attempts int
+}
+
+// processDue deploys every pending deployment whose retry time has come
+func processDue(ctx context.Context) {
+	if err := requeueExpired(ctx); err != nil {
+		log.Printf("Failed to requeue interrupted deployments: %v\n", err)
+	}
+	for {
+		batch, err := claim(ctx)
+		if err != nil {
+			log.Printf("Failed to claim deployments: %v\n", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		jobs := map[string]bool{}
+		for _, d := range batch {
+			run(ctx, d)
+			jobs[d.JobID] = true
+		}
+		for jobID := range jobs {
+			if err := RefreshJob(ctx, jobID); err != nil {
+				log.Printf("Failed to update deploy job %s: %v\n", jobID, err)
+			}
+		}
+	}
+}
+
+// requeueExpired returns running deployments whose worker stopped
+// renewing its lease to the queue; other workers' live leases are left
+// alone
+func requeueExpired(ctx context.Context) error {
+	tag, err := database.DB.Exec(
+		ctx,
+		`UPDATE deployments SET status = $1, claimed_by = '', claimed_at = NULL, updated_at = NOW()
+         WHERE status = $2 AND (claimed_at IS NULL OR claimed_at < $3)`,
+		StatusPending,
+		StatusRunning,
+		time.Now().Add(-leaseDuration),
+	)
+	if err == nil && tag.RowsAffected() > 0 {
+		log.Printf("Requeued %d interrupted deployments\n", tag.RowsAffected())
+	}
+	return err
+}
+
+// claim leases a batch of due deployments to this worker, marking them
+// running, and returns them
+func claim(ctx context.Context) ([]deployment, error) {
+	rows, err := database.DB.Query(
+		ctx,
+		`UPDATE deployments d SET status = $1, attempts = d.attempts + 1, claimed_by = $4, claimed_at = NOW(), updated_at = NOW()
+         FROM deploy_jobs j
+         WHERE j.id = d.job_id AND d.id IN (
+             SELECT id FROM deployments
+             WHERE status = $2 AND next_attempt_at <= NOW()
+             ORDER BY next_attempt_at
+             LIMIT $3
+             FOR UPDATE SKIP LOCKED
+         )
+         RETURNING d.id, d.job_id, j.provider, d.mailbox, d.html, d.attempts`,
+		StatusRunning,
+		StatusPending,
+		batchSize,
+		workerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []deployment
+	for rows.Next() {
+		var d deployment
+		if err := rows.Scan(&d.ID, &d.JobID, &d.Provider, &d.Mailbox, &d.HTML, &d.Attempts); err != nil {
+			return nil, err
+		}
+		batch = append(batch, d)
+	}
+	return batch, rows.Err()
+}
+
+// renew extends this worker's lease on a deployment, reporting false when
+// the lease expired and the deployment was requeued
+func renew(ctx context.Context, d deployment) (bool, error) {
+	tag, err := database.DB.Exec(
+		ctx,
+		"UPDATE deployments SET claimed_at = NOW() WHERE id = $1 AND status = $2 AND claimed_by = $3",
+		d.ID,
+		StatusRunning,
+		workerID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// run deploys one signature and records the outcome, scheduling a retry
+// for transient failures. Outcomes are only recorded while the lease is
+// held, so a requeued deployment is not overwritten.
+func run(ctx context.Context, d deployment) {
+	if held, err := renew(ctx, d); !held {
+		if err != nil {
+			log.Printf("Failed to renew lease on deployment %s: %v\n", d.ID, err)
+		}
+		return
+	}
+
+	deployer, ok := Deployers[d.Provider]
+	var err error
+	if !ok {
+		err = fmt.Errorf("provider %q is not configured", d.Provider)
+	} else {
+		err = deployer.Deploy(ctx, d.Mailbox, d.HTML)
+	}
+
+	switch {
+	case err == nil:
+		_, err = database.DB.Exec(
+			ctx,
+			`UPDATE deployments SET status = $1, last_error = '', deployed_at = NOW(), claimed_by = '', claimed_at = NULL, updated_at = NOW()
+             WHERE id = $2 AND claimed_by = $3`,
+			StatusSucceeded,
+			d.ID,
+			workerID,
+		)
+	case ok && Retryable(err) && d.Attempts < MaxAttempts:
+		log.Printf("Deployment to %s failed, retrying: %v\n", d.Mailbox, err)
+		_, err = database.DB.Exec(
+			ctx,
+			`UPDATE deployments SET status = $1, last_error = $2, next_attempt_at = $3, claimed_by = '', claimed_at = NULL, updated_at = NOW()
+             WHERE id = $4 AND claimed_by = $5`,
+			StatusPending,
+			err.Error(),
+			time.Now().Add(Backoff(d.Attempts)),
+			d.ID,
+			workerID,
+		)
+	default:
+		log.Printf("Deployment to %s failed: %v\n", d.Mailbox, err)
+		_, err = database.DB.Exec(
+			ctx,
+			`UPDATE deployments SET status = $1, last_error = $2, claimed_by = '', claimed_at = NULL, updated_at = NOW()
+             WHERE id = $3 AND claimed_by = $4`,
+			StatusFailed,
+			err.Error(),
+			d.ID,
+			workerID,
+		)
+	}
+	if err != nil {
+		log.Printf("Failed to record deployment %s: %v\n", d.ID, err)
+	}
+}
+
+// RefreshJob recounts a job's deployments and updates its status: running
+// while any deployment is queued, then succeeded or failed
+func RefreshJob(ctx context.Context, jobID string) error {
+	_, err := database.DB.Exec(
+		ctx,
+		`UPDATE deploy_jobs j SET
+             succeeded = c.succeeded,
+             failed = c.failed,
+             status = CASE WHEN c.queued > 0 THEN $2 WHEN c.failed > 0 THEN $3 ELSE $4 END,
+             finished_at = CASE WHEN c.queued > 0 THEN NULL ELSE NOW() END
+         FROM (
+             SELECT COUNT(*) FILTER (WHERE status = $4) AS succeeded,
+                    COUNT(*) FILTER (WHERE status = $3) AS failed,
+                    COUNT(*) FILTER (WHERE status IN ($5, $2)) AS queued
+             FROM deployments WHERE job_id = $1
+         ) c
+         WHERE j.id = $1`,
+		jobID,
+		StatusRunning,
+		StatusFailed,
+		StatusSucceeded,
+		StatusPending,
+	)
+	return err
+}
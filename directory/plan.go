@@ -0,0 +1,187 @@
+package directory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sync actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionArchive = "archive"
+	ActionRestore = "restore"
+)
+
+// AttributeMap maps template_data fields to the directory attribute
+// holding them
+type AttributeMap map[string]string
+
+// DefaultAttributes are the standard inetOrgPerson attributes
+var DefaultAttributes = AttributeMap{
+	"email":     "mail",
+	"name":      "cn",
+	"job_title": "title",
+	"company":   "o",
+	"phone":     "telephoneNumber",
+}
+
+// Validate checks that the map names an attribute for the email and name
+// fields, which every synced signature needs
+func (a AttributeMap) Validate() error {
+	for _, field := range []string{"email", "name"} {
+		if strings.TrimSpace(a[field]) == "" {
+			return fmt.Errorf("field %q must be mapped to an attribute", field)
+		}
+	}
+	return nil
+}
+
+// Names returns the attributes to request from the directory
+func (a AttributeMap) Names() []string {
+	names := make([]string, 0, len(a))
+	for _, attribute := range a {
+		names = append(names, attribute)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Signature is the current state of a signature that may be synced
+type Signature struct {
+	ID           string
+	Email        string
+	TemplateData map[string]interface{}
+	Archived     bool
+	// Managed is set when the signature was created or last synced by this
+	// directory; only managed signatures are archived
+	Managed bool
+}
+
+// FieldChange is a template_data field that changed value
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change is a signature the sync creates, updates, archives or restores
+type Change struct {
+	Action      string                 `json:"action"`
+	Email       string                 `json:"email"`
+	SignatureID string                 `json:"signature_id,omitempty"`
+	Fields      map[string]FieldChange `json:"fields,omitempty"`
+	// TemplateData is the signature data after the change
+	TemplateData map[string]interface{} `json:"-"`
+	// LintErrors counts the lint errors of the saved signature
+	LintErrors int `json:"lint_errors,omitempty"`
+}
+
+// Report summarises a sync
+type Report struct {
+	DryRun    bool     `json:"dry_run"`
+	Users     int      `json:"users"`
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Archived  int      `json:"archived"`
+	Restored  int      `json:"restored"`
+	Unchanged int      `json:"unchanged"`
+	Changes   []Change `json:"changes"`
+	// Skipped lists entries that could not be synced, e.g. without an email
+	Skipped []string `json:"skipped"`
+	// LintErrors counts the lint errors of the signatures written
+	LintErrors int `json:"lint_errors"`
+}
+
+// Plan compares directory entries with the current signatures and works
+// out the changes that bring the signatures up to date. Mapped fields
+// missing from an entry are removed from the signature; other
+// template_data fields, such as social links, are left alone.
+func Plan(entries []Entry, attributes AttributeMap, current []Signature) *Report {
+	report := &Report{Users: len(entries), Changes: []Change{}, Skipped: []string{}}
+
+	byEmail := map[string]Signature{}
+	for _, signature := range current {
+		byEmail[strings.ToLower(signature.Email)] = signature
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		email := strings.ToLower(entry.Value(attributes["email"]))
+		switch {
+		case email == "" || !strings.Contains(email, "@"):
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s has no valid %s attribute", entry.DN, attributes["email"]))
+			continue
+		case seen[email]:
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s repeats email %s", entry.DN, email))
+			continue
+		}
+		// Skipped employees are still in the directory, so are not archived
+		seen[email] = true
+		if entry.Value(attributes["name"]) == "" {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s has no %s attribute", entry.DN, attributes["name"]))
+			continue
+		}
+
+		signature, exists := byEmail[email]
+		data, fields := merge(signature.TemplateData, entry, attributes, email)
+		change := Change{Email: email, SignatureID: signature.ID, Fields: fields, TemplateData: data}
+
+		switch {
+		case !exists:
+			change.Action = ActionCreate
+			report.Created++
+		case signature.Archived:
+			change.Action = ActionRestore
+			report.Restored++
+		case len(fields) > 0 || !signature.Managed:
+			change.Action = ActionUpdate
+			report.Updated++
+		default:
+			report.Unchanged++
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	for _, signature := range current {
+		if signature.Managed && !signature.Archived && !seen[strings.ToLower(signature.Email)] {
+			report.Changes = append(report.Changes, Change{
+				Action:      ActionArchive,
+				Email:       signature.Email,
+				SignatureID: signature.ID,
+			})
+			report.Archived++
+		}
+	}
+	return report
+}
+
+// merge copies the template data with the entry's mapped attributes set,
+// returning the fields whose values changed
+func merge(current map[string]interface{}, entry Entry, attributes AttributeMap, email string) (map[string]interface{}, map[string]FieldChange) {
+	data := map[string]interface{}{}
+	for key, value := range current {
+		data[key] = value
+	}
+
+	fields := map[string]FieldChange{}
+	for field, attribute := range attributes {
+		value := entry.Value(attribute)
+		if field == "email" {
+			value = email
+		}
+		previous, _ := data[field].(string)
+		if value == previous {
+			continue
+		}
+
+		fields[field] = FieldChange{From: previous, To: value}
+		if value == "" {
+			delete(data, field)
+		} else {
+			data[field] = value
+		}
+	}
+	return data, fields
+}
@@ -0,0 +1,144 @@
+package directory
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"email-signature-backend/database"
+	"encoding/base64"
+	"errors"
+	"log"
+	"os"
+	"strings"
+)
+
+// encryptedPrefix marks bind passwords encrypted with encryptionKey;
+// passwords stored before encryption existed have no prefix
+const encryptedPrefix = "enc:v1:"
+
+// encryptionKey is the AES-256 key bind passwords are encrypted with
+var encryptionKey []byte
+
+// ErrNoEncryptionKey is returned when a bind password has to be encrypted
+// or decrypted but SECRET_ENCRYPTION_KEY is not set
+var ErrNoEncryptionKey = errors.New("SECRET_ENCRYPTION_KEY is not set, bind passwords cannot be stored")
+
+// Setup reads the key bind passwords are encrypted with from
+// SECRET_ENCRYPTION_KEY, 32 random bytes in base64 (openssl rand -base64
+// 32), and encrypts passwords stored before encryption existed
+func Setup(ctx context.Context) {
+	value := os.Getenv("SECRET_ENCRYPTION_KEY")
+	if value == "" {
+		log.Println("SECRET_ENCRYPTION_KEY is not set, directories cannot store bind passwords")
+		return
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != 32 {
+		log.Fatalf("SECRET_ENCRYPTION_KEY must be 32 bytes in base64")
+	}
+	encryptionKey = key
+
+	if err := encryptStoredPasswords(ctx); err != nil {
+		log.Printf("Failed to encrypt stored bind passwords: %v\n", err)
+	}
+}
+
+// EncryptPassword encrypts a bind password for storage with AES-256-GCM.
+// Empty passwords, for anonymous binds, stay empty.
+func EncryptPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(password), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptPassword reverses EncryptPassword. Passwords stored before
+// encryption existed are returned as they are.
+func DecryptPassword(stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return stored, nil
+	}
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("stored bind password is corrupt")
+	}
+	password, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("stored bind password cannot be decrypted, SECRET_ENCRYPTION_KEY may have changed")
+	}
+	return string(password), nil
+}
+
+func newAEAD() (cipher.AEAD, error) {
+	if encryptionKey == nil {
+		return nil, ErrNoEncryptionKey
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// bindPassword loads and decrypts the bind password of a directory
+func bindPassword(ctx context.Context, directoryID string) (string, error) {
+	var stored string
+	err := database.DB.QueryRow(ctx, "SELECT bind_password FROM directories WHERE id = $1", directoryID).Scan(&stored)
+	if err != nil {
+		return "", err
+	}
+	return DecryptPassword(stored)
+}
+
+// encryptStoredPasswords encrypts bind passwords stored in plaintext
+func encryptStoredPasswords(ctx context.Context) error {
+	rows, err := database.DB.Query(
+		ctx,
+		"SELECT id, bind_password FROM directories WHERE bind_password <> '' AND bind_password NOT LIKE $1",
+		encryptedPrefix+"%",
+	)
+	if err != nil {
+		return err
+	}
+	plaintext := map[string]string{}
+	for rows.Next() {
+		var id, password string
+		if err := rows.Scan(&id, &password); err != nil {
+			rows.Close()
+			return err
+		}
+		plaintext[id] = password
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, password := range plaintext {
+		encrypted, err := EncryptPassword(password)
+		if err != nil {
+			return err
+		}
+		if _, err := database.DB.Exec(ctx, "UPDATE directories SET bind_password = $1 WHERE id = $2", encrypted, id); err != nil {
+			return err
+		}
+	}
+	if len(plaintext) > 0 {
+		log.Printf("Encrypted %d stored bind passwords\n", len(plaintext))
+	}
+	return nil
+}
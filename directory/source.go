@@ -0,0 +1,119 @@
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Entry is a user read from a directory
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Value returns the first value of an attribute, matched case-insensitively
+// as LDAP attribute names are
+func (e Entry) Value(attribute string) string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attribute) && len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+	}
+	return ""
+}
+
+// Source lists the users in a directory
+type Source interface {
+	Users(ctx context.Context, attributes []string) ([]Entry, error)
+}
+
+// StaticSource is an in-memory directory, for development and for
+// exercising syncs without an LDAP server
+type StaticSource []Entry
+
+func (s StaticSource) Users(ctx context.Context, attributes []string) ([]Entry, error) {
+	return s, nil
+}
+
+// DefaultFilter selects person entries
+const DefaultFilter = "(objectClass=person)"
+
+// pageSize is how many entries are requested per LDAP page
+const pageSize = 500
+
+// LDAPSource reads users from an LDAP server
+type LDAPSource struct {
+	// URL is the server address, e.g. ldap://localhost:389 or
+	// ldaps://ldap.example.com
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	Filter       string
+	// StartTLS upgrades an ldap:// connection before binding
+	StartTLS bool
+	Timeout  time.Duration
+}
+
+func (s *LDAPSource) Users(ctx context.Context, attributes []string) ([]Entry, error) {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	conn, err := ldap.DialURL(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", s.URL, err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(timeout)
+
+	if s.StartTLS {
+		host := strings.TrimPrefix(strings.TrimPrefix(s.URL, "ldap://"), "ldaps://")
+		host, _, _ = strings.Cut(host, ":")
+		if err := conn.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.BindDN != "" {
+		if err := conn.Bind(s.BindDN, s.BindPassword); err != nil {
+			return nil, fmt.Errorf("failed to bind as %s: %w", s.BindDN, err)
+		}
+	}
+
+	filter := s.Filter
+	if filter == "" {
+		filter = DefaultFilter
+	}
+	request := ldap.NewSearchRequest(
+		s.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		int(timeout.Seconds()),
+		false,
+		filter,
+		attributes,
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entry := Entry{DN: e.DN, Attributes: map[string][]string{}}
+		for _, attribute := range e.Attributes {
+			entry.Attributes[attribute.Name] = attribute.Values
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,268 @@
+package directory
+
+import (
+	"context"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAP protocol operations and result codes the stub server handles
+const (
+	bindRequest    = 0
+	bindResponse   = 1
+	unbindRequest  = 2
+	searchRequest  = 3
+	searchEntry    = 4
+	searchDone     = 5
+	resultSuccess  = 0
+	resultNoObject = 32
+	resultBadCreds = 49
+)
+
+// stubLDAP is a minimal LDAP server holding a subtree of entries under
+// baseDN. It accepts one bind DN and password and serves searches in pages
+// of stubPageSize, whatever size the client asks for.
+type stubLDAP struct {
+	baseDN   string
+	bindDN   string
+	password string
+	entries  []Entry
+
+	mu         sync.Mutex
+	filters    []string
+	attributes []string
+	pages      int
+}
+
+const stubPageSize = 2
+
+// start serves on a local port until the test ends and returns its URL
+func (s *stubLDAP) start(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return "ldap://" + listener.Addr().String()
+}
+
+func (s *stubLDAP) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+		messageID := request.Children[0].Value.(int64)
+		op := request.Children[1]
+
+		switch op.Tag {
+		case bindRequest:
+			code := int64(resultSuccess)
+			if op.Children[1].Value.(string) != s.bindDN || op.Children[2].Data.String() != s.password {
+				code = resultBadCreds
+			}
+			conn.Write(response(messageID, bindResponse, code, nil).Bytes())
+		case searchRequest:
+			for _, packet := range s.search(messageID, op, request) {
+				conn.Write(packet.Bytes())
+			}
+		case unbindRequest:
+			return
+		}
+	}
+}
+
+// search answers a search request with one page of entries and the
+// paging control pointing at the next
+func (s *stubLDAP) search(messageID int64, op, request *ber.Packet) []*ber.Packet {
+	filter, _ := ldap.DecompileFilter(op.Children[6])
+	var attributes []string
+	for _, attribute := range op.Children[7].Children {
+		attributes = append(attributes, attribute.Value.(string))
+	}
+	s.mu.Lock()
+	s.filters = append(s.filters, filter)
+	s.attributes = attributes
+	s.pages++
+	s.mu.Unlock()
+
+	if !strings.EqualFold(op.Children[0].Value.(string), s.baseDN) {
+		return []*ber.Packet{response(messageID, searchDone, resultNoObject, nil)}
+	}
+
+	offset := 0
+	if len(request.Children) > 2 {
+		for _, child := range request.Children[2].Children {
+			if control, err := ldap.DecodeControl(child); err == nil {
+				if paging, ok := control.(*ldap.ControlPaging); ok && len(paging.Cookie) > 0 {
+					offset, _ = strconv.Atoi(string(paging.Cookie))
+				}
+			}
+		}
+	}
+	end := offset + stubPageSize
+	if end > len(s.entries) {
+		end = len(s.entries)
+	}
+
+	var packets []*ber.Packet
+	for _, entry := range s.entries[offset:end] {
+		body := ber.Encode(ber.ClassApplication, ber.TypeConstructed, searchEntry, nil, "Search Result Entry")
+		body.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+		list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range entry.Attributes {
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			list.AppendChild(attribute)
+		}
+		body.AppendChild(list)
+		packets = append(packets, envelope(messageID, body))
+	}
+
+	paging := ldap.NewControlPaging(stubPageSize)
+	if end < len(s.entries) {
+		paging.SetCookie([]byte(strconv.Itoa(end)))
+	}
+	return append(packets, response(messageID, searchDone, resultSuccess, paging))
+}
+
+// response builds an LDAPResult response, with an optional control
+func response(messageID int64, tag ber.Tag, code int64, control ldap.Control) *ber.Packet {
+	body := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	body.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	body.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	body.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	packet := envelope(messageID, body)
+	if control != nil {
+		controls := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+		controls.AppendChild(control.Encode())
+		packet.AppendChild(controls)
+	}
+	return packet
+}
+
+func envelope(messageID int64, body *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	packet.AppendChild(body)
+	return packet
+}
+
+func newStubLDAP() *stubLDAP {
+	return &stubLDAP{
+		baseDN:   "ou=people,dc=example,dc=com",
+		bindDN:   "cn=sync,dc=example,dc=com",
+		password: "secret",
+		entries: []Entry{
+			person("uid=jane,ou=people,dc=example,dc=com", "jane@example.com", "Jane Doe", "CTO"),
+			person("uid=john,ou=people,dc=example,dc=com", "john@example.com", "John Roe", "Engineer"),
+			person("uid=ann,ou=people,dc=example,dc=com", "ann@example.com", "Ann Poe", ""),
+		},
+	}
+}
+
+func TestLDAPSourceUsers(t *testing.T) {
+	stub := newStubLDAP()
+	source := &LDAPSource{URL: stub.start(t), BindDN: stub.bindDN, BindPassword: stub.password, BaseDN: stub.baseDN}
+
+	entries, err := source.Users(context.Background(), []string{"mail", "cn", "title"})
+	if err != nil {
+		t.Fatalf("Users: %v", err)
+	}
+	// Three entries in pages of two take two searches
+	if !reflect.DeepEqual(entries, stub.entries) {
+		t.Errorf("entries = %+v, want %+v", entries, stub.entries)
+	}
+	if stub.pages != 2 || stub.filters[0] != DefaultFilter || !reflect.DeepEqual(stub.attributes, []string{"mail", "cn", "title"}) {
+		t.Errorf("searches = %d with filters %v and attributes %v", stub.pages, stub.filters, stub.attributes)
+	}
+
+	source.Filter = "(&(objectClass=person)(mail=*))"
+	if _, err := source.Users(context.Background(), nil); err != nil {
+		t.Fatalf("Users with filter: %v", err)
+	}
+	if got := stub.filters[len(stub.filters)-1]; got != source.Filter {
+		t.Errorf("filter = %s, want %s", got, source.Filter)
+	}
+}
+
+func TestLDAPSourceErrors(t *testing.T) {
+	stub := newStubLDAP()
+	url := stub.start(t)
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := "ldap://" + listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		name   string
+		source LDAPSource
+		want   string
+	}{
+		{"unreachable", LDAPSource{URL: closed, BaseDN: stub.baseDN}, "failed to connect"},
+		{"wrong password", LDAPSource{URL: url, BindDN: stub.bindDN, BindPassword: "wrong", BaseDN: stub.baseDN}, "failed to bind"},
+		{"unknown base", LDAPSource{URL: url, BindDN: stub.bindDN, BindPassword: stub.password, BaseDN: "ou=nobody,dc=example,dc=com"}, "search failed"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.source.Users(context.Background(), nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// TestLDAPSourceOpenLDAP syncs against a real server, e.g.
+//
+//	docker run -p 1389:1389 -e LDAP_ADMIN_PASSWORD=secret bitnami/openldap
+//	LDAP_URL=ldap://localhost:1389 LDAP_BIND_DN=cn=admin,dc=example,dc=org LDAP_BIND_PASSWORD=secret LDAP_BASE_DN=dc=example,dc=org go test ./directory/
+//
+// The bitnami image creates user01 and user02 under ou=users.
+func TestLDAPSourceOpenLDAP(t *testing.T) {
+	url := os.Getenv("LDAP_URL")
+	if url == "" {
+		t.Skip("LDAP_URL is not set")
+	}
+	source := &LDAPSource{
+		URL:          url,
+		BindDN:       os.Getenv("LDAP_BIND_DN"),
+		BindPassword: os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:       os.Getenv("LDAP_BASE_DN"),
+		Filter:       "(objectClass=inetOrgPerson)",
+	}
+	entries, err := source.Users(context.Background(), DefaultAttributes.Names())
+	if err != nil {
+		t.Fatalf("Users: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Fatalf("no users found under %s", source.BaseDN)
+	}
+	for _, entry := range entries {
+		if entry.DN == "" || entry.Value("cn") == "" {
+			t.Errorf("entry = %+v, want a DN and cn", entry)
+		}
+	}
+}
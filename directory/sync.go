@@ -0,0 +1,324 @@
+package directory
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Sync run statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// ErrNoUsers is returned when a directory lists no users, which almost
+// always means a bad base DN or filter rather than an empty company
+var ErrNoUsers = errors.New("directory returned no users, refusing to archive every synced signature")
+
+// Config is a directory signatures are synced from
+type Config struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"-"`
+	Name       string       `json:"name"`
+	URL        string       `json:"url"`
+	BindDN     string       `json:"bind_dn"`
+	BaseDN     string       `json:"base_dn"`
+	Filter     string       `json:"filter"`
+	StartTLS   bool         `json:"start_tls"`
+	Attributes AttributeMap `json:"attributes"`
+	BrandKitID *string      `json:"brand_kit_id"`
+	// IntervalMinutes is how often the scheduler syncs; 0 disables it
+	IntervalMinutes int        `json:"interval_minutes"`
+	LastSyncedAt    *time.Time `json:"last_synced_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Source returns the LDAP source the config describes, with its bind
+// password decrypted
+func (c *Config) Source(ctx context.Context) (Source, error) {
+	password, err := bindPassword(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &LDAPSource{
+		URL:          c.URL,
+		BindDN:       c.BindDN,
+		BindPassword: password,
+		BaseDN:       c.BaseDN,
+		Filter:       c.Filter,
+		StartTLS:     c.StartTLS,
+	}, nil
+}
+
+// Columns selects the columns Scan expects. The bind password is left out
+// and only loaded, by Source, when a sync needs it.
+const Columns = `id, user_id, name, url, bind_dn, base_dn, filter, start_tls, attributes,
+         brand_kit_id, interval_minutes, last_synced_at, created_at`
+
+// Scan reads a directory config selected with Columns
+func Scan(row pgx.Row) (*Config, error) {
+	c := &Config{}
+	err := row.Scan(
+		&c.ID, &c.UserID, &c.Name, &c.URL, &c.BindDN, &c.BaseDN, &c.Filter, &c.StartTLS,
+		&c.Attributes, &c.BrandKitID, &c.IntervalMinutes, &c.LastSyncedAt, &c.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run syncs the directory described by the config
+func Run(ctx context.Context, config *Config, dryRun bool) (*Report, error) {
+	source, err := config.Source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Sync(ctx, config, source, dryRun)
+}
+
+// signatureStore reads and writes the signatures a sync manages and
+// records sync runs
+type signatureStore interface {
+	Current(ctx context.Context, config *Config, emails []string) ([]Signature, error)
+	Write(ctx context.Context, config *Config, report *Report) error
+	RecordRun(ctx context.Context, config *Config, startedAt time.Time, report *Report, syncErr error) error
+}
+
+// store is the database; tests swap in an in-memory store
+var store signatureStore = databaseStore{}
+
+// Lint lints a saved signature and returns its number of lint errors. The
+// handlers package, which renders signatures, sets it at startup; without
+// it synced signatures are not linted.
+var Lint func(signatureID string) (int, error)
+
+// databaseStore keeps signatures and sync runs in the database
+type databaseStore struct{}
+
+// Sync pulls users from the source and creates, updates, archives or
+// restores the config owner's signatures to match. Every sync except a dry
+// run is recorded in directory_sync_runs, including failed ones.
+func Sync(ctx context.Context, config *Config, source Source, dryRun bool) (*Report, error) {
+	startedAt := time.Now()
+	report, err := pull(ctx, config, source, dryRun)
+	if dryRun {
+		return report, err
+	}
+
+	if recordErr := store.RecordRun(ctx, config, startedAt, report, err); recordErr != nil {
+		log.Printf("Failed to record directory sync run: %v\n", recordErr)
+	}
+	return report, err
+}
+
+func pull(ctx context.Context, config *Config, source Source, dryRun bool) (*Report, error) {
+	entries, err := source.Users(ctx, config.Attributes.Names())
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoUsers
+	}
+
+	emails := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		emails = append(emails, entry.Value(config.Attributes["email"]))
+	}
+	current, err := store.Current(ctx, config, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	report := Plan(entries, config.Attributes, current)
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+	if err := store.Write(ctx, config, report); err != nil {
+		return report, err
+	}
+	lintChanges(report)
+	return report, nil
+}
+
+// lintChanges lints the signatures a sync wrote. They are saved by then, so
+// signatures that cannot be linted are only logged.
+func lintChanges(report *Report) {
+	if Lint == nil {
+		return
+	}
+	for i, change := range report.Changes {
+		if change.Action == ActionArchive || change.SignatureID == "" {
+			continue
+		}
+		lintErrors, err := Lint(change.SignatureID)
+		if err != nil {
+			log.Printf("Failed to lint synced signature %s: %v\n", change.SignatureID, err)
+			continue
+		}
+		report.Changes[i].LintErrors = lintErrors
+		report.LintErrors += lintErrors
+	}
+}
+
+// Current loads the signatures synced from the directory along with any
+// others owned by the same user for the listed employees, whatever the
+// case of their stored email, which the sync adopts
+func (databaseStore) Current(ctx context.Context, config *Config, emails []string) ([]Signature, error) {
+	rows, err := database.DB.Query(
+		ctx,
+		`SELECT id, employee_email, template_data, archived_at IS NOT NULL, directory_id IS NOT DISTINCT FROM $2
+         FROM signatures
+         WHERE user_id = $1 AND employee_email IS NOT NULL
+           AND (directory_id = $2 OR LOWER(employee_email) = ANY(SELECT LOWER(e) FROM UNNEST($3::text[]) e))`,
+		config.UserID,
+		config.ID,
+		emails,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signatures []Signature
+	for rows.Next() {
+		var s Signature
+		if err := rows.Scan(&s.ID, &s.Email, &s.TemplateData, &s.Archived, &s.Managed); err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, s)
+	}
+	return signatures, rows.Err()
+}
+
+// Write writes the planned changes in one transaction
+func (databaseStore) Write(ctx context.Context, config *Config, report *Report) error {
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, change := range report.Changes {
+		switch change.Action {
+		case ActionCreate:
+			err = tx.QueryRow(
+				ctx,
+				`INSERT INTO signatures (user_id, employee_email, template_data, brand_kit_id, directory_id)
+                 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+				config.UserID,
+				change.Email,
+				change.TemplateData,
+				config.BrandKitID,
+				config.ID,
+			).Scan(&report.Changes[i].SignatureID)
+		case ActionUpdate, ActionRestore:
+			_, err = tx.Exec(
+				ctx,
+				"UPDATE signatures SET template_data = $1, directory_id = $2, employee_email = $3, archived_at = NULL WHERE id = $4",
+				change.TemplateData,
+				config.ID,
+				change.Email,
+				change.SignatureID,
+			)
+		case ActionArchive:
+			_, err = tx.Exec(ctx, "UPDATE signatures SET archived_at = NOW() WHERE id = $1", change.SignatureID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s signature for %s: %w", change.Action, change.Email, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordRun stores the outcome of a sync in directory_sync_runs
+func (databaseStore) RecordRun(ctx context.Context, config *Config, startedAt time.Time, report *Report, syncErr error) error {
+	status, message := StatusSucceeded, ""
+	if syncErr != nil {
+		status, message = StatusFailed, syncErr.Error()
+		report = &Report{Changes: []Change{}, Skipped: []string{}}
+	}
+
+	_, err := database.DB.Exec(
+		ctx,
+		`INSERT INTO directory_sync_runs
+             (directory_id, started_at, status, error, users, created, updated, archived, restored, unchanged, changes, skipped)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		config.ID,
+		startedAt,
+		status,
+		message,
+		report.Users,
+		report.Created,
+		report.Updated,
+		report.Archived,
+		report.Restored,
+		report.Unchanged,
+		report.Changes,
+		report.Skipped,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.DB.Exec(ctx, "UPDATE directories SET last_synced_at = $1 WHERE id = $2", startedAt, config.ID)
+	return err
+}
+
+// schedulerTick is how often the scheduler looks for directories due a sync
+const schedulerTick = time.Minute
+
+// StartScheduler syncs every directory whose interval has elapsed since its
+// last sync, checking once a minute until the context is cancelled
+func StartScheduler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(schedulerTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				syncDue(ctx)
+			}
+		}
+	}()
+}
+
+func syncDue(ctx context.Context) {
+	rows, err := database.DB.Query(
+		ctx,
+		`SELECT `+Columns+` FROM directories
+         WHERE interval_minutes > 0
+           AND (last_synced_at IS NULL OR last_synced_at + interval_minutes * INTERVAL '1 minute' <= NOW())`,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch directories due a sync: %v\n", err)
+		return
+	}
+	configs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Config, error) {
+		return Scan(row)
+	})
+	if err != nil {
+		log.Printf("Failed to parse directories: %v\n", err)
+		return
+	}
+
+	for _, config := range configs {
+		report, err := Run(ctx, config, false)
+		if err != nil {
+			log.Printf("Directory sync of %s failed: %v\n", config.Name, err)
+			continue
+		}
+		log.Printf("Directory sync of %s: %d created, %d updated, %d archived, %d restored\n",
+			config.Name, report.Created, report.Updated, report.Archived, report.Restored)
+	}
+}
@@ -0,0 +1,239 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps signatures in memory in place of the database
+type memoryStore struct {
+	signatures []Signature
+	runs       []error
+	nextID     int
+}
+
+func (m *memoryStore) Current(ctx context.Context, config *Config, emails []string) ([]Signature, error) {
+	listed := map[string]bool{}
+	for _, email := range emails {
+		listed[strings.ToLower(email)] = true
+	}
+	var current []Signature
+	for _, s := range m.signatures {
+		if s.Managed || listed[strings.ToLower(s.Email)] {
+			current = append(current, s)
+		}
+	}
+	return current, nil
+}
+
+func (m *memoryStore) Write(ctx context.Context, config *Config, report *Report) error {
+	for i, change := range report.Changes {
+		if change.Action == ActionCreate {
+			m.nextID++
+			report.Changes[i].SignatureID = fmt.Sprintf("new-%d", m.nextID)
+			m.signatures = append(m.signatures, Signature{ID: report.Changes[i].SignatureID, Email: change.Email, TemplateData: change.TemplateData, Managed: true})
+			continue
+		}
+		for j := range m.signatures {
+			if m.signatures[j].ID != change.SignatureID {
+				continue
+			}
+			switch change.Action {
+			case ActionUpdate, ActionRestore:
+				m.signatures[j].TemplateData = change.TemplateData
+				m.signatures[j].Managed = true
+				m.signatures[j].Archived = false
+			case ActionArchive:
+				m.signatures[j].Archived = true
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) RecordRun(ctx context.Context, config *Config, startedAt time.Time, report *Report, syncErr error) error {
+	m.runs = append(m.runs, syncErr)
+	return nil
+}
+
+// useStore swaps in an in-memory store for the test
+func useStore(t *testing.T, signatures ...Signature) *memoryStore {
+	t.Helper()
+	m := &memoryStore{signatures: signatures}
+	previous := store
+	store = m
+	t.Cleanup(func() { store = previous })
+	return m
+}
+
+func person(dn, mail, name, title string) Entry {
+	return Entry{DN: dn, Attributes: map[string][]string{"mail": {mail}, "cn": {name}, "title": {title}}}
+}
+
+var testConfig = &Config{ID: "dir-1", UserID: "user-1", Attributes: DefaultAttributes}
+
+func TestSyncCreatesUpdatesAndArchives(t *testing.T) {
+	m := useStore(t,
+		// Stored with a mixed-case email before the directory existed
+		Signature{ID: "sig-jane", Email: "Jane.Doe@Example.com", TemplateData: map[string]interface{}{"email": "Jane.Doe@Example.com", "name": "Jane Doe", "linkedin": "https://linkedin.com/in/jane"}},
+		Signature{ID: "sig-left", Email: "left@example.com", TemplateData: map[string]interface{}{"email": "left@example.com", "name": "Left"}, Managed: true},
+		Signature{ID: "sig-back", Email: "back@example.com", TemplateData: map[string]interface{}{"email": "back@example.com", "name": "Back"}, Managed: true, Archived: true},
+	)
+	source := StaticSource{
+		person("uid=jane", "jane.doe@example.com", "Jane Doe", "CTO"),
+		person("uid=new", "New.Hire@example.com", "New Hire", "Engineer"),
+		person("uid=back", "back@example.com", "Back", ""),
+	}
+
+	report, err := Sync(context.Background(), testConfig, source, false)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if report.Users != 3 || report.Created != 1 || report.Updated != 1 || report.Archived != 1 || report.Restored != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(m.signatures) != 4 {
+		t.Fatalf("signatures = %+v, want the mixed-case signature adopted rather than duplicated", m.signatures)
+	}
+
+	jane := m.signatures[0]
+	if !jane.Managed || jane.TemplateData["job_title"] != "CTO" || jane.TemplateData["email"] != "jane.doe@example.com" {
+		t.Errorf("adopted signature = %+v", jane)
+	}
+	if jane.TemplateData["linkedin"] != "https://linkedin.com/in/jane" {
+		t.Errorf("unmapped field was dropped: %+v", jane.TemplateData)
+	}
+	if !m.signatures[1].Archived {
+		t.Errorf("signature of an employee who left is not archived")
+	}
+	if m.signatures[2].Archived {
+		t.Errorf("signature of a returning employee is not restored")
+	}
+	if created := m.signatures[3]; created.Email != "new.hire@example.com" || created.TemplateData["name"] != "New Hire" {
+		t.Errorf("created signature = %+v", created)
+	}
+	if len(m.runs) != 1 || m.runs[0] != nil {
+		t.Errorf("runs = %v, want one successful run", m.runs)
+	}
+
+	// A second sync finds nothing to change
+	report, err = Sync(context.Background(), testConfig, source, false)
+	if err != nil {
+		t.Fatalf("second Sync: %v", err)
+	}
+	if report.Unchanged != 3 || len(report.Changes) != 0 {
+		t.Errorf("second report = %+v, want everything unchanged", report)
+	}
+}
+
+func TestSyncDryRunWritesNothing(t *testing.T) {
+	m := useStore(t)
+	report, err := Sync(context.Background(), testConfig, StaticSource{person("uid=a", "a@example.com", "A", "")}, true)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !report.DryRun || report.Created != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(m.signatures) != 0 || len(m.runs) != 0 {
+		t.Errorf("dry run wrote %d signatures and %d runs", len(m.signatures), len(m.runs))
+	}
+}
+
+func TestSyncLintsWrittenSignatures(t *testing.T) {
+	useStore(t, Signature{ID: "sig-left", Email: "left@example.com", Managed: true})
+	linted := []string{}
+	Lint = func(signatureID string) (int, error) {
+		linted = append(linted, signatureID)
+		if signatureID == "new-2" {
+			return 0, errors.New("render failed")
+		}
+		return 2, nil
+	}
+	t.Cleanup(func() { Lint = nil })
+
+	source := StaticSource{person("uid=a", "a@example.com", "A", ""), person("uid=b", "b@example.com", "B", "")}
+	report, err := Sync(context.Background(), testConfig, source, false)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	// The archived signature is not linted and a failure to lint is not
+	// counted
+	if strings.Join(linted, ",") != "new-1,new-2" {
+		t.Errorf("linted %v, want the created signatures", linted)
+	}
+	if report.LintErrors != 2 || report.Changes[0].LintErrors != 2 || report.Changes[1].LintErrors != 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestSyncRefusesEmptyDirectory(t *testing.T) {
+	m := useStore(t, Signature{ID: "sig", Email: "a@example.com", Managed: true})
+	if _, err := Sync(context.Background(), testConfig, StaticSource{}, false); !errors.Is(err, ErrNoUsers) {
+		t.Fatalf("err = %v, want ErrNoUsers", err)
+	}
+	if m.signatures[0].Archived {
+		t.Errorf("empty directory archived signatures")
+	}
+	if len(m.runs) != 1 || !errors.Is(m.runs[0], ErrNoUsers) {
+		t.Errorf("runs = %v, want the failed run recorded", m.runs)
+	}
+}
+
+func TestPlanSkipsInvalidEntries(t *testing.T) {
+	entries := []Entry{
+		person("uid=nomail", "", "No Mail", ""),
+		person("uid=noname", "noname@example.com", "", ""),
+		person("uid=a", "a@example.com", "A", ""),
+		person("uid=dup", "A@example.com", "Duplicate", ""),
+	}
+	current := []Signature{{ID: "sig", Email: "noname@example.com", Managed: true}}
+
+	report := Plan(entries, DefaultAttributes, current)
+	if len(report.Skipped) != 3 {
+		t.Errorf("skipped = %v, want the entries without email or name and the duplicate", report.Skipped)
+	}
+	if report.Created != 1 || report.Archived != 0 {
+		t.Errorf("report = %+v, want one created and the skipped employee kept", report)
+	}
+}
+
+func TestBindPasswordEncryption(t *testing.T) {
+	encryptionKey = nil
+	if _, err := EncryptPassword("secret"); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Fatalf("err = %v, want ErrNoEncryptionKey without a key", err)
+	}
+
+	encryptionKey = []byte("0123456789abcdef0123456789abcdef")
+	t.Cleanup(func() { encryptionKey = nil })
+
+	stored, err := EncryptPassword("secret")
+	if err != nil {
+		t.Fatalf("EncryptPassword: %v", err)
+	}
+	if !strings.HasPrefix(stored, encryptedPrefix) || strings.Contains(stored, "secret") {
+		t.Errorf("stored = %q, want an encrypted value", stored)
+	}
+	if again, _ := EncryptPassword("secret"); again == stored {
+		t.Errorf("encrypting twice gave the same value, nonces are reused")
+	}
+	if password, err := DecryptPassword(stored); err != nil || password != "secret" {
+		t.Errorf("DecryptPassword = %q, %v", password, err)
+	}
+
+	if password, err := DecryptPassword("legacy"); err != nil || password != "legacy" {
+		t.Errorf("legacy plaintext = %q, %v", password, err)
+	}
+	if empty, err := EncryptPassword(""); err != nil || empty != "" {
+		t.Errorf("empty password = %q, %v", empty, err)
+	}
+
+	encryptionKey = []byte("fedcba9876543210fedcba9876543210")
+	if _, err := DecryptPassword(stored); err == nil {
+		t.Errorf("decrypting with another key succeeded")
+	}
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Step transforms a parsed signature fragment in place
+type Step func(nodes []*html.Node) ([]*html.Node, error)
+
+// Pipeline post-processes rendered signature HTML before it is exported
+type Pipeline struct {
+	steps []Step
+}
+
+// Result is the processed HTML along with its size before and after
+type Result struct {
+	HTML          string `json:"html"`
+	OriginalBytes int    `json:"original_bytes"`
+	FinalBytes    int    `json:"final_bytes"`
+}
+
+// Saved returns the number of bytes the pipeline removed
+func (r Result) Saved() int {
+	return r.OriginalBytes - r.FinalBytes
+}
+
+// NewPipeline builds a pipeline that runs the given steps in order
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// DefaultPipeline inlines <style> rules into style attributes and then
+// minifies the result, which is what every exported signature goes through
+func DefaultPipeline() *Pipeline {
+	return NewPipeline(InlineCSS, Minify)
+}
+
+// Process runs every step over the signature HTML
+func (p *Pipeline) Process(signatureHTML string) (Result, error) {
+	result := Result{OriginalBytes: len(signatureHTML)}
+
+	// Signatures are fragments, so parse them as the contents of a <body>
+	nodes, err := html.ParseFragment(strings.NewReader(signatureHTML), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, step := range p.steps {
+		if nodes, err = step(nodes); err != nil {
+			return result, err
+		}
+	}
+
+	var buf strings.Builder
+	for _, n := range nodes {
+		if err := html.Render(&buf, n); err != nil {
+			return result, err
+		}
+	}
+
+	result.HTML = buf.String()
+	result.FinalBytes = len(result.HTML)
+	return result, nil
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+func getAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func setAttr(n *html.Node, name, value string) {
+	for i, a := range n.Attr {
+		if a.Key == name {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: name, Val: value})
+}
+
+func removeAttr(n *html.Node, name string) {
+	for i, a := range n.Attr {
+		if a.Key == name {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+// inline runs only the CSS inlining step
+func inline(t *testing.T, signatureHTML string) string {
+	t.Helper()
+	result, err := NewPipeline(InlineCSS).Process(signatureHTML)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	return result.HTML
+}
+
+func TestInlineCSSCascade(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			"type selector",
+			`<style>td { color: red; }</style><table><tr><td>x</td></tr></table>`,
+			`<td style="color: red;">`,
+		},
+		{
+			"specificity beats source order",
+			`<style>td.name { color: blue; } td { color: red; }</style><table><tr><td class="name">x</td></tr></table>`,
+			`<td class="name" style="color: blue;">`,
+		},
+		{
+			"later rule wins at equal specificity",
+			`<style>td { color: red; } td { color: green; }</style><table><tr><td>x</td></tr></table>`,
+			`<td style="color: green;">`,
+		},
+		{
+			"inline beats stylesheet",
+			`<style>td { color: red; }</style><table><tr><td style="color: blue">x</td></tr></table>`,
+			`<td style="color: blue;">`,
+		},
+		{
+			"stylesheet important beats inline",
+			`<style>td { color: red !important; }</style><table><tr><td style="color: blue">x</td></tr></table>`,
+			`<td style="color: red;">`,
+		},
+		{
+			"inline important beats stylesheet important",
+			`<style>td { color: red !important; }</style><table><tr><td style="color: blue !important">x</td></tr></table>`,
+			`<td style="color: blue !important;">`,
+		},
+		{
+			"child combinator",
+			`<style>tr > td { color: red; } table > td { color: blue; }</style><table><tr><td>x</td></tr></table>`,
+			`<td style="color: red;">`,
+		},
+		{
+			"descendant combinator",
+			`<style>.card a { color: red; }</style><div class="card"><table><tr><td><a href="#">x</a></td></tr></table></div>`,
+			`<a href="#" style="color: red;">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inline(t, tt.html); !strings.Contains(got, tt.want) {
+				t.Errorf("got %s, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineCSSSemicolonsInValues(t *testing.T) {
+	image := `url(data:image/png;base64,iVBORw0KGgo=)`
+	got := inline(t, `<style>td { background-image: `+image+`; color: red; }</style><table><tr><td style="font-family: 'A;B', sans-serif">x</td></tr></table>`)
+	for _, want := range []string{"background-image: " + image + ";", "color: red;", "font-family: &#39;A;B&#39;, sans-serif;"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %s, want it to contain %s", got, want)
+		}
+	}
+}
+
+func TestInlineCSSKeepsRulesThatCannotBeInlined(t *testing.T) {
+	got := inline(t, `<style>td { color: red; } a:hover { color: blue; } @media (max-width: 480px) { td { display: block; } }</style><table><tr><td>x</td></tr></table>`)
+	if !strings.HasPrefix(got, `<style>a:hover{color: blue;}@media (max-width: 480px){td{display: block;}}</style>`) {
+		t.Errorf("retained rules missing: %s", got)
+	}
+	if !strings.Contains(got, `<td style="color: red;">`) {
+		t.Errorf("rule not inlined: %s", got)
+	}
+}
+
+func TestDefaultPipeline(t *testing.T) {
+	signatureHTML := `<style>
+    td { color: red; }
+</style>
+<!-- contact details -->
+<!--[if mso]><table><tr><td><![endif]-->
+<table>
+    <tr>
+        <td style="padding: 4px ;  font-weight : bold !important">Jane   <b>Doe</b></td>
+    </tr>
+</table>`
+
+	result, err := DefaultPipeline().Process(signatureHTML)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	want := `<!--[if mso]><table><tr><td><![endif]--><table><tbody><tr><td style="color:red;padding:4px;font-weight:bold!important">Jane <b>Doe</b></td></tr></tbody></table>`
+	if result.HTML != want {
+		t.Errorf("HTML =\n%s\nwant\n%s", result.HTML, want)
+	}
+	if result.OriginalBytes != len(signatureHTML) || result.FinalBytes != len(result.HTML) {
+		t.Errorf("sizes = %d/%d, want %d/%d", result.OriginalBytes, result.FinalBytes, len(signatureHTML), len(result.HTML))
+	}
+	if result.Saved() <= 0 {
+		t.Errorf("Saved() = %d, want the pipeline to shrink the HTML", result.Saved())
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	rewrite := RewriteLinks(func(href string) (string, error) {
+		if strings.HasPrefix(href, "https://") {
+			return "https://t.example.com/r/abc", nil
+		}
+		return href, nil
+	})
+	result, err := NewPipeline(rewrite).Process(`<a href="https://example.com">x</a><a href="mailto:a@example.com">y</a>`)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	want := `<a href="https://t.example.com/r/abc">x</a><a href="mailto:a@example.com">y</a>`
+	if result.HTML != want {
+		t.Errorf("HTML = %s, want %s", result.HTML, want)
+	}
+}
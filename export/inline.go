@@ -0,0 +1,432 @@
+package export
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	cssComment      = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssWhitespace   = regexp.MustCompile(`\s+`)
+	cssPunctuation  = regexp.MustCompile(`\s*([{};,])\s*`)
+	compoundPattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)?((?:[.#][a-zA-Z_-][a-zA-Z0-9_-]*)*)$`)
+	simplePattern   = regexp.MustCompile(`[.#][a-zA-Z_-][a-zA-Z0-9_-]*`)
+	importantFlag   = regexp.MustCompile(`(?i)\s*!\s*important\s*$`)
+)
+
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// compound is a run of simple selectors with no combinator, e.g. td.name#main
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// selector is a chain of compounds joined by descendant (' ') or child ('>')
+// combinators; combinators[i] sits between parts[i] and parts[i+1]
+type selector struct {
+	parts       []compound
+	combinators []byte
+}
+
+type cssRule struct {
+	selector     selector
+	specificity  int
+	order        int
+	declarations []declaration
+}
+
+// InlineCSS moves rules from <style> blocks into each matching element's
+// style attribute. Rules that cannot be expressed inline, such as media
+// queries and pseudo-classes, are kept in a single <style> block.
+func InlineCSS(nodes []*html.Node) ([]*html.Node, error) {
+	var stylesheet strings.Builder
+	var styleNodes []*html.Node
+	for _, root := range nodes {
+		walk(root, func(n *html.Node) {
+			if n.Type == html.ElementNode && n.DataAtom == atom.Style {
+				styleNodes = append(styleNodes, n)
+				for child := n.FirstChild; child != nil; child = child.NextSibling {
+					stylesheet.WriteString(child.Data)
+				}
+				stylesheet.WriteString("\n")
+			}
+		})
+	}
+
+	if len(styleNodes) == 0 {
+		return nodes, nil
+	}
+
+	// Detach the original <style> blocks, they are replaced below
+	nodes = removeNodes(nodes, styleNodes)
+
+	rules, retained := parseStylesheet(stylesheet.String())
+	for _, root := range nodes {
+		walk(root, func(n *html.Node) {
+			if n.Type == html.ElementNode {
+				applyRules(n, rules)
+			}
+		})
+	}
+
+	if len(retained) > 0 {
+		style := &html.Node{Type: html.ElementNode, Data: "style", DataAtom: atom.Style}
+		style.AppendChild(&html.Node{Type: html.TextNode, Data: strings.Join(retained, "")})
+		nodes = append([]*html.Node{style}, nodes...)
+	}
+
+	return nodes, nil
+}
+
+// removeNodes detaches each target from its parent, or drops it from the
+// top-level list when it has none
+func removeNodes(nodes []*html.Node, targets []*html.Node) []*html.Node {
+	remove := make(map[*html.Node]bool, len(targets))
+	for _, n := range targets {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+			continue
+		}
+		remove[n] = true
+	}
+
+	kept := nodes[:0]
+	for _, n := range nodes {
+		if !remove[n] {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// parseStylesheet splits CSS into rules that can be inlined and the raw
+// text of the rules that must stay in a <style> block
+func parseStylesheet(css string) ([]cssRule, []string) {
+	css = cssComment.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	var retained []string
+	order := 0
+	for {
+		css = strings.TrimSpace(css)
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+		end := matchingBrace(css, open)
+		if end < 0 {
+			break
+		}
+
+		prelude := strings.TrimSpace(css[:open])
+		body := css[open+1 : end]
+		css = css[end+1:]
+
+		if strings.HasPrefix(prelude, "@") {
+			retained = append(retained, compactCSS(prelude+"{"+body+"}"))
+			continue
+		}
+
+		declarations := parseDeclarations(body)
+		for _, raw := range strings.Split(prelude, ",") {
+			raw = strings.TrimSpace(raw)
+			sel, ok := parseSelector(raw)
+			if !ok {
+				retained = append(retained, compactCSS(raw+"{"+body+"}"))
+				continue
+			}
+			rules = append(rules, cssRule{
+				selector:     sel,
+				specificity:  sel.specificity(),
+				order:        order,
+				declarations: declarations,
+			})
+			order++
+		}
+	}
+
+	return rules, retained
+}
+
+// matchingBrace returns the index of the brace closing the one at open
+func matchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func compactCSS(css string) string {
+	css = cssWhitespace.ReplaceAllString(strings.TrimSpace(css), " ")
+	return cssPunctuation.ReplaceAllString(css, "$1")
+}
+
+func parseDeclarations(body string) []declaration {
+	var declarations []declaration
+	for _, part := range splitDeclarations(body) {
+		property, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = cssWhitespace.ReplaceAllString(strings.TrimSpace(value), " ")
+		important := false
+		if loc := importantFlag.FindStringIndex(value); loc != nil {
+			important = true
+			value = value[:loc[0]]
+		}
+		if property == "" || value == "" {
+			continue
+		}
+
+		declarations = append(declarations, declaration{property: property, value: value, important: important})
+	}
+	return declarations
+}
+
+// splitDeclarations splits a declaration block at semicolons, except those
+// inside parentheses or quotes such as url(data:image/png;base64,...)
+func splitDeclarations(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ';' && depth == 0:
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, body[start:])
+}
+
+// parseSelector parses the subset of CSS selectors that can be resolved
+// statically: type, class and id selectors joined by descendant or child
+// combinators
+func parseSelector(raw string) (selector, bool) {
+	if raw == "" || strings.ContainsAny(raw, ":[*+~") {
+		return selector{}, false
+	}
+
+	var sel selector
+	pendingChild := false
+	for _, token := range strings.Fields(strings.ReplaceAll(raw, ">", " > ")) {
+		if token == ">" {
+			if len(sel.parts) == 0 || pendingChild {
+				return selector{}, false
+			}
+			pendingChild = true
+			continue
+		}
+
+		match := compoundPattern.FindStringSubmatch(token)
+		if match == nil {
+			return selector{}, false
+		}
+
+		part := compound{tag: strings.ToLower(match[1])}
+		for _, simple := range simplePattern.FindAllString(match[2], -1) {
+			if simple[0] == '#' {
+				part.id = simple[1:]
+			} else {
+				part.classes = append(part.classes, simple[1:])
+			}
+		}
+
+		if len(sel.parts) > 0 {
+			if pendingChild {
+				sel.combinators = append(sel.combinators, '>')
+			} else {
+				sel.combinators = append(sel.combinators, ' ')
+			}
+		}
+		sel.parts = append(sel.parts, part)
+		pendingChild = false
+	}
+
+	return sel, len(sel.parts) > 0 && !pendingChild
+}
+
+// specificity packs id, class and type counts into one comparable number
+func (s selector) specificity() int {
+	ids, classes, tags := 0, 0, 0
+	for _, part := range s.parts {
+		if part.id != "" {
+			ids++
+		}
+		classes += len(part.classes)
+		if part.tag != "" {
+			tags++
+		}
+	}
+	return ids*10000 + classes*100 + tags
+}
+
+func (s selector) matches(n *html.Node) bool {
+	return s.matchAt(len(s.parts)-1, n)
+}
+
+func (s selector) matchAt(i int, n *html.Node) bool {
+	if !s.parts[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	if s.combinators[i-1] == '>' {
+		parent := parentElement(n)
+		return parent != nil && s.matchAt(i-1, parent)
+	}
+	for parent := parentElement(n); parent != nil; parent = parentElement(parent) {
+		if s.matchAt(i-1, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compound) matches(n *html.Node) bool {
+	if c.tag != "" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" {
+		if id, _ := getAttr(n, "id"); id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		value, _ := getAttr(n, "class")
+		classes := strings.Fields(value)
+		for _, want := range c.classes {
+			found := false
+			for _, class := range classes {
+				if class == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func parentElement(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
+
+// applyRules merges matching rules into the element's style attribute,
+// following the cascade: stylesheet rules by specificity and source order,
+// then the existing inline style, then !important stylesheet declarations
+// and finally !important inline declarations, which keep their flag
+func applyRules(n *html.Node, rules []cssRule) {
+	var matched []cssRule
+	for _, rule := range rules {
+		if rule.selector.matches(n) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].specificity != matched[j].specificity {
+			return matched[i].specificity < matched[j].specificity
+		}
+		return matched[i].order < matched[j].order
+	})
+
+	var inline []declaration
+	if value, ok := getAttr(n, "style"); ok {
+		inline = parseDeclarations(value)
+	}
+
+	style := newStyleMap()
+	for _, important := range []bool{false, true} {
+		for _, rule := range matched {
+			for _, d := range rule.declarations {
+				if d.important == important {
+					style.set(declaration{property: d.property, value: d.value})
+				}
+			}
+		}
+		for _, d := range inline {
+			if d.important == important {
+				style.set(d)
+			}
+		}
+	}
+
+	setAttr(n, "style", style.String())
+}
+
+// styleMap keeps declarations in the order their property first appeared
+type styleMap struct {
+	properties []string
+	values     map[string]declaration
+}
+
+func newStyleMap() *styleMap {
+	return &styleMap{values: map[string]declaration{}}
+}
+
+func (m *styleMap) set(d declaration) {
+	if _, ok := m.values[d.property]; !ok {
+		m.properties = append(m.properties, d.property)
+	}
+	m.values[d.property] = d
+}
+
+func (m *styleMap) String() string {
+	parts := make([]string, 0, len(m.properties))
+	for _, property := range m.properties {
+		d := m.values[property]
+		if d.important {
+			parts = append(parts, property+": "+d.value+" !important")
+		} else {
+			parts = append(parts, property+": "+d.value)
+		}
+	}
+	return strings.Join(parts, "; ") + ";"
+}
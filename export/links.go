@@ -0,0 +1,34 @@
+package export
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// LinkRewriter returns the URL to use instead of href, or href itself to
+// leave the link alone
+type LinkRewriter func(href string) (string, error)
+
+// RewriteLinks returns a step that passes the href of every <a> through
+// rewrite, for example to route outbound links through click tracking
+func RewriteLinks(rewrite LinkRewriter) Step {
+	return func(nodes []*html.Node) ([]*html.Node, error) {
+		var err error
+		for _, root := range nodes {
+			walk(root, func(n *html.Node) {
+				if err != nil || n.Type != html.ElementNode || n.DataAtom != atom.A {
+					return
+				}
+				href, ok := getAttr(n, "href")
+				if !ok {
+					return
+				}
+				var rewritten string
+				if rewritten, err = rewrite(href); err == nil && rewritten != href {
+					setAttr(n, "href", rewritten)
+				}
+			})
+		}
+		return nodes, err
+	}
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// inlineElements are the elements whitespace between which is significant
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "code": true, "em": true, "font": true,
+	"i": true, "img": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "u": true,
+}
+
+// preservedElements keep their text content untouched
+var preservedElements = map[string]bool{
+	"pre": true, "textarea": true, "style": true, "script": true,
+}
+
+// Minify strips comments, collapses whitespace and compacts style
+// attributes. Outlook conditional comments are kept since they carry
+// markup only Outlook renders.
+func Minify(nodes []*html.Node) ([]*html.Node, error) {
+	var kept []*html.Node
+	for _, n := range nodes {
+		if removable(n) {
+			continue
+		}
+		kept = append(kept, n)
+	}
+
+	for i, n := range kept {
+		var prev, next *html.Node
+		if i > 0 {
+			prev = kept[i-1]
+		}
+		if i < len(kept)-1 {
+			next = kept[i+1]
+		}
+		if n.Type == html.TextNode {
+			n.Data = collapseText(n.Data, prev, next)
+		}
+		minifyChildren(n)
+	}
+
+	return kept, nil
+}
+
+func minifyChildren(n *html.Node) {
+	if n.Type == html.ElementNode {
+		if style, ok := getAttr(n, "style"); ok {
+			if compact := compactStyle(style); compact != "" {
+				setAttr(n, "style", compact)
+			} else {
+				removeAttr(n, "style")
+			}
+		}
+		if preservedElements[n.Data] {
+			return
+		}
+	}
+
+	child := n.FirstChild
+	for child != nil {
+		next := child.NextSibling
+		if removable(child) {
+			n.RemoveChild(child)
+		} else {
+			if child.Type == html.TextNode {
+				child.Data = collapseText(child.Data, child.PrevSibling, child.NextSibling)
+			}
+			minifyChildren(child)
+		}
+		child = next
+	}
+}
+
+// removable reports whether a node can be dropped entirely: regular
+// comments and whitespace between block-level elements
+func removable(n *html.Node) bool {
+	switch n.Type {
+	case html.CommentNode:
+		data := strings.TrimSpace(n.Data)
+		return !strings.HasPrefix(data, "[if") && !strings.HasPrefix(data, "<![endif]") && !strings.HasPrefix(data, "[endif]")
+	case html.TextNode:
+		if strings.TrimSpace(n.Data) != "" {
+			return false
+		}
+		if n.Parent != nil && preservedElements[n.Parent.Data] {
+			return false
+		}
+		return !isInline(n.PrevSibling) || !isInline(n.NextSibling)
+	}
+	return false
+}
+
+// collapseText reduces whitespace runs to single spaces and drops them
+// entirely where they border a block-level sibling
+func collapseText(text string, prev, next *html.Node) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		return " "
+	}
+
+	if startsWithSpace(text) && isInline(prev) {
+		collapsed = " " + collapsed
+	}
+	if endsWithSpace(text) && isInline(next) {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+func isInline(n *html.Node) bool {
+	if n == nil {
+		return false
+	}
+	if n.Type == html.TextNode {
+		return true
+	}
+	return n.Type == html.ElementNode && inlineElements[n.Data]
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\r\n\f", rune(s[0]))
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\r\n\f", rune(s[len(s)-1]))
+}
+
+// compactStyle rewrites a style attribute without optional whitespace
+func compactStyle(style string) string {
+	declarations := parseDeclarations(style)
+	parts := make([]string, 0, len(declarations))
+	for _, d := range declarations {
+		value := d.value
+		if d.important {
+			value += "!important"
+		}
+		parts = append(parts, d.property+":"+value)
+	}
+	return strings.Join(parts, ";")
+}
@@ -4,38 +4,97 @@ import (
 	"context"
 	"email-signature-backend/database"
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
 	"log"
+	"time"
 )
 
 type AnalyticsResponse struct {
 	LinkID      string `json:"link_id"`
 	TotalClicks int    `json:"total_clicks"`
-	LastClicked string `json:"last_clicked"`
+	// UniqueClicks counts each visitor once per link and day
+	UniqueClicks int `json:"unique_clicks"`
+	// BotClicks and ScannerClicks count non-human clicks, which
+	// TotalClicks leaves out unless include_bots is set
+	BotClicks     int `json:"bot_clicks"`
+	ScannerClicks int `json:"scanner_clicks"`
+	// LastClicked is null for links without clicks
+	LastClicked *time.Time `json:"last_clicked"`
 }
 
+// analyticsBreakdown counts the clicks of one value of the grouped
+// column; Value is nil for clicks without one, such as those from unknown
+// countries
+type analyticsBreakdown struct {
+	Value         *string
+	TotalClicks   int
+	UniqueClicks  int
+	BotClicks     int
+	ScannerClicks int
+	LastClicked   time.Time
+}
+
+// analyticsBreakdowns are the group_by values besides link and the click
+// column each one groups by
+var analyticsBreakdowns = map[string]string{
+	"campaign": "clicks.campaign",
+	"country":  "clicks.country",
+	"device":   "clicks.device_type",
+	"os":       "clicks.os",
+	"browser":  "clicks.browser",
+}
+
+// clickCounts counts the clicks of a group. total_clicks and unique_clicks
+// leave out bots and scanners unless $2 is true; they are counted on their
+// own. Visitor keys change daily, so unique_clicks counts each visitor once
+// per link and day. Clicks without a key, from before keys existed or with
+// Do-Not-Track, are told apart the same way by their stored IP address and
+// user agent and the day; Do-Not-Track clicks of a link and day store
+// neither and so count as one visitor.
+const clickCounts = `COUNT(clicks.id) FILTER (WHERE clicks.traffic = 'human' OR $2) AS total_clicks,
+            COUNT(DISTINCT (clicks.link_id, COALESCE(
+                clicks.visitor_key,
+                md5(COALESCE(clicks.ip_address, '') || ' ' || clicks.user_agent) || ':' || clicks.timestamp::date
+            ))) FILTER (WHERE (clicks.traffic = 'human' OR $2) AND clicks.id IS NOT NULL) AS unique_clicks,
+            COUNT(clicks.id) FILTER (WHERE clicks.traffic = 'bot') AS bot_clicks,
+            COUNT(clicks.id) FILTER (WHERE clicks.traffic = 'scanner') AS scanner_clicks`
+
 type CountResponse struct {
 	Count int `json:"count"`
 }
 
 // GetAnalytics godoc
 // @Summary Retrieve analytics for user links
-// @Description Fetches the total clicks and last clicked timestamps for all links belonging to the user's signatures
+// @Description Fetches the total and unique clicks and last clicked timestamps for all links belonging to the user's signatures; last_clicked is null for links without clicks. With group_by, clicks are broken down instead by the UTM campaign they were attributed to (campaign), country (ISO code, from the geo-IP database), device type (desktop, mobile or tablet), os or browser; each entry holds the group_by value under its own name, e.g. {"country": "DE", "total_clicks": 12, ...}. unique_clicks counts each visitor once per link and day, telling visitors apart by a hash of their IP address and user agent with a salt that rotates daily; older clicks and clicks with Do-Not-Track are told apart by their stored IP address and user agent instead. Clicks by bots and mail security scanners are reported in bot_clicks and scanner_clicks and left out of total_clicks and unique_clicks unless include_bots is set.
 // @Tags Analytics
 // @Produce json
+// @Param group_by query string false "link (default), campaign, country, device, os or browser"
+// @Param include_bots query bool false "Count bot and scanner clicks in total_clicks"
 // @Success 200 {object} map[string][]AnalyticsResponse
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /api/analytics [get]
 func GetAnalytics(c *fiber.Ctx) error {
 	// Get user_id from context
 	userID := c.Locals("user_id").(string)
+	includeBots := c.QueryBool("include_bots")
+
+	if groupBy := c.Query("group_by", "link"); groupBy != "link" {
+		if _, ok := analyticsBreakdowns[groupBy]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "group_by must be link, campaign, country, device, os or browser",
+			})
+		}
+		return getAnalyticsBreakdown(c, userID, groupBy, includeBots)
+	}
 
 	// Query to fetch click analytics
 	rows, err := database.DB.Query(
 		context.Background(),
 		`SELECT 
             links.id AS link_id, 
-            COUNT(clicks.id) AS total_clicks,
+            `+clickCounts+`,
             MAX(clicks.timestamp) AS last_clicked
          FROM links
          LEFT JOIN clicks ON clicks.link_id = links.id
@@ -44,6 +103,7 @@ func GetAnalytics(c *fiber.Ctx) error {
          )
          GROUP BY links.id`,
 		userID,
+		includeBots,
 	)
 	if err != nil {
 		log.Printf("Failed to fetch analytics: %v\n", err)
@@ -57,7 +117,7 @@ func GetAnalytics(c *fiber.Ctx) error {
 	analytics := []AnalyticsResponse{}
 	for rows.Next() {
 		var response AnalyticsResponse
-		if err := rows.Scan(&response.LinkID, &response.TotalClicks, &response.LastClicked); err != nil {
+		if err := rows.Scan(&response.LinkID, &response.TotalClicks, &response.UniqueClicks, &response.BotClicks, &response.ScannerClicks, &response.LastClicked); err != nil {
 			log.Printf("Failed to parse row: %v\n", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to parse analytics data",
@@ -71,6 +131,54 @@ func GetAnalytics(c *fiber.Ctx) error {
 	})
 }
 
+// getAnalyticsBreakdown reports the user's clicks grouped by one of the
+// analyticsBreakdowns, most clicked first
+func getAnalyticsBreakdown(c *fiber.Ctx, userID, groupBy string, includeBots bool) error {
+	column := analyticsBreakdowns[groupBy]
+	rows, err := database.DB.Query(
+		context.Background(),
+		`SELECT `+column+`, `+clickCounts+`, MAX(clicks.timestamp)
+         FROM clicks
+         JOIN links ON links.id = clicks.link_id
+         WHERE links.signature_id IN (
+             SELECT id FROM signatures WHERE user_id = $1
+         )
+         GROUP BY `+column+`
+         ORDER BY total_clicks DESC`,
+		userID,
+		includeBots,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch %s analytics: %v\n", groupBy, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve analytics",
+		})
+	}
+	breakdowns, err := pgx.CollectRows(rows, pgx.RowToStructByPos[analyticsBreakdown])
+	if err != nil {
+		log.Printf("Failed to parse %s analytics: %v\n", groupBy, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to parse analytics data",
+		})
+	}
+
+	analytics := make([]fiber.Map, len(breakdowns))
+	for i, breakdown := range breakdowns {
+		analytics[i] = fiber.Map{
+			groupBy:          breakdown.Value,
+			"total_clicks":   breakdown.TotalClicks,
+			"unique_clicks":  breakdown.UniqueClicks,
+			"bot_clicks":     breakdown.BotClicks,
+			"scanner_clicks": breakdown.ScannerClicks,
+			"last_clicked":   breakdown.LastClicked,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"analytics": analytics,
+	})
+}
+
 // CountAnalyticsEntries godoc
 // @Summary Get total analytics entries
 // @Description Retrieve the total number of analytics entries (clicks).
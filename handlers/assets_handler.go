@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/assets"
+	"email-signature-backend/config"
+	"email-signature-backend/database"
+	"email-signature-backend/storage"
+	"errors"
+	"io"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AssetResponse struct {
+	ID            string    `json:"id"`
+	Kind          string    `json:"kind"`
+	ContentType   string    `json:"content_type"`
+	ByteSize      int       `json:"byte_size"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	DisplayWidth  int       `json:"display_width"`
+	DisplayHeight int       `json:"display_height"`
+	URL           string    `json:"url"`
+	OriginalURL   string    `json:"original_url"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AssetsListResponse struct {
+	Assets []AssetResponse `json:"assets"`
+}
+
+// assetFilePattern matches the file names assets are stored under
+var assetFilePattern = regexp.MustCompile(`^(original|display)\.(png|jpg|gif)$`)
+
+// UploadAsset godoc
+// @Summary Upload a logo, headshot or banner
+// @Description Uploads an image, validates its type and dimensions, and stores it together with a retina-ready resized version. Use the returned url, display_width and display_height in brand kits and template data.
+// @Tags Assets
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "PNG, JPEG or GIF image"
+// @Param kind formData string true "Asset kind (logo, headshot or banner)"
+// @Success 201 {object} AssetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/assets [post]
+func UploadAsset(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	kind, ok := assets.Kinds[c.FormValue("kind")]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Kind must be logo, headshot or banner"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Missing file"})
+	}
+	if fileHeader.Size > assets.MaxUploadBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: assets.ErrTooLarge.Error()})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Failed to read file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, assets.MaxUploadBytes+1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Failed to read file"})
+	}
+
+	image, err := assets.Process(data, kind)
+	if errors.Is(err, assets.ErrTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	assetID := uuid.New()
+	originalKey := "assets/" + assetID.String() + "/original" + assets.Extension(image.ContentType)
+	displayKey := "assets/" + assetID.String() + "/display" + assets.Extension(image.DisplayContentType)
+
+	ctx := context.Background()
+	if err := storage.Store.Put(ctx, originalKey, data, image.ContentType); err != nil {
+		log.Printf("Failed to store asset: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to store asset"})
+	}
+	if err := storage.Store.Put(ctx, displayKey, image.Display, image.DisplayContentType); err != nil {
+		log.Printf("Failed to store asset: %v\n", err)
+		storage.Store.Delete(ctx, originalKey)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to store asset"})
+	}
+
+	row := database.DB.QueryRow(
+		ctx,
+		`INSERT INTO assets (id, user_id, kind, content_type, byte_size, width, height, display_width, display_height, original_key, display_key)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+         RETURNING `+assetColumns,
+		assetID,
+		userID,
+		kind.Name,
+		image.ContentType,
+		len(data),
+		image.Width,
+		image.Height,
+		image.DisplayWidth,
+		image.DisplayHeight,
+		originalKey,
+		displayKey,
+	)
+	asset, err := scanAsset(row)
+	if err != nil {
+		log.Printf("Failed to insert asset: %v\n", err)
+		storage.Store.Delete(ctx, originalKey)
+		storage.Store.Delete(ctx, displayKey)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to store asset"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(asset)
+}
+
+// GetAssets godoc
+// @Summary List uploaded assets
+// @Description Retrieve the logos and headshots uploaded by the authenticated user
+// @Tags Assets
+// @Produce json
+// @Success 200 {object} AssetsListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/assets [get]
+func GetAssets(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+assetColumns+" FROM assets WHERE user_id = $1 ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch assets: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch assets"})
+	}
+	defer rows.Close()
+
+	list := []AssetResponse{}
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			log.Printf("Failed to parse asset: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse assets"})
+		}
+		list = append(list, *asset)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(AssetsListResponse{Assets: list})
+}
+
+// DeleteAsset godoc
+// @Summary Delete an asset
+// @Description Deletes an uploaded asset and its stored files. Signatures still referencing its URL will show a broken image.
+// @Tags Assets
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/assets/{id} [delete]
+func DeleteAsset(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var originalKey, displayKey string
+	err := database.DB.QueryRow(
+		context.Background(),
+		"DELETE FROM assets WHERE id = $1 AND user_id = $2 RETURNING original_key, display_key",
+		c.Params("id"),
+		userID,
+	).Scan(&originalKey, &displayKey)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Asset not found or unauthorized"})
+	}
+
+	for _, key := range []string{originalKey, displayKey} {
+		if err := storage.Store.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete stored asset %s: %v\n", key, err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Asset deleted successfully"})
+}
+
+// ServeAsset godoc
+// @Summary Serve an uploaded asset
+// @Description Public, cacheable URL for an uploaded image, suitable for embedding in signatures
+// @Tags Assets
+// @Produce png
+// @Produce jpeg
+// @Produce gif
+// @Param id path string true "Asset ID"
+// @Param file path string true "File name (display or original, with extension)"
+// @Success 200 {file} binary
+// @Failure 404 {string} string "Not found"
+// @Router /assets/{id}/{file} [get]
+func ServeAsset(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil || !assetFilePattern.MatchString(c.Params("file")) {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	body, contentType, err := storage.Store.Get(context.Background(), "assets/"+assetID.String()+"/"+c.Params("file"))
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		log.Printf("Failed to read stored asset: %v\n", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		log.Printf("Failed to read stored asset: %v\n", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	// Asset files never change once uploaded, so clients may cache forever
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// assetColumns selects the columns scanAsset expects
+const assetColumns = "id, kind, content_type, byte_size, width, height, display_width, display_height, original_key, display_key, created_at"
+
+func scanAsset(row pgx.Row) (*AssetResponse, error) {
+	asset := &AssetResponse{}
+	var originalKey, displayKey string
+	err := row.Scan(
+		&asset.ID, &asset.Kind, &asset.ContentType, &asset.ByteSize, &asset.Width, &asset.Height,
+		&asset.DisplayWidth, &asset.DisplayHeight, &originalKey, &displayKey, &asset.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	asset.OriginalURL = assetURL(originalKey)
+	asset.URL = assetURL(displayKey)
+	return asset, nil
+}
+
+// assetURL returns the public URL a stored asset is served from
+func assetURL(key string) string {
+	return config.PublicBaseURL() + "/" + key
+}
@@ -0,0 +1,578 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/templates"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type BrandKitRequest struct {
+	Name           string            `json:"name"`
+	OrganizationID string            `json:"organization_id"`
+	Palette        map[string]string `json:"palette"`
+	FontStack      string            `json:"font_stack"`
+	LogoAssetID    string            `json:"logo_asset_id"`
+	LogoURL        string            `json:"logo_url"`
+	LogoWidth      int               `json:"logo_width"`
+	LogoHeight     int               `json:"logo_height"`
+	IconStyle      string            `json:"icon_style"`
+	LinkColor      string            `json:"link_color"`
+	// DarkPalette and DarkLinkColor are used by email clients in dark mode
+	DarkPalette   map[string]string `json:"dark_palette"`
+	DarkLinkColor string            `json:"dark_link_color"`
+	// DarkLogoAssetID or DarkLogoURL set the logo shown in dark mode
+	DarkLogoAssetID string `json:"dark_logo_asset_id"`
+	DarkLogoURL     string `json:"dark_logo_url"`
+	DarkLogoWidth   int    `json:"dark_logo_width"`
+	DarkLogoHeight  int    `json:"dark_logo_height"`
+}
+
+// resolveLogoAsset fills in the logo URLs and dimensions from uploaded
+// logo assets when logo_asset_id or dark_logo_asset_id is set
+func (r *BrandKitRequest) resolveLogoAsset(userID string) error {
+	if r.LogoAssetID != "" {
+		asset, err := loadLogoAsset(r.LogoAssetID, userID)
+		if err != nil {
+			return fmt.Errorf("logo asset not found")
+		}
+		r.LogoURL, r.LogoWidth, r.LogoHeight = asset.URL, asset.DisplayWidth, asset.DisplayHeight
+	}
+
+	if r.DarkLogoAssetID != "" {
+		asset, err := loadLogoAsset(r.DarkLogoAssetID, userID)
+		if err != nil {
+			return fmt.Errorf("dark logo asset not found")
+		}
+		r.DarkLogoURL, r.DarkLogoWidth, r.DarkLogoHeight = asset.URL, asset.DisplayWidth, asset.DisplayHeight
+	}
+	return nil
+}
+
+// loadLogoAsset fetches a logo the user uploaded
+func loadLogoAsset(assetID, userID string) (*AssetResponse, error) {
+	return scanAsset(database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+assetColumns+" FROM assets WHERE id = $1 AND user_id = $2 AND kind = 'logo'",
+		assetID,
+		userID,
+	))
+}
+
+// brand returns the styling part of the request
+func (r *BrandKitRequest) brand() templates.Brand {
+	return templates.Brand{
+		Palette:        r.Palette,
+		FontStack:      r.FontStack,
+		LogoURL:        r.LogoURL,
+		LogoWidth:      r.LogoWidth,
+		LogoHeight:     r.LogoHeight,
+		IconStyle:      r.IconStyle,
+		LinkColor:      r.LinkColor,
+		DarkPalette:    r.DarkPalette,
+		DarkLinkColor:  r.DarkLinkColor,
+		DarkLogoURL:    r.DarkLogoURL,
+		DarkLogoWidth:  r.DarkLogoWidth,
+		DarkLogoHeight: r.DarkLogoHeight,
+	}
+}
+
+type BrandKitResponse struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	UserID         *string         `json:"user_id"`
+	OrganizationID *string         `json:"organization_id"`
+	Brand          templates.Brand `json:"brand"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type BrandKitsListResponse struct {
+	BrandKits []BrandKitResponse `json:"brand_kits"`
+}
+
+type RerenderedSignature struct {
+	SignatureID string               `json:"signature_id"`
+	Lint        []TemplateLintResult `json:"lint"`
+}
+
+type BrandKitPreviewRequest struct {
+	SignatureID string          `json:"signature_id"`
+	Template    string          `json:"template"`
+	Changes     BrandKitRequest `json:"changes"`
+}
+
+type SignatureBrandKitRequest struct {
+	BrandKitID string `json:"brand_kit_id"`
+}
+
+// brandKitColumns selects a brand kit aliased as bk, tolerating the NULLs
+// a LEFT JOIN produces; scan it with brandKitDest
+const brandKitColumns = `COALESCE(bk.palette, '{}'), COALESCE(bk.font_stack, ''), COALESCE(bk.logo_url, ''),
+         COALESCE(bk.logo_width, 0), COALESCE(bk.logo_height, 0), COALESCE(bk.icon_style, ''), COALESCE(bk.link_color, ''),
+         COALESCE(bk.dark_palette, '{}'), COALESCE(bk.dark_link_color, ''), COALESCE(bk.dark_logo_url, ''),
+         COALESCE(bk.dark_logo_width, 0), COALESCE(bk.dark_logo_height, 0)`
+
+// brandKitDest returns scan destinations matching brandKitColumns
+func brandKitDest(brand *templates.Brand) []interface{} {
+	return []interface{}{
+		&brand.Palette, &brand.FontStack, &brand.LogoURL,
+		&brand.LogoWidth, &brand.LogoHeight, &brand.IconStyle, &brand.LinkColor,
+		&brand.DarkPalette, &brand.DarkLinkColor, &brand.DarkLogoURL,
+		&brand.DarkLogoWidth, &brand.DarkLogoHeight,
+	}
+}
+
+// brandKitAccess limits brand kits (aliased bk) to those the user bound to
+// userParam owns or, for organization kits, belongs to; adminOnly further
+// requires the admin role, for changes
+func brandKitAccess(userParam string, adminOnly bool) string {
+	role := ""
+	if adminOnly {
+		role = " AND role = '" + RoleAdmin + "'"
+	}
+	return fmt.Sprintf(`(bk.user_id = %[1]s OR bk.organization_id IN (
+             SELECT organization_id FROM organization_members WHERE user_id = %[1]s%[2]s
+         ))`, userParam, role)
+}
+
+// CreateBrandKit godoc
+// @Summary Create a brand kit
+// @Description Creates a brand kit owned by the authenticated user, or by an organization they administer when organization_id is set
+// @Tags Brand Kits
+// @Accept json
+// @Produce json
+// @Param request body BrandKitRequest true "Brand kit payload"
+// @Success 201 {object} map[string]interface{} "Brand kit created successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits [post]
+func CreateBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(BrandKitRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.resolveLogoAsset(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	brand := req.brand()
+	if err := brand.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if brand.IconStyle == "" {
+		brand.IconStyle = templates.IconStyleText
+	}
+
+	// Organization kits belong to the organization rather than the user
+	var ownerID, organizationID interface{} = userID, nil
+	if req.OrganizationID != "" {
+		if !isOrganizationAdmin(req.OrganizationID, userID) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can create organization brand kits"})
+		}
+		ownerID, organizationID = nil, req.OrganizationID
+	}
+
+	brandKitID := uuid.New()
+	_, err := database.DB.Exec(
+		context.Background(),
+		`INSERT INTO brand_kits (id, user_id, organization_id, name, palette, font_stack, logo_url, logo_width, logo_height, icon_style, link_color,
+             dark_palette, dark_link_color, dark_logo_url, dark_logo_width, dark_logo_height)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		brandKitID,
+		ownerID,
+		organizationID,
+		req.Name,
+		paletteOrEmpty(brand.Palette),
+		brand.FontStack,
+		brand.LogoURL,
+		brand.LogoWidth,
+		brand.LogoHeight,
+		brand.IconStyle,
+		brand.LinkColor,
+		paletteOrEmpty(brand.DarkPalette),
+		brand.DarkLinkColor,
+		brand.DarkLogoURL,
+		brand.DarkLogoWidth,
+		brand.DarkLogoHeight,
+	)
+	if err != nil {
+		log.Printf("Failed to insert brand kit: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create brand kit"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":      "Brand kit created successfully",
+		"brand_kit_id": brandKitID,
+	})
+}
+
+// GetBrandKits godoc
+// @Summary List brand kits
+// @Description Retrieve the brand kits owned by the authenticated user and by their organizations
+// @Tags Brand Kits
+// @Produce json
+// @Success 200 {object} BrandKitsListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits [get]
+func GetBrandKits(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		`SELECT bk.id, bk.name, bk.user_id, bk.organization_id, bk.created_at, bk.updated_at, `+brandKitColumns+`
+         FROM brand_kits bk
+         WHERE `+brandKitAccess("$1", false)+`
+         ORDER BY bk.name`,
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch brand kits: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch brand kits"})
+	}
+	defer rows.Close()
+
+	brandKits := []BrandKitResponse{}
+	for rows.Next() {
+		brandKit, err := scanBrandKit(rows)
+		if err != nil {
+			log.Printf("Failed to parse brand kit: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse brand kits"})
+		}
+		brandKits = append(brandKits, *brandKit)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(BrandKitsListResponse{BrandKits: brandKits})
+}
+
+// GetBrandKit godoc
+// @Summary Get a brand kit
+// @Description Retrieve a single brand kit the authenticated user can access
+// @Tags Brand Kits
+// @Produce json
+// @Param id path string true "Brand kit ID"
+// @Success 200 {object} BrandKitResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits/{id} [get]
+func GetBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	brandKit, err := loadBrandKit(c.Params("id"), userID, false)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Brand kit not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(brandKit)
+}
+
+// UpdateBrandKit godoc
+// @Summary Update a brand kit
+// @Description Replaces a brand kit's name and styling. Every signature using the kit is re-rendered and linted, and the results are returned.
+// @Tags Brand Kits
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand kit ID"
+// @Param request body BrandKitRequest true "Brand kit payload"
+// @Success 200 {object} map[string]interface{} "Brand kit updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits/{id} [put]
+func UpdateBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	brandKitID := c.Params("id")
+
+	req := new(BrandKitRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.resolveLogoAsset(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	brand := req.brand()
+	if err := brand.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if brand.IconStyle == "" {
+		brand.IconStyle = templates.IconStyleText
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		`UPDATE brand_kits bk
+         SET name = $3, palette = $4, font_stack = $5, logo_url = $6, logo_width = $7,
+             logo_height = $8, icon_style = $9, link_color = $10, dark_palette = $11, dark_link_color = $12,
+             dark_logo_url = $13, dark_logo_width = $14, dark_logo_height = $15, updated_at = NOW()
+         WHERE bk.id = $1 AND `+brandKitAccess("$2", true),
+		brandKitID,
+		userID,
+		req.Name,
+		paletteOrEmpty(brand.Palette),
+		brand.FontStack,
+		brand.LogoURL,
+		brand.LogoWidth,
+		brand.LogoHeight,
+		brand.IconStyle,
+		brand.LinkColor,
+		paletteOrEmpty(brand.DarkPalette),
+		brand.DarkLinkColor,
+		brand.DarkLogoURL,
+		brand.DarkLogoWidth,
+		brand.DarkLogoHeight,
+	)
+	if err != nil {
+		log.Printf("Failed to update brand kit: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update brand kit"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Brand kit not found or unauthorized"})
+	}
+
+	// Re-render every signature that uses the kit so problems introduced by
+	// the new styling are reported straight away
+	rerendered, err := rerenderBrandKitSignatures(brandKitID)
+	if err != nil {
+		log.Printf("Failed to re-render brand kit signatures: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Brand kit updated but signatures could not be re-rendered"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Brand kit updated successfully",
+		"signatures": rerendered,
+	})
+}
+
+// DeleteBrandKit godoc
+// @Summary Delete a brand kit
+// @Description Deletes a brand kit. Signatures using it fall back to their template's default styling.
+// @Tags Brand Kits
+// @Produce json
+// @Param id path string true "Brand kit ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits/{id} [delete]
+func DeleteBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM brand_kits bk WHERE bk.id = $1 AND "+brandKitAccess("$2", true),
+		c.Params("id"),
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to delete brand kit: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete brand kit"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Brand kit not found or unauthorized"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Brand kit deleted successfully"})
+}
+
+// PreviewBrandKit godoc
+// @Summary Preview brand kit changes
+// @Description Renders one of the user's signatures with the brand kit as saved and with the proposed changes, side by side, without saving anything
+// @Tags Brand Kits
+// @Accept json
+// @Produce html
+// @Param id path string true "Brand kit ID"
+// @Param request body BrandKitPreviewRequest true "Signature and proposed changes"
+// @Success 200 {string} string "HTML preview comparing current and proposed styling"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/brand-kits/{id}/preview [post]
+func PreviewBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(BrandKitPreviewRequest)
+	if err := c.BodyParser(req); err != nil || req.SignatureID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.Changes.resolveLogoAsset(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	proposed := req.Changes.brand()
+	if err := proposed.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	templateType := req.Template
+	if !templates.Exists(templateType) {
+		templateType = templates.DefaultTemplate
+	}
+
+	brandKit, err := loadBrandKit(c.Params("id"), userID, false)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Brand kit not found"})
+	}
+
+	signature, err := loadSignature(req.SignatureID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	current, err := templates.Render(templateType, templates.Input{Data: signature.TemplateData, Brand: brandKit.Brand})
+	if err != nil {
+		log.Printf("Failed to render signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to generate preview"})
+	}
+	changed, err := templates.Render(templateType, templates.Input{Data: signature.TemplateData, Brand: proposed})
+	if err != nil {
+		log.Printf("Failed to render signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to generate preview"})
+	}
+
+	html := previewDocument(fmt.Sprintf(`
+            <div class="preview-label">Current</div>
+            <div class="signature-container">%s</div>
+            <div class="preview-label">With changes</div>
+            <div class="signature-container">%s</div>
+    `, current, changed))
+
+	c.Type("html")
+	return c.Status(fiber.StatusOK).SendString(html)
+}
+
+// SetSignatureBrandKit godoc
+// @Summary Assign a brand kit to a signature
+// @Description Sets the brand kit a signature is rendered with. An empty brand_kit_id removes it. The response includes a compatibility lint report for every template.
+// @Tags Signatures
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body SignatureBrandKitRequest true "Brand kit assignment"
+// @Success 200 {object} map[string]interface{} "Brand kit assigned successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/brand-kit [put]
+func SetSignatureBrandKit(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(SignatureBrandKitRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	var brandKitID interface{}
+	if req.BrandKitID != "" {
+		if _, err := loadBrandKit(req.BrandKitID, userID, false); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Brand kit not found"})
+		}
+		brandKitID = req.BrandKitID
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"UPDATE signatures SET brand_kit_id = $1 WHERE id = $2 AND user_id = $3",
+		brandKitID,
+		c.Params("id"),
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to assign brand kit: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to assign brand kit"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found or unauthorized"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(withLint(fiber.Map{
+		"message": "Brand kit assigned successfully",
+	}, c.Params("id")))
+}
+
+// loadBrandKit fetches a brand kit the user can read, or modify when
+// requireAdmin is set
+func loadBrandKit(brandKitID, userID string, requireAdmin bool) (*BrandKitResponse, error) {
+	row := database.DB.QueryRow(
+		context.Background(),
+		`SELECT bk.id, bk.name, bk.user_id, bk.organization_id, bk.created_at, bk.updated_at, `+brandKitColumns+`
+         FROM brand_kits bk
+         WHERE bk.id = $1 AND `+brandKitAccess("$2", requireAdmin),
+		brandKitID,
+		userID,
+	)
+	return scanBrandKit(row)
+}
+
+func scanBrandKit(row pgx.Row) (*BrandKitResponse, error) {
+	brandKit := &BrandKitResponse{}
+	dest := append([]interface{}{
+		&brandKit.ID, &brandKit.Name, &brandKit.UserID, &brandKit.OrganizationID, &brandKit.CreatedAt, &brandKit.UpdatedAt,
+	}, brandKitDest(&brandKit.Brand)...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return brandKit, nil
+}
+
+// rerenderBrandKitSignatures renders and lints every signature that uses
+// the brand kit with its current styling
+func rerenderBrandKitSignatures(brandKitID string) ([]RerenderedSignature, error) {
+	return rerenderSignatures("s.brand_kit_id = $1", brandKitID)
+}
+
+// rerenderSignatures renders and lints every signature matching the
+// condition
+func rerenderSignatures(condition string, args ...interface{}) ([]RerenderedSignature, error) {
+	rows, err := database.DB.Query(
+		context.Background(),
+		signatureRecordQuery+" WHERE "+condition,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []*signatureRecord
+	for rows.Next() {
+		signature, err := scanSignatureRecord(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		signatures = append(signatures, signature)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rerendered := make([]RerenderedSignature, 0, len(signatures))
+	for _, signature := range signatures {
+		results, err := signature.lint(templates.Names())
+		if err != nil {
+			return nil, err
+		}
+		rerendered = append(rerendered, RerenderedSignature{SignatureID: signature.ID, Lint: results})
+	}
+	return rerendered, nil
+}
+
+// paletteOrEmpty stores a missing palette as an empty JSON object
+func paletteOrEmpty(palette map[string]string) map[string]string {
+	if palette == nil {
+		return map[string]string{}
+	}
+	return palette
+}
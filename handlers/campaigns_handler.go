@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/templates"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Campaign audiences
+const (
+	// AudienceOrganization targets every signature in the organization
+	AudienceOrganization = "organization"
+	// AudienceDepartment targets organization signatures whose department
+	// field matches
+	AudienceDepartment = "department"
+	// AudienceSignatures targets a list of signatures
+	AudienceSignatures = "signatures"
+)
+
+type CampaignRequest struct {
+	Name string `json:"name"`
+	// BannerAssetID fills in the image from an uploaded banner asset;
+	// otherwise image_url, image_width and image_height are required
+	BannerAssetID  string    `json:"banner_asset_id"`
+	ImageURL       string    `json:"image_url"`
+	ImageWidth     int       `json:"image_width"`
+	ImageHeight    int       `json:"image_height"`
+	AltText        string    `json:"alt_text"`
+	LinkURL        string    `json:"link_url"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Audience       string    `json:"audience"`
+	OrganizationID string    `json:"organization_id"`
+	Department     string    `json:"department"`
+	SignatureIDs   []string  `json:"signature_ids"`
+}
+
+type CampaignResponse struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	ImageURL       string    `json:"image_url"`
+	ImageWidth     int       `json:"image_width"`
+	ImageHeight    int       `json:"image_height"`
+	AltText        string    `json:"alt_text"`
+	LinkURL        string    `json:"link_url"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Audience       string    `json:"audience"`
+	OrganizationID *string   `json:"organization_id"`
+	Department     string    `json:"department,omitempty"`
+	SignatureIDs   []string  `json:"signature_ids,omitempty"`
+	// Active reports whether the campaign is running now
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CampaignsListResponse struct {
+	Campaigns []CampaignResponse `json:"campaigns"`
+}
+
+// campaignColumns selects the columns scanCampaign expects
+const campaignColumns = `ca.id, ca.name, ca.image_url, ca.image_width, ca.image_height, ca.alt_text, ca.link_url,
+         ca.starts_at, ca.ends_at, ca.audience, ca.organization_id, ca.department, ca.created_at, ca.updated_at`
+
+// campaignAccess limits campaigns (aliased ca) to the personal campaigns
+// of the user bound to userParam and those of organizations they
+// administer
+func campaignAccess(userParam string) string {
+	return fmt.Sprintf(`((ca.organization_id IS NULL AND ca.user_id = %[1]s) OR ca.organization_id IN (
+             SELECT organization_id FROM organization_members WHERE user_id = %[1]s AND role = '%[2]s'
+         ))`, userParam, RoleAdmin)
+}
+
+// CreateCampaign godoc
+// @Summary Create a banner campaign
+// @Description Schedules a promotional banner that is appended below every targeted signature between starts_at and ends_at, linking through a tracked redirect. The audience is a whole organization, one department of it, or a list of the user's signatures. Organization campaigns can only be created by its admins.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param request body CampaignRequest true "Campaign payload"
+// @Success 201 {object} CampaignResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/campaigns [post]
+func CreateCampaign(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(CampaignRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.validate(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if req.OrganizationID != "" && !isOrganizationAdmin(req.OrganizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can run organization campaigns"})
+	}
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create campaign"})
+	}
+	defer tx.Rollback(ctx)
+
+	var campaignID string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO campaigns (user_id, organization_id, name, image_url, image_width, image_height, alt_text, link_url, starts_at, ends_at, audience, department)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		userID,
+		nullIfEmpty(req.OrganizationID),
+		req.Name,
+		req.ImageURL,
+		req.ImageWidth,
+		req.ImageHeight,
+		req.AltText,
+		req.LinkURL,
+		req.StartsAt,
+		req.EndsAt,
+		req.Audience,
+		req.Department,
+	).Scan(&campaignID)
+	if err == nil {
+		err = setCampaignSignatures(ctx, tx, campaignID, req.SignatureIDs)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to insert campaign: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create campaign"})
+	}
+
+	campaign, err := loadCampaign(campaignID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch campaign: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch campaign"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(campaign)
+}
+
+// GetCampaigns godoc
+// @Summary List campaigns
+// @Description Lists the user's campaigns and those of organizations they administer, newest first
+// @Tags Campaigns
+// @Produce json
+// @Success 200 {object} CampaignsListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/campaigns [get]
+func GetCampaigns(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+campaignColumns+" FROM campaigns ca WHERE "+campaignAccess("$1")+" ORDER BY ca.starts_at DESC",
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch campaigns: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch campaigns"})
+	}
+	campaigns, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (CampaignResponse, error) {
+		campaign, err := scanCampaign(row)
+		if err != nil {
+			return CampaignResponse{}, err
+		}
+		return *campaign, nil
+	})
+	if err != nil {
+		log.Printf("Failed to fetch campaigns: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch campaigns"})
+	}
+
+	for i := range campaigns {
+		if err := campaigns[i].loadSignatureIDs(); err != nil {
+			log.Printf("Failed to fetch campaign signatures: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch campaigns"})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(CampaignsListResponse{Campaigns: campaigns})
+}
+
+// GetCampaign godoc
+// @Summary Get a campaign
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} CampaignResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/campaigns/{id} [get]
+func GetCampaign(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	campaign, err := loadCampaign(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Campaign not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(campaign)
+}
+
+// UpdateCampaign godoc
+// @Summary Update a campaign
+// @Description Replaces the campaign's banner, schedule and audience. Exports pick up the change immediately; banner links already sent keep working and follow the new link_url.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Param request body CampaignRequest true "Campaign payload"
+// @Success 200 {object} CampaignResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/campaigns/{id} [put]
+func UpdateCampaign(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(CampaignRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.validate(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	campaign, err := loadCampaign(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Campaign not found"})
+	}
+	if req.OrganizationID != "" && !isOrganizationAdmin(req.OrganizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can run organization campaigns"})
+	}
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update campaign"})
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE campaigns SET organization_id = $2, name = $3, image_url = $4, image_width = $5, image_height = $6,
+             alt_text = $7, link_url = $8, starts_at = $9, ends_at = $10, audience = $11, department = $12, updated_at = NOW()
+         WHERE id = $1`,
+		campaign.ID,
+		nullIfEmpty(req.OrganizationID),
+		req.Name,
+		req.ImageURL,
+		req.ImageWidth,
+		req.ImageHeight,
+		req.AltText,
+		req.LinkURL,
+		req.StartsAt,
+		req.EndsAt,
+		req.Audience,
+		req.Department,
+	)
+	if err == nil {
+		// Already issued banner links redirect to the new destination
+		_, err = tx.Exec(ctx, "UPDATE links SET url = $1 WHERE campaign_id = $2", req.LinkURL, campaign.ID)
+	}
+	if err == nil {
+		err = setCampaignSignatures(ctx, tx, campaign.ID, req.SignatureIDs)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to update campaign: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update campaign"})
+	}
+
+	campaign, err = loadCampaign(campaign.ID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch campaign: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch campaign"})
+	}
+	return c.Status(fiber.StatusOK).JSON(campaign)
+}
+
+// DeleteCampaign godoc
+// @Summary Delete a campaign
+// @Description Deletes a campaign. Banner links already sent keep redirecting to its last destination.
+// @Tags Campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/campaigns/{id} [delete]
+func DeleteCampaign(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM campaigns ca WHERE ca.id = $1 AND "+campaignAccess("$2"),
+		c.Params("id"),
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to delete campaign: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete campaign"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Campaign not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Campaign deleted successfully"})
+}
+
+// validate checks the request and fills in the image from a banner asset
+func (r *CampaignRequest) validate(userID string) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if !isHTTPURL(r.LinkURL) {
+		return errors.New("link_url must be an http or https URL")
+	}
+	if r.StartsAt.IsZero() || !r.EndsAt.After(r.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	if r.BannerAssetID != "" {
+		asset, err := scanAsset(database.DB.QueryRow(
+			context.Background(),
+			"SELECT "+assetColumns+" FROM assets WHERE id = $1 AND user_id = $2 AND kind = 'banner'",
+			r.BannerAssetID,
+			userID,
+		))
+		if err != nil {
+			return errors.New("banner asset not found")
+		}
+		r.ImageURL, r.ImageWidth, r.ImageHeight = asset.URL, asset.DisplayWidth, asset.DisplayHeight
+	}
+	if !isHTTPURL(r.ImageURL) {
+		return errors.New("image_url must be an http or https URL")
+	}
+	if r.ImageWidth <= 0 || r.ImageHeight <= 0 {
+		return errors.New("image_width and image_height are required")
+	}
+
+	switch r.Audience {
+	case AudienceOrganization, AudienceDepartment:
+		if r.OrganizationID == "" {
+			return errors.New("organization_id is required for organization and department audiences")
+		}
+		if r.Audience == AudienceDepartment && strings.TrimSpace(r.Department) == "" {
+			return errors.New("department is required for the department audience")
+		}
+		r.SignatureIDs = nil
+	case AudienceSignatures:
+		if len(r.SignatureIDs) == 0 {
+			return errors.New("signature_ids is required for the signatures audience")
+		}
+		var owned int
+		err := database.DB.QueryRow(
+			context.Background(),
+			"SELECT COUNT(*) FROM signatures WHERE id::text = ANY($1) AND user_id = $2",
+			r.SignatureIDs,
+			userID,
+		).Scan(&owned)
+		if err != nil || owned != len(uniqueStrings(r.SignatureIDs)) {
+			return errors.New("signature_ids must be your signatures")
+		}
+		r.OrganizationID = ""
+	default:
+		return fmt.Errorf("audience must be %s, %s or %s", AudienceOrganization, AudienceDepartment, AudienceSignatures)
+	}
+	if r.Audience != AudienceDepartment {
+		r.Department = ""
+	}
+	return nil
+}
+
+// setCampaignSignatures replaces the signatures a campaign targets
+func setCampaignSignatures(ctx context.Context, tx pgx.Tx, campaignID string, signatureIDs []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM campaign_signatures WHERE campaign_id = $1", campaignID); err != nil {
+		return err
+	}
+	for _, signatureID := range uniqueStrings(signatureIDs) {
+		_, err := tx.Exec(
+			ctx,
+			"INSERT INTO campaign_signatures (campaign_id, signature_id) VALUES ($1, $2)",
+			campaignID,
+			signatureID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadCampaign(campaignID, userID string) (*CampaignResponse, error) {
+	if _, err := uuid.Parse(campaignID); err != nil {
+		return nil, err
+	}
+	campaign, err := scanCampaign(database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+campaignColumns+" FROM campaigns ca WHERE ca.id = $1 AND "+campaignAccess("$2"),
+		campaignID,
+		userID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return campaign, campaign.loadSignatureIDs()
+}
+
+func scanCampaign(row pgx.Row) (*CampaignResponse, error) {
+	campaign := &CampaignResponse{}
+	err := row.Scan(
+		&campaign.ID, &campaign.Name, &campaign.ImageURL, &campaign.ImageWidth, &campaign.ImageHeight,
+		&campaign.AltText, &campaign.LinkURL, &campaign.StartsAt, &campaign.EndsAt, &campaign.Audience,
+		&campaign.OrganizationID, &campaign.Department, &campaign.CreatedAt, &campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	campaign.Active = !now.Before(campaign.StartsAt) && now.Before(campaign.EndsAt)
+	return campaign, nil
+}
+
+// loadSignatureIDs fills in the targeted signatures of a signatures
+// audience campaign
+func (campaign *CampaignResponse) loadSignatureIDs() error {
+	if campaign.Audience != AudienceSignatures {
+		return nil
+	}
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT signature_id::text FROM campaign_signatures WHERE campaign_id = $1 ORDER BY signature_id",
+		campaign.ID,
+	)
+	if err != nil {
+		return err
+	}
+	campaign.SignatureIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	return err
+}
+
+// campaignBanner returns the banner of the campaign currently targeting
+// the signature, linked through a tracked redirect, or nil. When several
+// campaigns overlap the one that started last wins.
+func campaignBanner(signature *signatureRecord) (*templates.Banner, error) {
+	ctx := context.Background()
+
+	var campaignID string
+	var banner templates.Banner
+	err := database.DB.QueryRow(
+		ctx,
+		`SELECT ca.id, ca.image_url, ca.image_width, ca.image_height, ca.alt_text, ca.link_url
+         FROM signatures s
+         JOIN campaigns ca ON ca.starts_at <= NOW() AND ca.ends_at > NOW() AND (
+             (ca.audience = $2 AND ca.organization_id = s.organization_id)
+             OR (ca.audience = $3 AND ca.organization_id = s.organization_id
+                 AND LOWER(ca.department) = LOWER(s.template_data->>'department'))
+             OR (ca.audience = $4 AND EXISTS (
+                 SELECT 1 FROM campaign_signatures cs WHERE cs.campaign_id = ca.id AND cs.signature_id = s.id
+             ))
+         )
+         WHERE s.id = $1
+         ORDER BY ca.starts_at DESC
+         LIMIT 1`,
+		signature.ID,
+		AudienceOrganization,
+		AudienceDepartment,
+		AudienceSignatures,
+	).Scan(&campaignID, &banner.ImageURL, &banner.Width, &banner.Height, &banner.Alt, &banner.LinkURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Each signature gets its own link so clicks can be attributed to it
+	var code string
+	err = insertLink(func() error {
+		return database.DB.QueryRow(
+			ctx,
+			`INSERT INTO links (signature_id, url, campaign_id) VALUES ($1, $2, $3)
+             ON CONFLICT (campaign_id, signature_id) WHERE campaign_id IS NOT NULL DO UPDATE SET url = EXCLUDED.url
+             RETURNING code`,
+			signature.ID,
+			banner.LinkURL,
+			campaignID,
+		).Scan(&code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	banner.LinkURL = trackedLinkURL(code)
+
+	return &banner, nil
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// nullIfEmpty stores empty optional IDs as NULL
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
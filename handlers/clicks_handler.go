@@ -1,26 +1,43 @@
 package handlers
 
 import (
-	"context"
-	"email-signature-backend/database"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
 )
 
+// ClickRequest reports a click made by the caller of /api/track. The IP
+// address is the request's, so send it from the visitor's browser.
 type ClickRequest struct {
-	LinkID    string `json:"link_id"`
-	IPAddress string `json:"ip_address"`
+	LinkID string `json:"link_id"`
+	// VariantID is the A/B test variant the link was shown in, if any
+	VariantID string `json:"variant_id"`
+	// SentAt is when the message holding the link was sent, in unix
+	// seconds, if known; clicks within seconds of it count as scanners
+	SentAt int64 `json:"sent_at"`
 }
 
+// clickVariant is the variant to store with a click on link $1, given the
+// variant ID in $3: the link's own variant for variant banners, else $3 if
+// it is a variant of the link's signature
+const clickVariant = `COALESCE(
+             (SELECT variant_id FROM links WHERE id = $1),
+             (SELECT v.id FROM signature_variants v JOIN links l ON l.signature_id = v.signature_id WHERE l.id = $1 AND v.id::text = $3)
+         )`
+
 // TrackClick godoc
 // @Summary Track a click event
-// @Description Logs a click event for a specific link, including the user's IP address
+// @Description Logs a click event for a specific link the way /r/{code} records one: classified as human, bot or scanner traffic, with the requester's IP address, user agent, referrer, location, device and A/B test variant. The IP address is taken from the connection or the trusted proxy header and stored as the privacy mode in CLICK_IP_MODE requires. Requests with Do-Not-Track or Global Privacy Control are counted without visitor details. Clicks on expired links are not recorded.
 // @Tags Clicks
 // @Accept json
 // @Produce json
 // @Param request body ClickRequest true "Click tracking payload"
 // @Success 200 {object} map[string]string "Click tracked successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 404 {object} map[string]interface{} "Link not found"
+// @Failure 410 {object} map[string]interface{} "Link has expired"
 // @Failure 500 {object} map[string]interface{} "Failed to track click"
 // @Router /api/track [post]
 func TrackClick(c *fiber.Ctx) error {
@@ -32,16 +49,35 @@ func TrackClick(c *fiber.Ctx) error {
 		})
 	}
 
-	// Insert click into database
-	clickID := uuid.New()
-	_, err := database.DB.Exec(
-		context.Background(),
-		"INSERT INTO clicks (id, link_id, ip_address) VALUES ($1, $2, $3)",
-		clickID,
-		req.LinkID,
-		req.IPAddress,
-	)
+	linkID, err := uuid.Parse(req.LinkID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid link_id",
+		})
+	}
+	link, err := loadTrackedLink("l.id = $1", linkID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Link not found",
+		})
+	}
 	if err != nil {
+		log.Printf("Failed to fetch link: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to track click",
+		})
+	}
+
+	// Expired links record no clicks when followed, nor when reported
+	if link.Expired {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error": "Link has expired",
+		})
+	}
+
+	// Record the click like a followed link
+	if _, err := recordClick(c, link, req.VariantID, sentAt(req.SentAt)); err != nil {
+		log.Printf("Failed to record click: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to track click",
 		})
@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/deploy"
+	"email-signature-backend/templates"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+type DeployRequest struct {
+	// Provider is gmail or exchange, whichever are configured on the server
+	Provider string `json:"provider"`
+	// Mailbox defaults to the signature's employee email
+	Mailbox string `json:"mailbox"`
+	// Template defaults to the signature's template
+	Template string `json:"template"`
+}
+
+type OrganizationDeployRequest struct {
+	Provider string `json:"provider"`
+	// Template defaults to each signature's template
+	Template string `json:"template"`
+}
+
+type DeploymentResponse struct {
+	ID            string     `json:"id"`
+	SignatureID   *string    `json:"signature_id"`
+	Mailbox       string     `json:"mailbox"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	DeployedAt    *time.Time `json:"deployed_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type DeployJobResponse struct {
+	ID             string               `json:"id"`
+	OrganizationID *string              `json:"organization_id"`
+	Provider       string               `json:"provider"`
+	Template       string               `json:"template"`
+	Status         string               `json:"status"`
+	Total          int                  `json:"total"`
+	Succeeded      int                  `json:"succeeded"`
+	Failed         int                  `json:"failed"`
+	CreatedAt      time.Time            `json:"created_at"`
+	FinishedAt     *time.Time           `json:"finished_at"`
+	Deployments    []DeploymentResponse `json:"deployments"`
+}
+
+// pendingDeployment is a rendered signature waiting to be queued
+type pendingDeployment struct {
+	SignatureID string
+	Mailbox     string
+	HTML        string
+}
+
+// DeploySignature godoc
+// @Summary Deploy a signature to a mailbox
+// @Description Queues a job that installs the exported signature in the employee's mailbox through the Gmail sendAs API or Exchange Online. Transient failures are retried with backoff; poll the job for per-mailbox status.
+// @Tags Deployments
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body DeployRequest true "Deployment payload"
+// @Success 202 {object} DeployJobResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/deploy [post]
+func DeploySignature(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(DeployRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := validateDeployTarget(req.Provider, req.Template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		log.Printf("Failed to fetch signature: %v\n", err)
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	mailbox := strings.TrimSpace(req.Mailbox)
+	if mailbox == "" {
+		mailbox = signature.EmployeeEmail
+	}
+	if address, err := mail.ParseAddress(mailbox); err != nil || address.Address != mailbox {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "A valid mailbox is required"})
+	}
+
+	html, err := deployHTML(signature, req.Provider, req.Template, mailbox)
+	if err != nil {
+		log.Printf("Failed to export signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to generate HTML"})
+	}
+
+	job, err := queueDeployJob(userID, nil, req.Provider, req.Template, []pendingDeployment{
+		{SignatureID: signature.ID, Mailbox: mailbox, HTML: html},
+	})
+	if err != nil {
+		log.Printf("Failed to queue deploy job: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to queue deployment"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// DeployOrganization godoc
+// @Summary Deploy every signature in an organization
+// @Description Queues a job deploying each active signature in the organization to its employee's mailbox. Signatures without an employee email are skipped. Only admins can deploy.
+// @Tags Deployments
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body OrganizationDeployRequest true "Deployment payload"
+// @Success 202 {object} DeployJobResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/deploy [post]
+func DeployOrganization(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req := new(OrganizationDeployRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := validateDeployTarget(req.Provider, req.Template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can deploy signatures"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		signatureRecordQuery+" WHERE s.organization_id = $1 AND s.archived_at IS NULL AND s.employee_email IS NOT NULL",
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch organization signatures: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch signatures"})
+	}
+	signatures, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*signatureRecord, error) {
+		return scanSignatureRecord(row)
+	})
+	if err != nil {
+		log.Printf("Failed to fetch organization signatures: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch signatures"})
+	}
+
+	var deployments []pendingDeployment
+	for _, signature := range signatures {
+		html, err := deployHTML(signature, req.Provider, req.Template, signature.EmployeeEmail)
+		if err != nil {
+			log.Printf("Failed to export signature %s: %v\n", signature.ID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to generate HTML"})
+		}
+		deployments = append(deployments, pendingDeployment{SignatureID: signature.ID, Mailbox: signature.EmployeeEmail, HTML: html})
+	}
+	if len(deployments) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "The organization has no signatures with an employee email"})
+	}
+
+	job, err := queueDeployJob(userID, &organizationID, req.Provider, req.Template, deployments)
+	if err != nil {
+		log.Printf("Failed to queue deploy job: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to queue deployment"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// GetDeployJob godoc
+// @Summary Get a deploy job
+// @Description Returns the job with the status of each mailbox deployment. Jobs are visible to the user who started them and to admins of the job's organization.
+// @Tags Deployments
+// @Produce json
+// @Param id path string true "Deploy job ID"
+// @Success 200 {object} DeployJobResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/deploy-jobs/{id} [get]
+func GetDeployJob(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	job, err := loadDeployJob(c.Params("id"), userID)
+	if err != nil {
+		log.Printf("Failed to fetch deploy job: %v\n", err)
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Deploy job not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(job)
+}
+
+// RetryDeployJob godoc
+// @Summary Retry failed deployments
+// @Description Queues the job's failed deployments again with a fresh set of attempts
+// @Tags Deployments
+// @Produce json
+// @Param id path string true "Deploy job ID"
+// @Success 202 {object} DeployJobResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/deploy-jobs/{id}/retry [post]
+func RetryDeployJob(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := context.Background()
+
+	job, err := loadDeployJob(c.Params("id"), userID)
+	if err != nil {
+		log.Printf("Failed to fetch deploy job: %v\n", err)
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Deploy job not found"})
+	}
+
+	result, err := database.DB.Exec(
+		ctx,
+		`UPDATE deployments SET status = $1, attempts = 0, last_error = '', next_attempt_at = NOW(), updated_at = NOW()
+         WHERE job_id = $2 AND status = $3`,
+		deploy.StatusPending,
+		job.ID,
+		deploy.StatusFailed,
+	)
+	if err != nil {
+		log.Printf("Failed to retry deployments: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to retry deployments"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "The job has no failed deployments"})
+	}
+
+	if err := deploy.RefreshJob(ctx, job.ID); err != nil {
+		log.Printf("Failed to update deploy job: %v\n", err)
+	}
+	deploy.Kick()
+
+	job, err = loadDeployJob(job.ID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch deploy job: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch deploy job"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// validateDeployTarget checks the provider is configured and the template,
+// if any, exists
+func validateDeployTarget(provider, template string) error {
+	if _, ok := deploy.Deployers[provider]; !ok {
+		providers := deploy.Providers()
+		if len(providers) == 0 {
+			return fmt.Errorf("no deployment providers are configured")
+		}
+		return fmt.Errorf("provider must be one of: %s", strings.Join(providers, ", "))
+	}
+	if template != "" && !templates.Exists(template) {
+		return fmt.Errorf("unknown template")
+	}
+	return nil
+}
+
+// deployHTML exports the signature for the mailbox with the requested
+// template, else its own. Signatures with A/B test variants get the
+// mailbox's variant, and content rules see the provider as the format.
+// Compatibility problems are logged, not fatal.
+func deployHTML(signature *signatureRecord, provider, template, mailbox string) (string, error) {
+	variant, err := assignVariant(signature.ID, mailbox)
+	if err != nil {
+		return "", err
+	}
+	if template == "" {
+		template = signature.Template
+		if variant != nil && variant.Template != "" {
+			template = variant.Template
+		}
+	}
+	result, err := exportSignature(signature, resolveTemplate(template), variant, contentContext(signature, provider), false)
+	if err != nil {
+		return "", err
+	}
+	lintExport(signature.ID, result.HTML)
+	return result.HTML, nil
+}
+
+// queueDeployJob stores a job with its deployments and wakes the worker
+func queueDeployJob(userID string, organizationID *string, provider, template string, deployments []pendingDeployment) (*DeployJobResponse, error) {
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var jobID string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO deploy_jobs (user_id, organization_id, provider, template, status, total)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		userID,
+		organizationID,
+		provider,
+		template,
+		deploy.StatusRunning,
+		len(deployments),
+	).Scan(&jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range deployments {
+		_, err := tx.Exec(
+			ctx,
+			"INSERT INTO deployments (job_id, signature_id, mailbox, html) VALUES ($1, $2, $3, $4)",
+			jobID,
+			d.SignatureID,
+			d.Mailbox,
+			d.HTML,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	deploy.Kick()
+
+	return loadDeployJob(jobID, userID)
+}
+
+// loadDeployJob fetches a job started by the user or belonging to an
+// organization they administer, with its deployments
+func loadDeployJob(jobID, userID string) (*DeployJobResponse, error) {
+	ctx := context.Background()
+	job := &DeployJobResponse{Deployments: []DeploymentResponse{}}
+	err := database.DB.QueryRow(
+		ctx,
+		`SELECT id, organization_id, provider, template, status, total, succeeded, failed, created_at, finished_at
+         FROM deploy_jobs
+         WHERE id = $1 AND (user_id = $2 OR organization_id IN (
+             SELECT organization_id FROM organization_members WHERE user_id = $2 AND role = $3
+         ))`,
+		jobID,
+		userID,
+		RoleAdmin,
+	).Scan(&job.ID, &job.OrganizationID, &job.Provider, &job.Template, &job.Status, &job.Total, &job.Succeeded, &job.Failed, &job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.DB.Query(
+		ctx,
+		`SELECT id, signature_id, mailbox, status, attempts, last_error, next_attempt_at, deployed_at, updated_at
+         FROM deployments WHERE job_id = $1 ORDER BY mailbox`,
+		job.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (DeploymentResponse, error) {
+		var d DeploymentResponse
+		var nextAttemptAt time.Time
+		err := row.Scan(&d.ID, &d.SignatureID, &d.Mailbox, &d.Status, &d.Attempts, &d.LastError, &nextAttemptAt, &d.DeployedAt, &d.UpdatedAt)
+		// Only queued retries have a meaningful next attempt
+		if d.Status == deploy.StatusPending && d.Attempts > 0 {
+			d.NextAttemptAt = &nextAttemptAt
+		}
+		return d, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	job.Deployments = append(job.Deployments, deployments...)
+	return job, nil
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/directory"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DirectoryRequest struct {
+	Name         string                 `json:"name"`
+	URL          string                 `json:"url"`
+	BindDN       string                 `json:"bind_dn"`
+	BindPassword string                 `json:"bind_password"`
+	BaseDN       string                 `json:"base_dn"`
+	Filter       string                 `json:"filter"`
+	StartTLS     bool                   `json:"start_tls"`
+	Attributes   directory.AttributeMap `json:"attributes"`
+	BrandKitID   string                 `json:"brand_kit_id"`
+	// IntervalMinutes defaults to 60; 0 turns scheduled syncs off
+	IntervalMinutes *int `json:"interval_minutes"`
+}
+
+type DirectoriesListResponse struct {
+	Directories []directory.Config `json:"directories"`
+}
+
+type DirectorySyncRunResponse struct {
+	ID         string             `json:"id"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Users      int                `json:"users"`
+	Created    int                `json:"created"`
+	Updated    int                `json:"updated"`
+	Archived   int                `json:"archived"`
+	Restored   int                `json:"restored"`
+	Unchanged  int                `json:"unchanged"`
+	Changes    []directory.Change `json:"changes"`
+	Skipped    []string           `json:"skipped"`
+}
+
+type DirectorySyncRunsListResponse struct {
+	Runs []DirectorySyncRunResponse `json:"runs"`
+}
+
+// CreateDirectory godoc
+// @Summary Connect an LDAP directory
+// @Description Registers an LDAP directory whose users are synced into signatures keyed by employee email. attributes maps template_data fields to LDAP attributes and defaults to mail, cn, title, o and telephoneNumber. The directory is synced every interval_minutes (default 60, 0 for manual syncs only). bind_password is stored encrypted with SECRET_ENCRYPTION_KEY and never returned.
+// @Tags Directories
+// @Accept json
+// @Produce json
+// @Param request body DirectoryRequest true "Directory payload"
+// @Success 201 {object} directory.Config
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/directories [post]
+func CreateDirectory(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(DirectoryRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" || req.BaseDN == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "URL must be an ldap:// or ldaps:// address"})
+	}
+
+	if len(req.Attributes) == 0 {
+		req.Attributes = directory.DefaultAttributes
+	}
+	if err := req.Attributes.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	interval := 60
+	if req.IntervalMinutes != nil {
+		interval = *req.IntervalMinutes
+	}
+	if interval < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "interval_minutes cannot be negative"})
+	}
+
+	var brandKitID interface{}
+	if req.BrandKitID != "" {
+		brandKit, err := loadBrandKit(req.BrandKitID, userID, false)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Brand kit not found"})
+		}
+		brandKitID = brandKit.ID
+	}
+
+	// The bind password is only ever stored encrypted
+	bindPassword, err := directory.EncryptPassword(req.BindPassword)
+	if errors.Is(err, directory.ErrNoEncryptionKey) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Bind passwords cannot be stored until SECRET_ENCRYPTION_KEY is configured"})
+	}
+	if err != nil {
+		log.Printf("Failed to encrypt bind password: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create directory"})
+	}
+
+	row := database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO directories (user_id, name, url, bind_dn, bind_password, base_dn, filter, start_tls, attributes, brand_kit_id, interval_minutes)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+         RETURNING `+directory.Columns,
+		userID,
+		req.Name,
+		req.URL,
+		req.BindDN,
+		bindPassword,
+		req.BaseDN,
+		req.Filter,
+		req.StartTLS,
+		req.Attributes,
+		brandKitID,
+		interval,
+	)
+	config, err := directory.Scan(row)
+	if err != nil {
+		log.Printf("Failed to insert directory: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create directory"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(config)
+}
+
+// GetDirectories godoc
+// @Summary List connected directories
+// @Tags Directories
+// @Produce json
+// @Success 200 {object} DirectoriesListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/directories [get]
+func GetDirectories(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+directory.Columns+" FROM directories WHERE user_id = $1 ORDER BY name",
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch directories: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch directories"})
+	}
+	defer rows.Close()
+
+	list := []directory.Config{}
+	for rows.Next() {
+		config, err := directory.Scan(rows)
+		if err != nil {
+			log.Printf("Failed to parse directory: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse directories"})
+		}
+		list = append(list, *config)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DirectoriesListResponse{Directories: list})
+}
+
+// DeleteDirectory godoc
+// @Summary Disconnect a directory
+// @Description Stops syncing the directory. Its signatures are kept but no longer archived when employees leave.
+// @Tags Directories
+// @Produce json
+// @Param id path string true "Directory ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/directories/{id} [delete]
+func DeleteDirectory(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM directories WHERE id = $1 AND user_id = $2",
+		c.Params("id"),
+		userID,
+	)
+	if err != nil || result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Directory not found or unauthorized"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Directory deleted successfully"})
+}
+
+// SyncDirectory godoc
+// @Summary Sync a directory now
+// @Description Pulls users from the directory and creates, updates, archives or restores signatures to match, returning a report of every change. Signatures of employees no longer in the directory are archived. With dry_run=true nothing is saved.
+// @Tags Directories
+// @Produce json
+// @Param id path string true "Directory ID"
+// @Param dry_run query bool false "Report the changes without saving them"
+// @Success 200 {object} directory.Report
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/directories/{id}/sync [post]
+func SyncDirectory(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	config, err := loadDirectory(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Directory not found"})
+	}
+
+	report, err := directory.Run(context.Background(), config, c.QueryBool("dry_run"))
+	if err != nil {
+		log.Printf("Directory sync of %s failed: %v\n", config.Name, err)
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{Error: "Directory sync failed: " + err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
+// GetDirectorySyncRuns godoc
+// @Summary List a directory's sync history
+// @Description Returns the 50 most recent syncs with what each changed
+// @Tags Directories
+// @Produce json
+// @Param id path string true "Directory ID"
+// @Success 200 {object} DirectorySyncRunsListResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/directories/{id}/runs [get]
+func GetDirectorySyncRuns(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := loadDirectory(c.Params("id"), userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Directory not found"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		`SELECT id, started_at, finished_at, status, error, users, created, updated, archived, restored, unchanged, changes, skipped
+         FROM directory_sync_runs WHERE directory_id = $1 ORDER BY started_at DESC LIMIT 50`,
+		c.Params("id"),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch directory sync runs: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch sync runs"})
+	}
+	defer rows.Close()
+
+	list := []DirectorySyncRunResponse{}
+	for rows.Next() {
+		var run DirectorySyncRunResponse
+		err := rows.Scan(
+			&run.ID, &run.StartedAt, &run.FinishedAt, &run.Status, &run.Error, &run.Users, &run.Created,
+			&run.Updated, &run.Archived, &run.Restored, &run.Unchanged, &run.Changes, &run.Skipped,
+		)
+		if err != nil {
+			log.Printf("Failed to parse directory sync run: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse sync runs"})
+		}
+		list = append(list, run)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DirectorySyncRunsListResponse{Runs: list})
+}
+
+// loadDirectory fetches a directory owned by the user
+func loadDirectory(directoryID, userID string) (*directory.Config, error) {
+	return directory.Scan(database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+directory.Columns+" FROM directories WHERE id = $1 AND user_id = $2",
+		directoryID,
+		userID,
+	))
+}
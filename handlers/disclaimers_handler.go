@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/deploy"
+	"email-signature-backend/templates"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxDisclaimerLength is the longest disclaimer text accepted
+const MaxDisclaimerLength = 5000
+
+type DisclaimerRequest struct {
+	Name string `json:"name"`
+	// Text is plain text; line breaks are kept when rendered
+	Text string `json:"text"`
+}
+
+type DisclaimerResponse struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type DisclaimersListResponse struct {
+	Disclaimers []DisclaimerResponse `json:"disclaimers"`
+}
+
+type ContentRulesRequest struct {
+	Rules []templates.Rule `json:"rules"`
+}
+
+// errUnknownDisclaimer is returned when a content rule names a disclaimer
+// the user cannot use
+var errUnknownDisclaimer = errors.New("disclaimer not found")
+
+// disclaimerColumns selects the columns scanDisclaimer expects
+const disclaimerColumns = "d.id, d.organization_id, d.name, d.text, d.created_at, d.updated_at"
+
+// CreateDisclaimer godoc
+// @Summary Create a disclaimer
+// @Description Creates reusable disclaimer text for an organization, which signature content rules can show in some contexts only. Only admins can manage disclaimers.
+// @Tags Disclaimers
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body DisclaimerRequest true "Disclaimer payload"
+// @Success 201 {object} DisclaimerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/disclaimers [post]
+func CreateDisclaimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req, err := parseDisclaimerRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage disclaimers"})
+	}
+
+	row := database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO disclaimers AS d (organization_id, name, text) VALUES ($1, $2, $3)
+         RETURNING `+disclaimerColumns,
+		organizationID,
+		req.Name,
+		req.Text,
+	)
+	disclaimer, err := scanDisclaimer(row)
+	if err != nil {
+		log.Printf("Failed to insert disclaimer: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create disclaimer"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(disclaimer)
+}
+
+// GetDisclaimers godoc
+// @Summary List an organization's disclaimers
+// @Tags Disclaimers
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} DisclaimersListResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/disclaimers [get]
+func GetDisclaimers(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	if !isOrganizationMember(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "You are not a member of this organization"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+disclaimerColumns+" FROM disclaimers d WHERE d.organization_id = $1 ORDER BY d.name",
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch disclaimers: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch disclaimers"})
+	}
+	defer rows.Close()
+
+	disclaimers := []DisclaimerResponse{}
+	for rows.Next() {
+		disclaimer, err := scanDisclaimer(rows)
+		if err != nil {
+			log.Printf("Failed to scan disclaimer: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch disclaimers"})
+		}
+		disclaimers = append(disclaimers, *disclaimer)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DisclaimersListResponse{Disclaimers: disclaimers})
+}
+
+// GetDisclaimer godoc
+// @Summary Get a disclaimer
+// @Tags Disclaimers
+// @Produce json
+// @Param id path string true "Disclaimer ID"
+// @Success 200 {object} DisclaimerResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/disclaimers/{id} [get]
+func GetDisclaimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	disclaimer, err := loadDisclaimer(c.Params("id"))
+	if err != nil || !isOrganizationMember(disclaimer.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Disclaimer not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(disclaimer)
+}
+
+// UpdateDisclaimer godoc
+// @Summary Update a disclaimer
+// @Description Replaces the disclaimer's text. Signatures showing it pick up the new text on their next export.
+// @Tags Disclaimers
+// @Accept json
+// @Produce json
+// @Param id path string true "Disclaimer ID"
+// @Param request body DisclaimerRequest true "Disclaimer payload"
+// @Success 200 {object} DisclaimerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/disclaimers/{id} [put]
+func UpdateDisclaimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req, err := parseDisclaimerRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	disclaimer, err := loadDisclaimer(c.Params("id"))
+	if err != nil || !isOrganizationAdmin(disclaimer.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Disclaimer not found or unauthorized"})
+	}
+
+	row := database.DB.QueryRow(
+		context.Background(),
+		`UPDATE disclaimers AS d SET name = $1, text = $2, updated_at = NOW() WHERE d.id = $3
+         RETURNING `+disclaimerColumns,
+		req.Name,
+		req.Text,
+		disclaimer.ID,
+	)
+	disclaimer, err = scanDisclaimer(row)
+	if err != nil {
+		log.Printf("Failed to update disclaimer: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update disclaimer"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(disclaimer)
+}
+
+// DeleteDisclaimer godoc
+// @Summary Delete a disclaimer
+// @Description Deletes a disclaimer. Content rules that show it are skipped from then on.
+// @Tags Disclaimers
+// @Produce json
+// @Param id path string true "Disclaimer ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/disclaimers/{id} [delete]
+func DeleteDisclaimer(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	disclaimer, err := loadDisclaimer(c.Params("id"))
+	if err != nil || !isOrganizationAdmin(disclaimer.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Disclaimer not found or unauthorized"})
+	}
+
+	_, err = database.DB.Exec(context.Background(), "DELETE FROM disclaimers WHERE id = $1", disclaimer.ID)
+	if err != nil {
+		log.Printf("Failed to delete disclaimer: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete disclaimer"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Disclaimer deleted successfully"})
+}
+
+// SetSignatureContentRules godoc
+// @Summary Set a signature's conditional footer content
+// @Description Replaces the rules choosing which disclaimers or text appear below the signature. Each rule shows a disclaimer of one of your organizations, or inline text, when its condition holds: any of the listed locales, departments, regions, formats (html, preview, gmail, exchange) and audiences (internal, external). Empty lists match everything. Signatures based on a master template also show the master's rules first. The response includes a compatibility lint report for every template.
+// @Tags Signatures
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body ContentRulesRequest true "Content rules"
+// @Success 200 {object} map[string]interface{} "Content rules updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/content-rules [put]
+func SetSignatureContentRules(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(ContentRulesRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := validateContentRules(req.Rules, memberDisclaimers, userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"UPDATE signatures SET content_rules = $1 WHERE id = $2 AND user_id = $3",
+		rulesOrEmpty(req.Rules),
+		c.Params("id"),
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to set content rules: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to set content rules"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found or unauthorized"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(withLint(fiber.Map{
+		"message": "Content rules updated successfully",
+	}, c.Params("id")))
+}
+
+// Conditions on disclaimers (aliased d) content rules may use, given $2
+const (
+	// memberDisclaimers are those of the organizations user $2 belongs to
+	memberDisclaimers = "d.organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = $2)"
+	// organizationDisclaimers are those of organization $2
+	organizationDisclaimers = "d.organization_id = $2"
+)
+
+// validateContentRules checks the rules and that every disclaimer they
+// show meets the access condition
+func validateContentRules(rules []templates.Rule, access string, arg string) error {
+	if err := templates.ValidateRules(rules, contentFormats()); err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, rule := range rules {
+		if rule.DisclaimerID != "" {
+			ids = append(ids, rule.DisclaimerID)
+		}
+	}
+	ids = uniqueStrings(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var found int
+	err := database.DB.QueryRow(
+		context.Background(),
+		"SELECT COUNT(*) FROM disclaimers d WHERE d.id::text = ANY($1) AND "+access,
+		ids,
+		arg,
+	).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found != len(ids) {
+		return errUnknownDisclaimer
+	}
+	return nil
+}
+
+// contentFormats are the formats content rules can target
+func contentFormats() []string {
+	return []string{templates.FormatHTML, templates.FormatPreview, deploy.ProviderGmail, deploy.ProviderExchange}
+}
+
+// contentContext is the context a signature renders in by default: its own
+// locale, department and region, for external mail
+func contentContext(signature *signatureRecord, format string) templates.Context {
+	department, _ := signature.TemplateData["department"].(string)
+	region, _ := signature.TemplateData["region"].(string)
+	return templates.Context{
+		Locale:     signature.locale(),
+		Department: department,
+		Region:     region,
+		Format:     format,
+		Audience:   templates.AudienceExternal,
+	}
+}
+
+// requestContentContext is contentContext with the locale, region and
+// audience the request asks for
+func requestContentContext(c *fiber.Ctx, signature *signatureRecord, format string) templates.Context {
+	ctx := contentContext(signature, format)
+	ctx.Locale = c.Query("locale", ctx.Locale)
+	ctx.Region = c.Query("region", ctx.Region)
+	if c.Query("audience") == templates.AudienceInternal {
+		ctx.Audience = templates.AudienceInternal
+	}
+	return ctx
+}
+
+// contentFooter renders the footer blocks of the signature's rules that
+// apply in the context, or nothing if none do
+func contentFooter(signature *signatureRecord, ctx templates.Context) (string, error) {
+	rules := templates.SelectRules(signature.ContentRules, ctx)
+	if len(rules) == 0 {
+		return "", nil
+	}
+
+	var ids []string
+	for _, rule := range rules {
+		if rule.DisclaimerID != "" {
+			ids = append(ids, rule.DisclaimerID)
+		}
+	}
+	texts := map[string]string{}
+	if len(ids) > 0 {
+		rows, err := database.DB.Query(
+			context.Background(),
+			"SELECT id, text FROM disclaimers WHERE id::text = ANY($1)",
+			uniqueStrings(ids),
+		)
+		if err != nil {
+			return "", err
+		}
+		for rows.Next() {
+			var id, text string
+			if err := rows.Scan(&id, &text); err != nil {
+				rows.Close()
+				return "", err
+			}
+			texts[id] = text
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+	}
+
+	var blocks []string
+	for _, rule := range rules {
+		text := rule.Text
+		if rule.DisclaimerID != "" {
+			// Deleted disclaimers are skipped
+			text = texts[rule.DisclaimerID]
+		}
+		if strings.TrimSpace(text) != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return templates.RenderFooter(blocks)
+}
+
+// parseDisclaimerRequest parses and validates a disclaimer payload
+func parseDisclaimerRequest(c *fiber.Ctx) (*DisclaimerRequest, error) {
+	req := new(DisclaimerRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return nil, errors.New("Invalid request payload")
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		return nil, errors.New("text is required")
+	}
+	if len(req.Text) > MaxDisclaimerLength {
+		return nil, errors.New("text is too long")
+	}
+	return req, nil
+}
+
+func loadDisclaimer(disclaimerID string) (*DisclaimerResponse, error) {
+	row := database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+disclaimerColumns+" FROM disclaimers d WHERE d.id = $1",
+		disclaimerID,
+	)
+	return scanDisclaimer(row)
+}
+
+func scanDisclaimer(row pgx.Row) (*DisclaimerResponse, error) {
+	d := &DisclaimerResponse{}
+	err := row.Scan(&d.ID, &d.OrganizationID, &d.Name, &d.Text, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// rulesOrEmpty stores missing rules as an empty JSON array
+func rulesOrEmpty(rules []templates.Rule) []templates.Rule {
+	if rules == nil {
+		return []templates.Rule{}
+	}
+	return rules
+}
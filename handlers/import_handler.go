@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/roster"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// Import row actions
+const (
+	ImportCreate = "create"
+	ImportUpdate = "update"
+	ImportSkip   = "skip"
+)
+
+type ImportMappingRequest struct {
+	Name    string         `json:"name"`
+	Columns roster.Mapping `json:"columns"`
+}
+
+type ImportMappingResponse struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Columns   roster.Mapping `json:"columns"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type ImportMappingsListResponse struct {
+	Mappings []ImportMappingResponse `json:"mappings"`
+}
+
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email"`
+	Action string   `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+	// LintErrors counts the lint errors of the saved signature
+	LintErrors int `json:"lint_errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun      bool              `json:"dry_run"`
+	Mapping     roster.Mapping    `json:"mapping"`
+	TotalRows   int               `json:"total_rows"`
+	ValidRows   int               `json:"valid_rows"`
+	InvalidRows int               `json:"invalid_rows"`
+	Created     int               `json:"created"`
+	Updated     int               `json:"updated"`
+	LintErrors  int               `json:"lint_errors"`
+	Rows        []ImportRowResult `json:"rows"`
+}
+
+// CreateImportMapping godoc
+// @Summary Save a roster column mapping
+// @Description Saves a mapping from signature fields (email, name, job_title, company, phone, website and social network keys) to roster column headers. Saving under an existing name replaces that mapping.
+// @Tags Imports
+// @Accept json
+// @Produce json
+// @Param request body ImportMappingRequest true "Mapping payload"
+// @Success 201 {object} ImportMappingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/import-mappings [post]
+func CreateImportMapping(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(ImportMappingRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.Columns.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	row := database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO import_mappings (user_id, name, columns) VALUES ($1, $2, $3)
+         ON CONFLICT (user_id, name) DO UPDATE SET columns = EXCLUDED.columns, updated_at = NOW()
+         RETURNING `+importMappingColumns,
+		userID,
+		req.Name,
+		req.Columns,
+	)
+	mapping, err := scanImportMapping(row)
+	if err != nil {
+		log.Printf("Failed to save import mapping: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to save mapping"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(mapping)
+}
+
+// GetImportMappings godoc
+// @Summary List saved roster column mappings
+// @Description Retrieve the import mappings saved by the authenticated user
+// @Tags Imports
+// @Produce json
+// @Success 200 {object} ImportMappingsListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/import-mappings [get]
+func GetImportMappings(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+importMappingColumns+" FROM import_mappings WHERE user_id = $1 ORDER BY name",
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch import mappings: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch mappings"})
+	}
+	defer rows.Close()
+
+	list := []ImportMappingResponse{}
+	for rows.Next() {
+		mapping, err := scanImportMapping(rows)
+		if err != nil {
+			log.Printf("Failed to parse import mapping: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse mappings"})
+		}
+		list = append(list, *mapping)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ImportMappingsListResponse{Mappings: list})
+}
+
+// DeleteImportMapping godoc
+// @Summary Delete a saved roster column mapping
+// @Tags Imports
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/import-mappings/{id} [delete]
+func DeleteImportMapping(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM import_mappings WHERE id = $1 AND user_id = $2",
+		c.Params("id"),
+		userID,
+	)
+	if err != nil || result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Mapping not found or unauthorized"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Mapping deleted successfully"})
+}
+
+// ImportSignatures godoc
+// @Summary Bulk create signatures from an employee roster
+// @Description Imports a CSV or XLSX roster, creating or updating one signature per employee keyed by employee email. Updates merge the roster's fields into the signature, keeping fields the roster does not map or leaves blank. Columns are mapped with a saved mapping (mapping_id), an inline JSON mapping, or else by matching headers to field names. Every row is validated first; if any row is invalid nothing is saved and the report lists the errors per row. With dry_run=true the report shows what would be created or updated without saving. Saved signatures are linted and the report counts their lint errors per row and in total.
+// @Tags Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX roster with a header row"
+// @Param mapping_id formData string false "Saved mapping ID"
+// @Param mapping formData string false "Inline JSON mapping of field to column header"
+// @Param brand_kit_id formData string false "Brand kit applied to every imported signature"
+// @Param dry_run formData bool false "Validate and report without saving"
+// @Success 200 {object} ImportReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 422 {object} ImportReport
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signatures/import [post]
+func ImportSignatures(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	dryRun := c.FormValue("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Missing file"})
+	}
+	if fileHeader.Size > roster.MaxFileBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: roster.ErrTooLarge.Error()})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Failed to read file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, roster.MaxFileBytes+1))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Failed to read file"})
+	}
+
+	sheet, err := roster.Parse(data)
+	if errors.Is(err, roster.ErrTooLarge) {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	mapping, err := importMapping(c, userID, sheet.Headers)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	var brandKitID interface{}
+	if id := c.FormValue("brand_kit_id"); id != "" {
+		brandKit, err := loadBrandKit(id, userID, false)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Brand kit not found"})
+		}
+		brandKitID = brandKit.ID
+	}
+
+	employees, err := mapping.Employees(sheet)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	report, err := planImport(userID, mapping, employees)
+	if err != nil {
+		log.Printf("Failed to plan import: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to import signatures"})
+	}
+	report.DryRun = dryRun
+
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(report)
+	}
+	if report.InvalidRows > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(report)
+	}
+
+	// Every row is valid, so save them all or none
+	if err := applyImport(userID, brandKitID, employees, report); err != nil {
+		log.Printf("Failed to import signatures: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to import signatures"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
+// importMapping resolves the mapping for an import request: a saved
+// mapping, an inline one, or one guessed from the headers
+func importMapping(c *fiber.Ctx, userID string, headers []string) (roster.Mapping, error) {
+	var mapping roster.Mapping
+	switch {
+	case c.FormValue("mapping_id") != "":
+		saved, err := scanImportMapping(database.DB.QueryRow(
+			context.Background(),
+			"SELECT "+importMappingColumns+" FROM import_mappings WHERE id = $1 AND user_id = $2",
+			c.FormValue("mapping_id"),
+			userID,
+		))
+		if err != nil {
+			return nil, errors.New("mapping not found")
+		}
+		mapping = saved.Columns
+	case c.FormValue("mapping") != "":
+		if err := json.Unmarshal([]byte(c.FormValue("mapping")), &mapping); err != nil {
+			return nil, errors.New("mapping must be a JSON object of field to column header")
+		}
+	default:
+		mapping = roster.DefaultMapping(headers)
+	}
+
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// planImport builds the import report, marking valid rows as creates or
+// updates depending on whether the employee already has a signature
+func planImport(userID string, mapping roster.Mapping, employees []roster.Employee) (*ImportReport, error) {
+	emails := []string{}
+	for _, employee := range employees {
+		if employee.Email != "" {
+			emails = append(emails, employee.Email)
+		}
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT LOWER(employee_email) FROM signatures WHERE user_id = $1 AND LOWER(employee_email) = ANY($2)",
+		userID,
+		emails,
+	)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, email := range existing {
+		exists[email] = true
+	}
+
+	report := &ImportReport{Mapping: mapping, TotalRows: len(employees), Rows: []ImportRowResult{}}
+	for _, employee := range employees {
+		result := ImportRowResult{Row: employee.Line, Email: employee.Email, Errors: employee.Errors}
+		switch {
+		case len(employee.Errors) > 0:
+			result.Action = ImportSkip
+			report.InvalidRows++
+		case exists[employee.Email]:
+			result.Action = ImportUpdate
+			report.ValidRows++
+			report.Updated++
+		default:
+			result.Action = ImportCreate
+			report.ValidRows++
+			report.Created++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+// applyImport upserts every employee's signature in one transaction,
+// corrects the report's counts with what the database actually did and
+// lints the saved signatures. Existing signatures keep the fields the
+// roster does not map or leaves blank.
+func applyImport(userID string, brandKitID interface{}, employees []roster.Employee, report *ImportReport) error {
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	report.Created, report.Updated = 0, 0
+	signatureIDs := make([]string, len(employees))
+	for i, employee := range employees {
+		var inserted bool
+		err := tx.QueryRow(
+			ctx,
+			`INSERT INTO signatures (user_id, employee_email, template_data, brand_kit_id) VALUES ($1, $2, $3, $4)
+             ON CONFLICT (user_id, LOWER(employee_email)) WHERE employee_email IS NOT NULL DO UPDATE
+             SET employee_email = EXCLUDED.employee_email, template_data = signatures.template_data || EXCLUDED.template_data, brand_kit_id = COALESCE(EXCLUDED.brand_kit_id, signatures.brand_kit_id)
+             RETURNING id, xmax = 0`,
+			userID,
+			employee.Email,
+			employee.TemplateData,
+			brandKitID,
+		).Scan(&signatureIDs[i], &inserted)
+		if err != nil {
+			return err
+		}
+
+		if inserted {
+			report.Rows[i].Action = ImportCreate
+			report.Created++
+		} else {
+			report.Rows[i].Action = ImportUpdate
+			report.Updated++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// The import has committed, so signatures that cannot be linted are
+	// only logged
+	report.LintErrors = 0
+	for i, signatureID := range signatureIDs {
+		lintErrors, err := LintSavedSignature(signatureID)
+		if err != nil {
+			log.Printf("Failed to lint imported signature %s: %v\n", signatureID, err)
+			continue
+		}
+		report.Rows[i].LintErrors = lintErrors
+		report.LintErrors += lintErrors
+	}
+	return nil
+}
+
+// importMappingColumns selects the columns scanImportMapping expects
+const importMappingColumns = "id, name, columns, created_at, updated_at"
+
+func scanImportMapping(row pgx.Row) (*ImportMappingResponse, error) {
+	mapping := &ImportMappingResponse{}
+	if err := row.Scan(&mapping.ID, &mapping.Name, &mapping.Columns, &mapping.CreatedAt, &mapping.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
@@ -3,19 +3,107 @@ package handlers
 import (
 	"context"
 	"email-signature-backend/database"
+	"email-signature-backend/tracking"
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"log"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type LinkRequest struct {
 	SignatureID string `json:"signature_id"`
 	URL         string `json:"url"`
+	// Slug is an optional vanity slug, unique within the signature's
+	// organization
+	Slug string `json:"slug"`
+	// UTM overrides the signature's UTM parameters for this link
+	UTM tracking.UTM `json:"utm"`
+	LinkLimits
 }
 
+// LinkLimits end a link's life: after ExpiresAt or once it has MaxClicks
+// clicks its redirect serves FallbackURL, or a "link expired" page
+type LinkLimits struct {
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxClicks   *int       `json:"max_clicks"`
+	FallbackURL string     `json:"fallback_url"`
+}
+
+type UpdateLinkRequest struct {
+	URL string       `json:"url"`
+	UTM tracking.UTM `json:"utm"`
+	LinkLimits
+}
+
+type LinkResponse struct {
+	ID          string  `json:"id"`
+	SignatureID string  `json:"signature_id"`
+	URL         string  `json:"url"`
+	Code        string  `json:"code"`
+	TrackedURL  string  `json:"tracked_url"`
+	Slug        *string `json:"slug"`
+	VanityURL   string  `json:"vanity_url,omitempty"`
+	// UTM is the link's own UTM parameters, merged with the signature's
+	// at click time
+	UTM tracking.UTM `json:"utm"`
+	// CampaignID and VariantID are set on banner links, whose destination
+	// follows the campaign or variant
+	CampaignID  *string    `json:"campaign_id"`
+	VariantID   *string    `json:"variant_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxClicks   *int       `json:"max_clicks"`
+	FallbackURL *string    `json:"fallback_url"`
+	// Clicks counts clicks by people, leaving out bots and scanners
+	Clicks int `json:"clicks"`
+	// Expired reports whether the link has passed its expiry date or
+	// click limit
+	Expired    bool       `json:"expired"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type LinksListResponse struct {
+	Links   []LinkResponse `json:"links"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+	Total   int            `json:"total"`
+}
+
+// linkColumns selects the columns scanLink expects
+const linkColumns = `l.id, l.signature_id, l.url, l.code, l.slug, o.link_prefix, l.utm, l.campaign_id, l.variant_id,
+         l.expires_at, l.max_clicks, l.fallback_url, (SELECT COUNT(*) FROM clicks WHERE link_id = l.id AND traffic = 'human'),
+         l.archived_at, l.created_at, COALESCE(l.updated_at, l.created_at)`
+
+// linkFrom joins what linkColumns needs; linkAccess limits it to links of
+// the user bound to userParam
+const linkFrom = " FROM links l LEFT JOIN organizations o ON o.id = l.organization_id"
+
+func linkAccess(userParam string) string {
+	return "l.signature_id IN (SELECT id FROM signatures WHERE user_id = " + userParam + ")"
+}
+
+// linkExpired is true for links (aliased l) past their expiry date or
+// click limit, which only counts clicks by people
+const linkExpired = `((l.expires_at IS NOT NULL AND l.expires_at <= NOW()) OR
+         (l.max_clicks IS NOT NULL AND (SELECT COUNT(*) FROM clicks WHERE link_id = l.id AND traffic = 'human') >= l.max_clicks))`
+
+// Page sizes of link listings
+const (
+	defaultLinksPerPage = 20
+	maxLinksPerPage     = 100
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
 // CreateLink godoc
 // @Summary Create a new link for a signature
-// @Description Adds a new link to an existing signature, ensuring the signature belongs to the authenticated user
+// @Description Adds a new link to an existing signature, ensuring the signature belongs to the authenticated user. Every link gets a short code; links of organization signatures can also have a vanity slug, served at /r/{organization link prefix}/{slug}. An optional expiry date or click limit makes the redirect serve fallback_url afterwards.
 // @Tags Links
 // @Accept json
 // @Produce json
@@ -23,6 +111,7 @@ type LinkRequest struct {
 // @Success 201 {object} map[string]string "Link created successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request payload"
 // @Failure 401 {object} map[string]interface{} "Unauthorized to add links to this signature"
+// @Failure 409 {object} map[string]interface{} "Slug already in use"
 // @Failure 500 {object} map[string]interface{} "Failed to create link"
 // @Security BearerAuth
 // @Router /api/links [post]
@@ -37,32 +126,78 @@ func CreateLink(c *fiber.Ctx) error {
 		})
 	}
 
+	if !isHTTPURL(req.URL) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "URL must be an absolute http(s) URL",
+		})
+	}
+	if err := req.LinkLimits.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := req.UTM.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if req.Slug != "" && !slugPattern.MatchString(req.Slug) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Slug must be 2-63 lowercase letters, digits or hyphens",
+		})
+	}
+
 	// Ensure the signature belongs to the user
-	var count int
+	var organizationID, linkPrefix *string
 	err := database.DB.QueryRow(
 		context.Background(),
-		"SELECT COUNT(*) FROM signatures WHERE id = $1 AND user_id = $2",
+		`SELECT s.organization_id, o.link_prefix FROM signatures s
+         LEFT JOIN organizations o ON o.id = s.organization_id
+         WHERE s.id = $1 AND s.user_id = $2`,
 		req.SignatureID,
 		userID,
-	).Scan(&count)
+	).Scan(&organizationID, &linkPrefix)
 
-	if err != nil || count == 0 {
+	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Unauthorized to add links to this signature",
 		})
 	}
 
+	if req.Slug != "" && organizationID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Vanity slugs are only available for organization signatures",
+		})
+	}
+
 	// Generate a new link ID
 	linkID := uuid.New()
 
 	// Insert the link into the database
-	_, err = database.DB.Exec(
-		context.Background(),
-		"INSERT INTO links (id, signature_id, url) VALUES ($1, $2, $3)",
-		linkID,
-		req.SignatureID,
-		req.URL,
-	)
+	var code string
+	err = insertLink(func() error {
+		return database.DB.QueryRow(
+			context.Background(),
+			`INSERT INTO links (id, signature_id, url, slug, organization_id, expires_at, max_clicks, fallback_url, utm)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING code`,
+			linkID,
+			req.SignatureID,
+			req.URL,
+			nullIfEmpty(req.Slug),
+			organizationID,
+			req.ExpiresAt,
+			req.MaxClicks,
+			nullIfEmpty(req.FallbackURL),
+			req.UTM,
+		).Scan(&code)
+	})
+	if isConstraintViolation(err, "links_slug_idx") {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Slug already in use in this organization",
+		})
+	}
 	if err != nil {
 		log.Printf("Failed to insert link: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -70,18 +205,295 @@ func CreateLink(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Link created successfully",
-		"link_id": linkID,
+	response := fiber.Map{
+		"message":     "Link created successfully",
+		"link_id":     linkID,
+		"code":        code,
+		"tracked_url": trackedLinkURL(code),
+	}
+	if req.Slug != "" {
+		response["slug"] = req.Slug
+		response["vanity_url"] = vanityLinkURL(*linkPrefix, req.Slug)
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// GetLinks godoc
+// @Summary List links
+// @Description Lists the user's links, newest first, with their click counts. Archived links are left out unless archived=true.
+// @Tags Links
+// @Produce json
+// @Param signature_id query string false "Only links of this signature"
+// @Param archived query bool false "List archived links instead"
+// @Param page query int false "Page number, from 1"
+// @Param per_page query int false "Links per page, 20 by default and at most 100"
+// @Success 200 {object} LinksListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links [get]
+func GetLinks(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	page := c.QueryInt("page", 1)
+	perPage := c.QueryInt("per_page", defaultLinksPerPage)
+	if page < 1 || perPage < 1 || perPage > maxLinksPerPage {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: fmt.Sprintf("page must be at least 1 and per_page between 1 and %d", maxLinksPerPage)})
+	}
+
+	where := " WHERE " + linkAccess("$1") + " AND l.archived_at IS NULL"
+	if c.QueryBool("archived") {
+		where = " WHERE " + linkAccess("$1") + " AND l.archived_at IS NOT NULL"
+	}
+	args := []interface{}{userID}
+	if signatureID := c.Query("signature_id"); signatureID != "" {
+		if _, err := uuid.Parse(signatureID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid signature_id"})
+		}
+		args = append(args, signatureID)
+		where += " AND l.signature_id = $2"
+	}
+
+	ctx := context.Background()
+	var total int
+	if err := database.DB.QueryRow(ctx, "SELECT COUNT(*)"+linkFrom+where, args...).Scan(&total); err != nil {
+		log.Printf("Failed to count links: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch links"})
+	}
+
+	rows, err := database.DB.Query(
+		ctx,
+		fmt.Sprintf("SELECT %s%s%s ORDER BY l.created_at DESC, l.id LIMIT %d OFFSET %d", linkColumns, linkFrom, where, perPage, (page-1)*perPage),
+		args...,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch links: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch links"})
+	}
+	links, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (LinkResponse, error) {
+		link, err := scanLink(row)
+		if err != nil {
+			return LinkResponse{}, err
+		}
+		return *link, nil
 	})
+	if err != nil {
+		log.Printf("Failed to fetch links: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch links"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LinksListResponse{Links: links, Page: page, PerPage: perPage, Total: total})
+}
+
+// GetLink godoc
+// @Summary Get a link
+// @Tags Links
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} LinkResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links/{id} [get]
+func GetLink(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	link, err := loadLink(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Link not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(link)
+}
+
+// UpdateLink godoc
+// @Summary Update a link
+// @Description Changes the link's destination, limits and UTM parameters. Its code, slug and clicks are kept, so tracked URLs already sent follow the new destination. Banner links follow their campaign or variant and cannot be edited here.
+// @Tags Links
+// @Accept json
+// @Produce json
+// @Param id path string true "Link ID"
+// @Param request body UpdateLinkRequest true "Link payload"
+// @Success 200 {object} LinkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links/{id} [put]
+func UpdateLink(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(UpdateLinkRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if !isHTTPURL(req.URL) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "URL must be an absolute http(s) URL"})
+	}
+	if err := req.LinkLimits.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+	if err := req.UTM.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	link, err := loadLink(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Link not found"})
+	}
+	if link.CampaignID != nil || link.VariantID != nil {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Banner links follow their campaign or variant; update that instead"})
+	}
+
+	_, err = database.DB.Exec(
+		context.Background(),
+		"UPDATE links SET url = $2, expires_at = $3, max_clicks = $4, fallback_url = $5, utm = $6, updated_at = NOW() WHERE id = $1",
+		link.ID,
+		req.URL,
+		req.ExpiresAt,
+		req.MaxClicks,
+		nullIfEmpty(req.FallbackURL),
+		req.UTM,
+	)
+	if err != nil {
+		log.Printf("Failed to update link: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update link"})
+	}
+
+	return respondWithLink(c, link.ID, userID)
+}
+
+// ArchiveLink godoc
+// @Summary Archive a link
+// @Description Hides the link from listings. Its tracked URL keeps redirecting and its clicks are kept.
+// @Tags Links
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} LinkResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links/{id}/archive [post]
+func ArchiveLink(c *fiber.Ctx) error {
+	return setLinkArchived(c, "COALESCE(l.archived_at, NOW())")
+}
+
+// RestoreLink godoc
+// @Summary Restore an archived link
+// @Tags Links
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} LinkResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links/{id}/restore [post]
+func RestoreLink(c *fiber.Ctx) error {
+	return setLinkArchived(c, "NULL")
+}
+
+func setLinkArchived(c *fiber.Ctx, archivedAt string) error {
+	userID := c.Locals("user_id").(string)
+
+	link, err := loadLink(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Link not found"})
+	}
+
+	_, err = database.DB.Exec(
+		context.Background(),
+		"UPDATE links l SET archived_at = "+archivedAt+", updated_at = NOW() WHERE l.id = $1",
+		link.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to archive link: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update link"})
+	}
+
+	return respondWithLink(c, link.ID, userID)
+}
+
+// DeleteLink godoc
+// @Summary Delete a link
+// @Description Deletes the link and its clicks. Its tracked URL stops working; archive the link instead to keep it redirecting.
+// @Tags Links
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/links/{id} [delete]
+func DeleteLink(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Link not found"})
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM links l WHERE l.id = $1 AND "+linkAccess("$2"),
+		c.Params("id"),
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to delete link: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete link"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Link not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Link deleted successfully"})
+}
+
+// SetSignatureUTM godoc
+// @Summary Set a signature's UTM parameters
+// @Description Sets the UTM parameters added to the destinations of the signature's tracked links at click time. Links can override each value; unset values default to utm_source=email_signature, utm_medium=email, the banner's campaign and the A/B test variant. Set disabled to leave destinations untouched.
+// @Tags Links
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body tracking.UTM true "UTM parameters"
+// @Success 200 {object} tracking.UTM
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/utm [put]
+func SetSignatureUTM(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	utm := new(tracking.UTM)
+	if err := c.BodyParser(utm); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := utm.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	_, err = database.DB.Exec(context.Background(), "UPDATE signatures SET utm = $1 WHERE id = $2", utm, signature.ID)
+	if err != nil {
+		log.Printf("Failed to set UTM parameters: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to set UTM parameters"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utm)
 }
 
 // CountLinks godoc
 // @Summary Get total links
-// @Description Retrieve the total number of links for the authenticated user.
+// @Description Retrieve the total number of links for the authenticated user. Archived links are left out unless archived=true, matching the link list.
 // @Tags Links
 // @Accept json
 // @Produce json
+// @Param archived query bool false "Count archived links instead"
 // @Security BearerAuth
 // @Success 200 {object} CountResponse
 // @Failure 401 {object} ErrorResponse
@@ -91,16 +503,71 @@ func CountLinks(c *fiber.Ctx) error {
 	// Get user_id from context
 	userID := c.Locals("user_id").(string)
 
-	// Query to count links for the user's signatures
+	// Count the user's links the way GetLinks does
+	archived := " AND l.archived_at IS NULL"
+	if c.QueryBool("archived") {
+		archived = " AND l.archived_at IS NOT NULL"
+	}
 	var count int
-	err := database.DB.QueryRow(context.Background(), `
-		SELECT COUNT(*) 
-		FROM links 
-		WHERE signature_id IN (SELECT id FROM signatures WHERE user_id = $1)
-	`, userID).Scan(&count)
+	err := database.DB.QueryRow(context.Background(), "SELECT COUNT(*) FROM links l WHERE "+linkAccess("$1")+archived, userID).Scan(&count)
 	if err != nil {
+		log.Printf("Failed to count links: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to count links"})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"count": count})
 }
+
+func (l LinkLimits) validate() error {
+	if l.MaxClicks != nil && *l.MaxClicks <= 0 {
+		return errors.New("max_clicks must be positive")
+	}
+	if l.FallbackURL != "" && !isHTTPURL(l.FallbackURL) {
+		return errors.New("fallback_url must be an absolute http(s) URL")
+	}
+	if l.FallbackURL != "" && l.ExpiresAt == nil && l.MaxClicks == nil {
+		return errors.New("fallback_url requires expires_at or max_clicks")
+	}
+	return nil
+}
+
+func loadLink(linkID, userID string) (*LinkResponse, error) {
+	if _, err := uuid.Parse(linkID); err != nil {
+		return nil, err
+	}
+	return scanLink(database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+linkColumns+linkFrom+" WHERE l.id = $1 AND "+linkAccess("$2"),
+		linkID,
+		userID,
+	))
+}
+
+func respondWithLink(c *fiber.Ctx, linkID, userID string) error {
+	link, err := loadLink(linkID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch link: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch link"})
+	}
+	return c.Status(fiber.StatusOK).JSON(link)
+}
+
+func scanLink(row pgx.Row) (*LinkResponse, error) {
+	link := &LinkResponse{}
+	var linkPrefix *string
+	err := row.Scan(
+		&link.ID, &link.SignatureID, &link.URL, &link.Code, &link.Slug, &linkPrefix, &link.UTM, &link.CampaignID, &link.VariantID,
+		&link.ExpiresAt, &link.MaxClicks, &link.FallbackURL, &link.Clicks,
+		&link.ArchivedAt, &link.CreatedAt, &link.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	link.TrackedURL = trackedLinkURL(link.Code)
+	if link.Slug != nil && linkPrefix != nil {
+		link.VanityURL = vanityLinkURL(*linkPrefix, *link.Slug)
+	}
+	link.Expired = (link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt)) ||
+		(link.MaxClicks != nil && link.Clicks >= *link.MaxClicks)
+	return link, nil
+}
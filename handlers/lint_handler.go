@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/lint"
+	"email-signature-backend/templates"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TemplateLintResult struct {
+	Template string      `json:"template"`
+	Passed   bool        `json:"passed"`
+	Report   lint.Report `json:"report"`
+}
+
+// MasterTemplateLintName is the template reported when linting signatures
+// rendered from a master template
+const MasterTemplateLintName = "master"
+
+type LintResponse struct {
+	SignatureID string               `json:"signature_id"`
+	Results     []TemplateLintResult `json:"results"`
+}
+
+// LintSignature godoc
+// @Summary Lint a signature for email-client compatibility
+// @Description Exports the signature as the export endpoint serves it, with its banner and footer, CSS inlined and minified, with each registered template (or only the requested one) and reports markup that Outlook, Gmail and Apple Mail are known to break. Signatures based on a master template are linted with the master only.
+// @Tags Signatures
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param template query string false "Template to lint (defaults to every registered template)"
+// @Success 200 {object} LintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/lint [get]
+func LintSignature(c *fiber.Ctx) error {
+	// Get user_id from context
+	userID := c.Locals("user_id").(string)
+
+	// Get signature_id from URL params
+	signatureID := c.Params("id")
+
+	names := templates.Names()
+	if name := c.Query("template"); name != "" {
+		if !templates.Exists(name) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Unknown template"})
+		}
+		names = []string{name}
+	}
+
+	// Fetch the signature and its brand kit from the database
+	signature, err := loadSignature(signatureID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch signature: %v\n", err)
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	results, err := signature.lint(names)
+	if err != nil {
+		log.Printf("Failed to render signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to render signature"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(LintResponse{
+		SignatureID: signatureID,
+		Results:     results,
+	})
+}
+
+// lintExport runs the signature, or one of its variants, through the
+// export pipeline with the template, as ExportSignature serves it, and
+// lints the result
+func (s *signatureRecord) lintExport(templateName string, variant *VariantResponse) (lint.Report, error) {
+	result, err := exportSignature(s, templateName, variant, contentContext(s, templates.FormatHTML), false)
+	if err != nil {
+		return lint.Report{}, err
+	}
+	return lint.Lint(result.HTML), nil
+}
+
+// lintStoredSignature lints a signature as it is stored, for reporting
+// problems introduced by a change straight away
+func lintStoredSignature(signatureID string) ([]TemplateLintResult, error) {
+	row := database.DB.QueryRow(context.Background(), signatureRecordQuery+" WHERE s.id = $1", signatureID)
+	signature, err := scanSignatureRecord(row)
+	if err != nil {
+		return nil, err
+	}
+	return signature.lint(templates.Names())
+}
+
+// lintExport lints exported signature HTML, logging the errors it finds
+// since the export is served or deployed regardless
+func lintExport(signatureID, signatureHTML string) lint.Report {
+	report := lint.Lint(signatureHTML)
+	for _, issue := range report.Issues {
+		if issue.Severity == lint.SeverityError {
+			log.Printf("Exported signature %s fails lint rule %s: %s\n", signatureID, issue.Rule, issue.Message)
+		}
+	}
+	return report
+}
+
+// lintWarning is reported when a change was saved but the signature could
+// not be linted
+const lintWarning = "Saved, but the signature could not be linted"
+
+// withLint adds the lint report of a signature that was just saved to the
+// response. The change has committed by then, so a failure to lint leaves
+// lint null with a warning rather than failing the request.
+func withLint(response fiber.Map, signatureID string) fiber.Map {
+	results, err := lintStoredSignature(signatureID)
+	if err != nil {
+		log.Printf("Failed to lint signature %s: %v\n", signatureID, err)
+		response["lint"] = nil
+		response["lint_warning"] = lintWarning
+		return response
+	}
+	response["lint"] = results
+	return response
+}
+
+// LintSavedSignature lints a signature saved without anyone to show the
+// report to, such as by SCIM provisioning or a directory sync, logs the
+// errors found and returns their number
+func LintSavedSignature(signatureID string) (int, error) {
+	results, err := lintStoredSignature(signatureID)
+	if err != nil {
+		return 0, err
+	}
+	errorCount := 0
+	for _, result := range results {
+		for _, issue := range result.Report.Issues {
+			if issue.Severity == lint.SeverityError {
+				log.Printf("Signature %s fails lint rule %s with template %s: %s\n", signatureID, issue.Rule, result.Template, issue.Message)
+			}
+		}
+		errorCount += result.Report.Errors
+	}
+	return errorCount, nil
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/master"
+	"email-signature-backend/templates"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+type MasterTemplateRequest struct {
+	Name    string          `json:"name"`
+	Regions []master.Region `json:"regions"`
+	// Values fill placeholders for every employee, e.g. {"company": "Acme"}
+	Values map[string]string `json:"values"`
+	// ContentRules choose footer content for every employee signature,
+	// showing the organization's disclaimers or inline text
+	ContentRules []templates.Rule `json:"content_rules"`
+}
+
+type MasterTemplateResponse struct {
+	ID             string            `json:"id"`
+	OrganizationID string            `json:"organization_id"`
+	Name           string            `json:"name"`
+	Regions        []master.Region   `json:"regions"`
+	Values         map[string]string `json:"values"`
+	ContentRules   []templates.Rule  `json:"content_rules"`
+	// PersonalFields are the placeholders each employee fills in
+	PersonalFields []string `json:"personal_fields"`
+	// EditableRegions are the regions employees may replace
+	EditableRegions []string  `json:"editable_regions"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type MasterTemplatesListResponse struct {
+	MasterTemplates []MasterTemplateResponse `json:"master_templates"`
+}
+
+type MasterSignatureRequest struct {
+	// Fields are the employee's personal placeholder values
+	Fields map[string]string `json:"fields"`
+	// RegionOverrides replace the text of editable regions
+	RegionOverrides map[string]string `json:"region_overrides"`
+	EmployeeEmail   string            `json:"employee_email"`
+}
+
+type SignatureFieldsRequest struct {
+	Fields          map[string]string `json:"fields"`
+	RegionOverrides map[string]string `json:"region_overrides"`
+}
+
+// masterTemplateColumns selects the columns scanMasterTemplate expects
+const masterTemplateColumns = "mt.id, mt.organization_id, mt.name, mt.regions, mt.placeholder_values, mt.content_rules, mt.created_at, mt.updated_at"
+
+// CreateMasterTemplate godoc
+// @Summary Create a master template
+// @Description Creates an organization-wide signature design made of regions. Regions may use {{placeholder}} fields; placeholders without an admin-set value are the personal fields each employee fills in. Employees cannot change locked regions and may replace the text of editable ones. Only admins can manage master templates.
+// @Tags Master Templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body MasterTemplateRequest true "Master template payload"
+// @Success 201 {object} MasterTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/master-templates [post]
+func CreateMasterTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req, err := parseMasterTemplateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage master templates"})
+	}
+	if err := validateContentRules(req.ContentRules, organizationDisclaimers, organizationID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	row := database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO master_templates AS mt (organization_id, name, regions, placeholder_values, content_rules)
+         VALUES ($1, $2, $3, $4, $5)
+         RETURNING `+masterTemplateColumns,
+		organizationID,
+		req.Name,
+		req.Regions,
+		req.Values,
+		req.ContentRules,
+	)
+	masterTemplate, err := scanMasterTemplate(row)
+	if err != nil {
+		log.Printf("Failed to insert master template: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create master template"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(masterTemplate)
+}
+
+// GetMasterTemplates godoc
+// @Summary List an organization's master templates
+// @Tags Master Templates
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} MasterTemplatesListResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/master-templates [get]
+func GetMasterTemplates(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	if !isOrganizationMember(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "You are not a member of this organization"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+masterTemplateColumns+" FROM master_templates mt WHERE mt.organization_id = $1 ORDER BY mt.name",
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch master templates: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch master templates"})
+	}
+	defer rows.Close()
+
+	masterTemplates := []MasterTemplateResponse{}
+	for rows.Next() {
+		masterTemplate, err := scanMasterTemplate(rows)
+		if err != nil {
+			log.Printf("Failed to scan master template: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch master templates"})
+		}
+		masterTemplates = append(masterTemplates, *masterTemplate)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MasterTemplatesListResponse{MasterTemplates: masterTemplates})
+}
+
+// GetMasterTemplate godoc
+// @Summary Get a master template
+// @Description Returns the master template with the personal fields and editable regions employees fill in
+// @Tags Master Templates
+// @Produce json
+// @Param id path string true "Master template ID"
+// @Success 200 {object} MasterTemplateResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/master-templates/{id} [get]
+func GetMasterTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	masterTemplate, err := loadMasterTemplate(c.Params("id"))
+	if err != nil || !isOrganizationMember(masterTemplate.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Master template not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(masterTemplate)
+}
+
+// UpdateMasterTemplate godoc
+// @Summary Update a master template
+// @Description Replaces the master template's design and values. Every employee signature based on it is re-rendered and linted, and the results are returned. Employee text for regions that are no longer editable is ignored.
+// @Tags Master Templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Master template ID"
+// @Param request body MasterTemplateRequest true "Master template payload"
+// @Success 200 {object} map[string]interface{} "Master template updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/master-templates/{id} [put]
+func UpdateMasterTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req, err := parseMasterTemplateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	masterTemplate, err := loadMasterTemplate(c.Params("id"))
+	if err != nil || !isOrganizationAdmin(masterTemplate.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Master template not found or unauthorized"})
+	}
+	if err := validateContentRules(req.ContentRules, organizationDisclaimers, masterTemplate.OrganizationID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	_, err = database.DB.Exec(
+		context.Background(),
+		"UPDATE master_templates SET name = $1, regions = $2, placeholder_values = $3, content_rules = $4, updated_at = NOW() WHERE id = $5",
+		req.Name,
+		req.Regions,
+		req.Values,
+		req.ContentRules,
+		masterTemplate.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to update master template: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update master template"})
+	}
+
+	// Re-render every dependent signature so problems introduced by the new
+	// design are reported straight away
+	rerendered, err := rerenderSignatures("s.master_template_id = $1 AND s.archived_at IS NULL", masterTemplate.ID)
+	if err != nil {
+		log.Printf("Failed to re-render master template signatures: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Master template updated but signatures could not be re-rendered"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Master template updated successfully",
+		"signatures": rerendered,
+	})
+}
+
+// DeleteMasterTemplate godoc
+// @Summary Delete a master template
+// @Description Deletes a master template. Templates that employee signatures are still based on cannot be deleted.
+// @Tags Master Templates
+// @Produce json
+// @Param id path string true "Master template ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/master-templates/{id} [delete]
+func DeleteMasterTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	masterTemplate, err := loadMasterTemplate(c.Params("id"))
+	if err != nil || !isOrganizationAdmin(masterTemplate.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Master template not found or unauthorized"})
+	}
+
+	_, err = database.DB.Exec(context.Background(), "DELETE FROM master_templates WHERE id = $1", masterTemplate.ID)
+	if isForeignKeyViolation(err) {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "Signatures are still based on this master template"})
+	}
+	if err != nil {
+		log.Printf("Failed to delete master template: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete master template"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Master template deleted successfully"})
+}
+
+// CreateMasterSignature godoc
+// @Summary Create a signature from a master template
+// @Description Creates the authenticated employee's signature from an organization master template. Only the personal fields and editable region text are stored; the design always comes from the master. The response includes a compatibility lint report.
+// @Tags Master Templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Master template ID"
+// @Param request body MasterSignatureRequest true "Employee fields"
+// @Success 201 {object} map[string]interface{} "Signature created successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/master-templates/{id}/signatures [post]
+func CreateMasterSignature(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(MasterSignatureRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	masterTemplate, err := loadMasterTemplate(c.Params("id"))
+	if err != nil || !isOrganizationMember(masterTemplate.OrganizationID, userID) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Master template not found"})
+	}
+
+	design := masterTemplate.template()
+	if err := design.CheckEmployee(req.Fields, req.RegionOverrides); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	var employeeEmail interface{}
+	if req.EmployeeEmail != "" {
+		if address, err := mail.ParseAddress(req.EmployeeEmail); err != nil || address.Address != req.EmployeeEmail {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid employee email"})
+		}
+		employeeEmail = strings.ToLower(req.EmployeeEmail)
+	}
+
+	var signatureID string
+	err = database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO signatures (user_id, organization_id, master_template_id, template_data, region_overrides, employee_email)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		userID,
+		masterTemplate.OrganizationID,
+		masterTemplate.ID,
+		stringMapOrEmpty(req.Fields),
+		stringMapOrEmpty(req.RegionOverrides),
+		employeeEmail,
+	).Scan(&signatureID)
+	if isUniqueViolation(err) {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "You already have a signature for this employee email"})
+	}
+	if err != nil {
+		log.Printf("Failed to insert signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create signature"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(withLint(fiber.Map{
+		"message":      "Signature created successfully",
+		"signature_id": signatureID,
+	}, signatureID))
+}
+
+// UpdateSignatureFields godoc
+// @Summary Update an employee's personal fields
+// @Description Replaces the personal fields and editable region text of a signature based on a master template. The response includes a compatibility lint report.
+// @Tags Master Templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body SignatureFieldsRequest true "Employee fields"
+// @Success 200 {object} map[string]interface{} "Signature updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/fields [put]
+func UpdateSignatureFields(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(SignatureFieldsRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+	if signature.MasterTemplateID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Signature is not based on a master template"})
+	}
+	if err := signature.Master.CheckEmployee(req.Fields, req.RegionOverrides); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	_, err = database.DB.Exec(
+		context.Background(),
+		"UPDATE signatures SET template_data = $1, region_overrides = $2 WHERE id = $3",
+		stringMapOrEmpty(req.Fields),
+		stringMapOrEmpty(req.RegionOverrides),
+		signature.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to update signature fields: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update signature"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(withLint(fiber.Map{
+		"message": "Signature updated successfully",
+	}, signature.ID))
+}
+
+// parseMasterTemplateRequest parses and validates a master template payload
+func parseMasterTemplateRequest(c *fiber.Ctx) (*MasterTemplateRequest, error) {
+	req := new(MasterTemplateRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return nil, errors.New("Invalid request payload")
+	}
+	req.Values = stringMapOrEmpty(req.Values)
+	req.ContentRules = rulesOrEmpty(req.ContentRules)
+
+	design := master.Template{Regions: req.Regions, Values: req.Values}
+	if err := design.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// template returns the master's design
+func (m *MasterTemplateResponse) template() *master.Template {
+	return &master.Template{Regions: m.Regions, Values: m.Values}
+}
+
+func loadMasterTemplate(masterTemplateID string) (*MasterTemplateResponse, error) {
+	row := database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+masterTemplateColumns+" FROM master_templates mt WHERE mt.id = $1",
+		masterTemplateID,
+	)
+	return scanMasterTemplate(row)
+}
+
+func scanMasterTemplate(row pgx.Row) (*MasterTemplateResponse, error) {
+	m := &MasterTemplateResponse{}
+	err := row.Scan(&m.ID, &m.OrganizationID, &m.Name, &m.Regions, &m.Values, &m.ContentRules, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	design := m.template()
+	m.PersonalFields = design.PersonalFields()
+	m.EditableRegions = design.EditableRegions()
+	return m, nil
+}
+
+// stringMapOrEmpty stores a missing map as an empty JSON object
+func stringMapOrEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/config"
+	"email-signature-backend/database"
+	"email-signature-backend/tracking"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OpenTrackingRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type OpenStatsResponse struct {
+	SignatureID string `json:"signature_id"`
+	// Opens counts every pixel load, Direct those from recipients' mail
+	// clients and Proxied those from image proxies, by proxy
+	Opens      int            `json:"opens"`
+	Direct     int            `json:"direct"`
+	Proxied    map[string]int `json:"proxied"`
+	LastOpened *time.Time     `json:"last_opened"`
+}
+
+// transparentGIF is a 1x1 transparent GIF
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// openPixelURL is the URL of the tracking pixel of a signature. Opens
+// record the variant, if any.
+func openPixelURL(signatureID string, variant *VariantResponse) string {
+	url := config.PublicBaseURL() + "/o/" + signatureID
+	if variant != nil {
+		url += "?v=" + variant.ID
+	}
+	return url
+}
+
+// OpenPixel godoc
+// @Summary Tracking pixel
+// @Description Public 1x1 image embedded in signatures that track opens. Records the open with the requester's IP address (as the privacy mode stores it), user agent and A/B test variant, leaving both out for requests with Do-Not-Track or Global Privacy Control; loads by known image proxies (Gmail, Apple Mail Privacy Protection, Yahoo) are flagged with the proxy. Always serves the image, and records nothing for signatures that do not track opens.
+// @Tags Opens
+// @Param id path string true "Signature ID"
+// @Param v query string false "A/B test variant the signature was exported with"
+// @Produce image/gif
+// @Success 200 {file} file "1x1 transparent GIF"
+// @Router /o/{id} [get]
+func OpenPixel(c *fiber.Ctx) error {
+	visitor, err := requestVisitor(c)
+	if err != nil {
+		log.Printf("Failed to protect IP address: %v\n", err)
+	}
+	_, err = database.DB.Exec(
+		context.Background(),
+		`INSERT INTO opens (signature_id, variant_id, ip_address, user_agent, proxy)
+         SELECT s.id, (SELECT v.id FROM signature_variants v WHERE v.signature_id = s.id AND v.id::text = $2), $3, $4, $5
+         FROM signatures s WHERE s.id::text = $1 AND s.track_opens`,
+		c.Params("id"),
+		c.Query("v"),
+		nullIfEmpty(visitor.IP),
+		nullIfEmpty(visitor.UserAgent),
+		nullIfEmpty(tracking.ImageProxy(c.Get(fiber.HeaderUserAgent))),
+	)
+	if err != nil {
+		// A lost open must not show a broken image
+		log.Printf("Failed to record open: %v\n", err)
+	}
+
+	// Every load has to reach the server to be counted
+	c.Set(fiber.HeaderCacheControl, "no-store, no-cache, must-revalidate, private")
+	c.Type("gif")
+	return c.Status(fiber.StatusOK).Send(transparentGIF)
+}
+
+// SetOpenTracking godoc
+// @Summary Turn open tracking on or off
+// @Description Sets whether exports and deployments of the signature embed a tracking pixel. Turning it off also stops recording opens of signatures already sent.
+// @Tags Opens
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body OpenTrackingRequest true "Open tracking setting"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/open-tracking [put]
+func SetOpenTracking(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	req := new(OpenTrackingRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	_, err = database.DB.Exec(context.Background(), "UPDATE signatures SET track_opens = $1 WHERE id = $2", req.Enabled, signature.ID)
+	if err != nil {
+		log.Printf("Failed to set open tracking: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to set open tracking"})
+	}
+
+	if req.Enabled {
+		return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Open tracking turned on"})
+	}
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Open tracking turned off"})
+}
+
+// GetOpenStats godoc
+// @Summary Count a signature's opens
+// @Description Counts the signature's tracking pixel loads. Image proxies load the pixel when a message is delivered or prefetched rather than read, so their loads are reported apart from direct opens.
+// @Tags Opens
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Success 200 {object} OpenStatsResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/opens [get]
+func GetOpenStats(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT COALESCE(proxy, ''), COUNT(*), MAX(timestamp) FROM opens WHERE signature_id = $1 GROUP BY proxy",
+		signature.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch opens: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch opens"})
+	}
+	defer rows.Close()
+
+	stats := OpenStatsResponse{SignatureID: signature.ID, Proxied: map[string]int{}}
+	for rows.Next() {
+		var proxy string
+		var count int
+		var last *time.Time
+		if err := rows.Scan(&proxy, &count, &last); err != nil {
+			log.Printf("Failed to parse opens: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch opens"})
+		}
+		stats.Opens += count
+		if proxy == "" {
+			stats.Direct = count
+		} else {
+			stats.Proxied[proxy] = count
+		}
+		if last != nil && (stats.LastOpened == nil || last.After(*stats.LastOpened)) {
+			stats.LastOpened = last
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to fetch opens: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch opens"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
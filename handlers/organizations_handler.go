@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/scim"
+	"email-signature-backend/templates"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Organization member roles
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+type OrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type OrganizationResponse struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	Role              string  `json:"role"`
+	DefaultTemplate   string  `json:"default_template"`
+	DefaultBrandKitID *string `json:"default_brand_kit_id"`
+	// ClickRetentionDays is how long clicks and opens are kept; null
+	// falls back to the server default
+	ClickRetentionDays *int      `json:"click_retention_days"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type OrganizationsListResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+}
+
+type MemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type OrganizationDefaultsRequest struct {
+	DefaultTemplate   string `json:"default_template"`
+	DefaultBrandKitID string `json:"default_brand_kit_id"`
+}
+
+type RetentionRequest struct {
+	// ClickRetentionDays is how long clicks and opens are kept; null
+	// falls back to the server default
+	ClickRetentionDays *int `json:"click_retention_days"`
+}
+
+type SCIMTokenRequest struct {
+	Description string `json:"description"`
+}
+
+type SCIMTokenResponse struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Token is only returned when the token is created
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type SCIMTokensListResponse struct {
+	Tokens []SCIMTokenResponse `json:"tokens"`
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Creates an organization and makes the authenticated user its admin
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param request body OrganizationRequest true "Organization payload"
+// @Success 201 {object} map[string]interface{} "Organization created successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations [post]
+func CreateOrganization(c *fiber.Ctx) error {
+	// Get user_id from context
+	userID := c.Locals("user_id").(string)
+
+	req := new(OrganizationRequest)
+	if err := c.BodyParser(req); err != nil || req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+
+	tx, err := database.DB.Begin(context.Background())
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create organization"})
+	}
+	defer tx.Rollback(context.Background())
+
+	organizationID := uuid.New()
+	_, err = tx.Exec(
+		context.Background(),
+		"INSERT INTO organizations (id, name) VALUES ($1, $2)",
+		organizationID,
+		req.Name,
+	)
+	if err != nil {
+		log.Printf("Failed to insert organization: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create organization"})
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		"INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)",
+		organizationID,
+		userID,
+		RoleAdmin,
+	)
+	if err != nil {
+		log.Printf("Failed to insert organization admin: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create organization"})
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		log.Printf("Failed to commit transaction: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create organization"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":         "Organization created successfully",
+		"organization_id": organizationID,
+	})
+}
+
+// GetOrganizations godoc
+// @Summary List organizations
+// @Description Retrieve the organizations the authenticated user belongs to, with their role in each
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} OrganizationsListResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations [get]
+func GetOrganizations(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		`SELECT o.id, o.name, m.role, o.default_template, o.default_brand_kit_id, o.click_retention_days, o.created_at
+         FROM organizations o
+         JOIN organization_members m ON m.organization_id = o.id
+         WHERE m.user_id = $1
+         ORDER BY o.name`,
+		userID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch organizations: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch organizations"})
+	}
+	defer rows.Close()
+
+	organizations := []OrganizationResponse{}
+	for rows.Next() {
+		var organization OrganizationResponse
+		if err := rows.Scan(
+			&organization.ID, &organization.Name, &organization.Role,
+			&organization.DefaultTemplate, &organization.DefaultBrandKitID, &organization.ClickRetentionDays, &organization.CreatedAt,
+		); err != nil {
+			log.Printf("Failed to parse organization: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse organizations"})
+		}
+		organizations = append(organizations, organization)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(OrganizationsListResponse{Organizations: organizations})
+}
+
+// AddOrganizationMember godoc
+// @Summary Add a member to an organization
+// @Description Adds an existing user to the organization, or updates their role. Only admins can manage members.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body MemberRequest true "Member payload"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/members [post]
+func AddOrganizationMember(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req := new(MemberRequest)
+	if err := c.BodyParser(req); err != nil || req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if req.Role == "" {
+		req.Role = RoleMember
+	}
+	if req.Role != RoleAdmin && req.Role != RoleMember {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Role must be admin or member"})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage members"})
+	}
+
+	var memberID string
+	err := database.DB.QueryRow(
+		context.Background(),
+		"SELECT id FROM users WHERE email = $1",
+		req.Email,
+	).Scan(&memberID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "User not found"})
+	}
+
+	_, err = database.DB.Exec(
+		context.Background(),
+		`INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)
+         ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role`,
+		organizationID,
+		memberID,
+		req.Role,
+	)
+	if err != nil {
+		log.Printf("Failed to add organization member: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to add member"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Member added successfully"})
+}
+
+// organizationRole returns the user's role in the organization, or an
+// empty string if they are not a member
+func organizationRole(organizationID, userID string) (string, error) {
+	var role string
+	err := database.DB.QueryRow(
+		context.Background(),
+		"SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2",
+		organizationID,
+		userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// isOrganizationMember reports whether the user belongs to the organization
+func isOrganizationMember(organizationID, userID string) bool {
+	role, err := organizationRole(organizationID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch organization role: %v\n", err)
+	}
+	return role != ""
+}
+
+// isOrganizationAdmin reports whether the user administers the organization
+func isOrganizationAdmin(organizationID, userID string) bool {
+	role, err := organizationRole(organizationID, userID)
+	if err != nil {
+		log.Printf("Failed to fetch organization role: %v\n", err)
+	}
+	return role == RoleAdmin
+}
+
+// UpdateOrganizationDefaults godoc
+// @Summary Set an organization's signature defaults
+// @Description Sets the template and brand kit used for signatures generated for provisioned employees. Only admins can change them.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body OrganizationDefaultsRequest true "Defaults payload"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/defaults [put]
+func UpdateOrganizationDefaults(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req := new(OrganizationDefaultsRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if req.DefaultTemplate == "" {
+		req.DefaultTemplate = templates.DefaultTemplate
+	}
+	if !templates.Exists(req.DefaultTemplate) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Unknown template"})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can change defaults"})
+	}
+
+	var brandKitID interface{}
+	if req.DefaultBrandKitID != "" {
+		brandKit, err := loadBrandKit(req.DefaultBrandKitID, userID, false)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Brand kit not found"})
+		}
+		brandKitID = brandKit.ID
+	}
+
+	_, err := database.DB.Exec(
+		context.Background(),
+		"UPDATE organizations SET default_template = $1, default_brand_kit_id = $2 WHERE id = $3",
+		req.DefaultTemplate,
+		brandKitID,
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to update organization defaults: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update defaults"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Defaults updated successfully"})
+}
+
+// SetOrganizationRetention godoc
+// @Summary Set an organization's retention period
+// @Description Sets how many days clicks and opens on the organization's signatures are kept before the hourly purge deletes them. Null falls back to the server default (CLICK_RETENTION_DAYS). Only admins can change it.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body RetentionRequest true "Retention payload"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/retention [put]
+func SetOrganizationRetention(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req := new(RetentionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if req.ClickRetentionDays != nil && *req.ClickRetentionDays <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "click_retention_days must be positive"})
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can change the retention period"})
+	}
+
+	_, err := database.DB.Exec(
+		context.Background(),
+		"UPDATE organizations SET click_retention_days = $1 WHERE id = $2",
+		req.ClickRetentionDays,
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to update retention period: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update retention period"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Retention period updated successfully"})
+}
+
+// CreateSCIMToken godoc
+// @Summary Create a SCIM provisioning token
+// @Description Creates a bearer token an identity provider uses to provision employees into the organization over /scim/v2. The token is only shown once. Signatures generated over SCIM are owned by the admin who created the token.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body SCIMTokenRequest false "Token payload"
+// @Success 201 {object} SCIMTokenResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/scim-tokens [post]
+func CreateSCIMToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	req := new(SCIMTokenRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+		}
+	}
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage SCIM tokens"})
+	}
+
+	token, err := scim.NewToken()
+	if err != nil {
+		log.Printf("Failed to generate SCIM token: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create token"})
+	}
+
+	response := SCIMTokenResponse{Description: req.Description, Token: token}
+	err = database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO scim_tokens (organization_id, created_by, description, token_hash) VALUES ($1, $2, $3, $4)
+         RETURNING id, created_at`,
+		organizationID,
+		userID,
+		req.Description,
+		scim.HashToken(token),
+	).Scan(&response.ID, &response.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to insert SCIM token: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create token"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// GetSCIMTokens godoc
+// @Summary List SCIM provisioning tokens
+// @Description Lists the organization's SCIM tokens without their secret values
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} SCIMTokensListResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/scim-tokens [get]
+func GetSCIMTokens(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage SCIM tokens"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT id, description, created_at, last_used_at FROM scim_tokens WHERE organization_id = $1 ORDER BY created_at",
+		organizationID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch SCIM tokens: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch tokens"})
+	}
+	defer rows.Close()
+
+	tokens := []SCIMTokenResponse{}
+	for rows.Next() {
+		var token SCIMTokenResponse
+		if err := rows.Scan(&token.ID, &token.Description, &token.CreatedAt, &token.LastUsedAt); err != nil {
+			log.Printf("Failed to parse SCIM token: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to parse tokens"})
+		}
+		tokens = append(tokens, token)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SCIMTokensListResponse{Tokens: tokens})
+}
+
+// DeleteSCIMToken godoc
+// @Summary Revoke a SCIM provisioning token
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param tokenId path string true "Token ID"
+// @Success 200 {object} MessageResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/organizations/{id}/scim-tokens/{tokenId} [delete]
+func DeleteSCIMToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	organizationID := c.Params("id")
+
+	if !isOrganizationAdmin(organizationID, userID) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{Error: "Only organization admins can manage SCIM tokens"})
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM scim_tokens WHERE id = $1 AND organization_id = $2",
+		c.Params("tokenId"),
+		organizationID,
+	)
+	if err != nil || result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Token not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Token revoked successfully"})
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/config"
+	"email-signature-backend/database"
+	"email-signature-backend/export"
+	"email-signature-backend/tracking"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// FollowLink godoc
+// @Summary Follow a tracked link
+// @Description Public redirect behind every tracked link in exported signatures. Records the click with the requester's IP address (as the privacy mode in CLICK_IP_MODE stores it), user agent, referrer, location, device and A/B test variant, classified as human, bot or scanner traffic, then redirects to the link's destination with UTM parameters added. Once the link has expired, clicks are not recorded and the redirect goes to its fallback URL, or to an expired page without one. Requests with Do-Not-Track or Global Privacy Control are counted without IP address, user agent, referrer, location or device. utm_source and utm_medium default to email_signature and email, utm_campaign to the banner's campaign and utm_content to the variant; the link's and signature's UTM settings override these. Links are addressed by short code; links exported before short codes existed are addressed by ID. Unknown codes get a 404 page.
+// @Tags Links
+// @Param code path string true "Link code"
+// @Param v query string false "A/B test variant the signature was exported with"
+// @Param sent query int false "When the message was sent, in unix seconds; clicks within seconds of it count as scanners"
+// @Success 302 "Redirect to the link's destination"
+// @Failure 404 {string} string "Link not found page"
+// @Failure 410 {string} string "Link expired page"
+// @Router /r/{code} [get]
+func FollowLink(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if _, err := uuid.Parse(code); err == nil {
+		return followLink(c, "l.id = $1", code)
+	}
+	return followLink(c, "l.code = $1", code)
+}
+
+// FollowVanityLink godoc
+// @Summary Follow a vanity link
+// @Description Public redirect for links with a vanity slug, addressed by the organization's link prefix and the slug. Records the click like /r/{code}.
+// @Tags Links
+// @Param prefix path string true "Organization link prefix"
+// @Param slug path string true "Vanity slug"
+// @Param v query string false "A/B test variant the signature was exported with"
+// @Param sent query int false "When the message was sent, in unix seconds"
+// @Success 302 "Redirect to the link's destination"
+// @Failure 404 {string} string "Link not found page"
+// @Failure 410 {string} string "Link expired page"
+// @Router /r/{prefix}/{slug} [get]
+func FollowVanityLink(c *fiber.Ctx) error {
+	return followLink(c, "o.link_prefix = $1 AND l.slug = $2", c.Params("prefix"), strings.ToLower(c.Params("slug")))
+}
+
+// followLink records a click on the link matching the condition and
+// redirects to its destination, or to its fallback without recording
+// anything once it has expired
+func followLink(c *fiber.Ctx, condition string, args ...interface{}) error {
+	link, err := loadTrackedLink(condition, args...)
+	if err != nil {
+		return linkNotFound(c)
+	}
+
+	// Clicks on expired links are not recorded, so they neither show in
+	// analytics nor count towards the click limit
+	if link.Expired {
+		if link.Fallback == nil {
+			return linkExpiredPage(c)
+		}
+		return c.Redirect(link.UTM.Apply(*link.Fallback), fiber.StatusFound)
+	}
+
+	utm := link.UTM
+	variantName, err := recordClick(c, link, c.Query("v"), sentAt(int64(c.QueryInt("sent"))))
+	if err != nil {
+		// A lost click must not break the recipient's navigation
+		log.Printf("Failed to record click: %v\n", err)
+	}
+	if variantName != nil {
+		utm = utm.Or(tracking.UTM{Content: *variantName})
+	}
+	return c.Redirect(utm.Apply(link.Destination), fiber.StatusFound)
+}
+
+// trackedLink is a link as clicks on it are recorded and redirected
+type trackedLink struct {
+	ID          string
+	SignatureID string
+	Destination string
+	Fallback    *string
+	Expired     bool
+	// UTM merges the link's, campaign's and signature's UTM parameters
+	// with the defaults
+	UTM tracking.UTM
+}
+
+// loadTrackedLink finds the link matching the condition
+func loadTrackedLink(condition string, args ...interface{}) (*trackedLink, error) {
+	link := &trackedLink{}
+	var campaignName string
+	var linkUTM, signatureUTM tracking.UTM
+	err := database.DB.QueryRow(
+		context.Background(),
+		"SELECT l.id, COALESCE(l.signature_id::text, ''), l.url, l.fallback_url, "+linkExpired+`, l.utm, COALESCE(s.utm, '{}'), COALESCE(ca.name, '')`+linkFrom+`
+         LEFT JOIN signatures s ON s.id = l.signature_id
+         LEFT JOIN campaigns ca ON ca.id = l.campaign_id
+         WHERE `+condition,
+		args...,
+	).Scan(&link.ID, &link.SignatureID, &link.Destination, &link.Fallback, &link.Expired, &linkUTM, &signatureUTM, &campaignName)
+	if err != nil {
+		return nil, err
+	}
+	link.UTM = linkUTM.Or(tracking.UTM{Campaign: campaignName}).Or(signatureUTM).Or(defaultUTM)
+	return link, nil
+}
+
+// recordClick stores a click on the link by the requester, classified
+// and with the visitor's details, and returns the name of the A/B test
+// variant it counts for, if any. variantID is the variant the link was
+// exported with and sent when the message was sent, if known. Every way
+// of reporting a click goes through here, so clicks are counted alike
+// however they arrive.
+func recordClick(c *fiber.Ctx, link *trackedLink, variantID string, sent time.Time) (*string, error) {
+	now := time.Now()
+	traffic := tracking.Classify(c.IP(), c.Get(fiber.HeaderUserAgent)).CheckSendTime(sent, now)
+	visitor, err := requestVisitor(c)
+	if err != nil {
+		log.Printf("Failed to protect IP address: %v\n", err)
+	}
+	var clickID string
+	var variantName *string
+	err = database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO clicks (link_id, ip_address, variant_id, user_agent, referrer, campaign, traffic, traffic_reason,
+             country, region, city, device_type, os, browser, visitor_key)
+         VALUES ($1, $2, `+clickVariant+`, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+         RETURNING id, (SELECT name FROM signature_variants WHERE id = clicks.variant_id)`,
+		link.ID,
+		nullIfEmpty(visitor.IP),
+		variantID,
+		visitor.UserAgent,
+		visitor.Referrer,
+		nullIfEmpty(link.UTM.Campaign),
+		traffic.Traffic,
+		nullIfEmpty(traffic.Reason),
+		nullIfEmpty(visitor.Location.Country),
+		nullIfEmpty(visitor.Location.Region),
+		nullIfEmpty(visitor.Location.City),
+		nullIfEmpty(visitor.Device.Type),
+		nullIfEmpty(visitor.Device.OS),
+		nullIfEmpty(visitor.Device.Browser),
+		nullIfEmpty(visitor.Key),
+	).Scan(&clickID, &variantName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bursts are told by the IP address as received, whatever the privacy
+	// mode stores
+	if burst := tracking.Bursts.Record(c.IP(), link.SignatureID, link.ID, clickID, now); len(burst) > 0 {
+		if err := flagScanBurst(burst); err != nil {
+			log.Printf("Failed to flag scanner burst: %v\n", err)
+		}
+	}
+	return variantName, nil
+}
+
+// sentAt reads a send time in unix seconds, zero when unknown
+func sentAt(unix int64) time.Time {
+	if unix <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
+// visitor is what is stored about whoever followed a link or loaded a
+// tracking pixel
+type visitor struct {
+	IP string
+	// Key tells the day's repeat clicks by one person apart from others'
+	Key       string
+	UserAgent string
+	Referrer  string
+	Location  tracking.Location
+	Device    tracking.Device
+}
+
+// requestVisitor reads the visitor of a request, with the IP address as
+// the privacy mode stores it. Requests sending Do-Not-Track or Global
+// Privacy Control get an empty visitor, so only the event itself is
+// counted. On error the IP address or key is left out.
+func requestVisitor(c *fiber.Ctx) (visitor, error) {
+	if c.Get("DNT") == "1" || c.Get("Sec-GPC") == "1" {
+		return visitor{}, nil
+	}
+
+	ip, userAgent := c.IP(), c.Get(fiber.HeaderUserAgent)
+	v := visitor{
+		UserAgent: userAgent,
+		Referrer:  c.Get(fiber.HeaderReferer),
+		Location:  tracking.Locate(ip),
+		Device:    tracking.ParseDevice(userAgent),
+	}
+	var err error
+	if v.IP, err = tracking.ProtectIP(context.Background(), ip); err != nil {
+		return v, err
+	}
+	v.Key, err = tracking.VisitorKey(context.Background(), ip, userAgent)
+	return v, err
+}
+
+// flagScanBurst marks clicks found in a burst by tracking.Bursts as a
+// scanner, unless they were classified as a bot or scanner already
+func flagScanBurst(clickIDs []string) error {
+	_, err := database.DB.Exec(
+		context.Background(),
+		"UPDATE clicks SET traffic = $2, traffic_reason = $3 WHERE id = ANY($1::uuid[]) AND traffic = $4",
+		clickIDs,
+		tracking.TrafficScanner,
+		tracking.ReasonBurst,
+		tracking.TrafficHuman,
+	)
+	return err
+}
+
+// defaultUTM tags destinations whose link and signature set no UTM
+// parameters of their own
+var defaultUTM = tracking.UTM{Source: tracking.DefaultUTMSource, Medium: tracking.DefaultUTMMedium}
+
+// trackedLinkURL is the public redirect URL of a link code
+func trackedLinkURL(code string) string {
+	return config.ShortLinkBaseURL() + "/" + code
+}
+
+// vanityLinkURL is the public redirect URL of a vanity slug
+func vanityLinkURL(prefix, slug string) string {
+	return config.ShortLinkBaseURL() + "/" + prefix + "/" + slug
+}
+
+// isTrackedURL reports whether href already goes through a redirect
+func isTrackedURL(href string) bool {
+	for _, prefix := range []string{config.ShortLinkBaseURL() + "/", config.PublicBaseURL() + "/r/", config.PublicBaseURL() + "/c/"} {
+		if strings.HasPrefix(href, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkCodeAttempts is how often a link insert is tried before giving up on
+// random codes that collide with existing ones
+const linkCodeAttempts = 5
+
+// insertLink runs an insert into links, retrying when the code the
+// database generated is already taken
+func insertLink(insert func() error) error {
+	var err error
+	for attempt := 0; attempt < linkCodeAttempts; attempt++ {
+		if err = insert(); !isConstraintViolation(err, "links_code_idx") {
+			return err
+		}
+	}
+	return err
+}
+
+// trackLinks rewrites outbound http(s) links of a signature to tracked
+// redirects, registering a link per destination the first time it is seen.
+// Clicks record the variant, if any.
+func trackLinks(signatureID string, variant *VariantResponse) export.LinkRewriter {
+	tracked := map[string]string{}
+	return func(href string) (string, error) {
+		if !isHTTPURL(href) || isTrackedURL(href) {
+			return href, nil
+		}
+		if trackedURL, ok := tracked[href]; ok {
+			return trackedURL, nil
+		}
+
+		code, err := signatureLink(signatureID, href)
+		if err != nil {
+			return "", err
+		}
+		trackedURL := trackedLinkURL(code)
+		if variant != nil {
+			trackedURL += "?v=" + variant.ID
+		}
+		tracked[href] = trackedURL
+		return trackedURL, nil
+	}
+}
+
+// signatureLink returns the code of the signature's link to the URL,
+// creating the link if there is none yet. Campaign and variant banner
+// links are kept apart, and archived or limited links are not reused.
+func signatureLink(signatureID, url string) (string, error) {
+	var code string
+	err := insertLink(func() error {
+		return database.DB.QueryRow(
+			context.Background(),
+			`WITH existing AS (
+             SELECT code FROM links
+             WHERE signature_id = $1 AND url = $2 AND campaign_id IS NULL AND variant_id IS NULL
+                 AND archived_at IS NULL AND expires_at IS NULL AND max_clicks IS NULL
+             ORDER BY created_at LIMIT 1
+         ), inserted AS (
+             INSERT INTO links (signature_id, url)
+             SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM existing)
+             RETURNING code
+         )
+         SELECT code FROM existing UNION ALL SELECT code FROM inserted`,
+			signatureID,
+			url,
+		).Scan(&code)
+	})
+	return code, err
+}
+
+// linkNotFound serves the page recipients see for unknown links
+func linkNotFound(c *fiber.Ctx) error {
+	return linkPage(c, fiber.StatusNotFound, "Link not found", "This link does not exist or is no longer available.")
+}
+
+// linkExpiredPage serves the page recipients see for expired links
+// without a fallback URL
+func linkExpiredPage(c *fiber.Ctx) error {
+	return linkPage(c, fiber.StatusGone, "Link expired", "This link has expired.")
+}
+
+func linkPage(c *fiber.Ctx, status int, title, message string) error {
+	c.Type("html")
+	return c.Status(status).SendString(`<!DOCTYPE html>
+<html>
+<head>
+    <title>` + title + `</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 0; padding: 80px 20px; background-color: #f9f9f9; color: #444; text-align: center; }
+        h1 { font-size: 22px; color: #222; }
+    </style>
+</head>
+<body>
+    <h1>` + title + `</h1>
+    <p>` + message + `</p>
+</body>
+</html>`)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestFollowExpiredLink checks expired links redirect to their fallback or
+// the expired page without recording clicks, while live links record one
+func TestFollowExpiredLink(t *testing.T) {
+	useTestDatabase(t)
+	userID, _ := testOrganization(t)
+	ctx := context.Background()
+
+	var signatureID string
+	err := database.DB.QueryRow(ctx, "INSERT INTO signatures (user_id, template_data) VALUES ($1, '{}') RETURNING id", userID).Scan(&signatureID)
+	if err != nil {
+		t.Fatalf("insert signature: %v", err)
+	}
+	insertLink := func(expiresAt string, fallback interface{}) (id, code string) {
+		t.Helper()
+		err := database.DB.QueryRow(
+			ctx,
+			"INSERT INTO links (signature_id, url, expires_at, fallback_url) VALUES ($1, 'https://example.com/offer', $2::timestamp, $3) RETURNING id, code",
+			signatureID, expiresAt, fallback,
+		).Scan(&id, &code)
+		if err != nil {
+			t.Fatalf("insert link: %v", err)
+		}
+		t.Cleanup(func() { database.DB.Exec(ctx, "DELETE FROM clicks WHERE link_id = $1", id) })
+		return id, code
+	}
+
+	app := fiber.New()
+	app.Get("/r/:code", FollowLink)
+
+	tests := []struct {
+		name      string
+		expiresAt string
+		fallback  interface{}
+		status    int
+		location  string
+		clicks    int
+	}{
+		{"live", "2999-01-01", nil, fiber.StatusFound, "https://example.com/offer", 1},
+		{"expired with fallback", "2000-01-01", "https://example.com/next", fiber.StatusFound, "https://example.com/next", 0},
+		{"expired", "2000-01-01", nil, fiber.StatusGone, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, code := insertLink(tt.expiresAt, tt.fallback)
+			resp, err := app.Test(httptest.NewRequest("GET", "/r/"+code, nil))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.status || !strings.HasPrefix(resp.Header.Get("Location"), tt.location) {
+				t.Errorf("response = %d to %q, want %d to %q", resp.StatusCode, resp.Header.Get("Location"), tt.status, tt.location)
+			}
+
+			var clicks int
+			if err := database.DB.QueryRow(ctx, "SELECT COUNT(*) FROM clicks WHERE link_id = $1", id).Scan(&clicks); err != nil {
+				t.Fatalf("count clicks: %v", err)
+			}
+			if clicks != tt.clicks {
+				t.Errorf("clicks = %d, want %d", clicks, tt.clicks)
+			}
+		})
+	}
+}
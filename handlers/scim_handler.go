@@ -0,0 +1,1058 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/config"
+	"email-signature-backend/database"
+	"email-signature-backend/scim"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// employee is a person provisioned over SCIM
+type employee struct {
+	ID          string
+	ExternalID  string
+	UserName    string
+	Email       string
+	GivenName   string
+	FamilyName  string
+	DisplayName string
+	Title       string
+	Department  string
+	Phone       string
+	Active      bool
+	SignatureID *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// employeeColumns selects the columns scanEmployee expects
+const employeeColumns = `id, external_id, user_name, email, given_name, family_name, display_name, title, department, phone,
+         active, signature_id, created_at, updated_at`
+
+func scanEmployee(row pgx.Row) (*employee, error) {
+	e := &employee{}
+	err := row.Scan(
+		&e.ID, &e.ExternalID, &e.UserName, &e.Email, &e.GivenName, &e.FamilyName, &e.DisplayName, &e.Title,
+		&e.Department, &e.Phone, &e.Active, &e.SignatureID, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// setUser copies the SCIM user's attributes onto the employee
+func (e *employee) setUser(u *scim.User) {
+	e.UserName = strings.TrimSpace(u.UserName)
+	e.ExternalID = u.ExternalID
+	e.DisplayName = u.DisplayName
+	e.Title = u.Title
+	e.Active = u.IsActive()
+	e.Phone = u.Phone()
+	e.GivenName, e.FamilyName = "", ""
+	if u.Name != nil {
+		e.GivenName, e.FamilyName = u.Name.GivenName, u.Name.FamilyName
+		if e.DisplayName == "" {
+			e.DisplayName = u.Name.Formatted
+		}
+	}
+	e.Department = ""
+	if u.Enterprise != nil {
+		e.Department = u.Enterprise.Department
+	}
+
+	// IdPs commonly use the email address as the user name
+	e.Email = strings.ToLower(strings.TrimSpace(u.Email()))
+	if e.Email == "" && strings.Contains(e.UserName, "@") {
+		e.Email = strings.ToLower(e.UserName)
+	}
+}
+
+// user returns the employee as a SCIM user
+func (e *employee) user(groups []scim.MultiValue) scim.User {
+	active := e.Active
+	u := scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          e.ID,
+		ExternalID:  e.ExternalID,
+		UserName:    e.UserName,
+		DisplayName: e.DisplayName,
+		Title:       e.Title,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      e.CreatedAt,
+			LastModified: e.UpdatedAt,
+			Location:     scimLocation("Users", e.ID),
+		},
+	}
+	if e.GivenName != "" || e.FamilyName != "" {
+		u.Name = &scim.Name{GivenName: e.GivenName, FamilyName: e.FamilyName}
+	}
+	if e.Email != "" {
+		u.Emails = []scim.MultiValue{{Value: e.Email, Type: "work", Primary: true}}
+	}
+	if e.Phone != "" {
+		u.PhoneNumbers = []scim.MultiValue{{Value: e.Phone, Type: "work"}}
+	}
+	if e.Department != "" {
+		u.Schemas = append(u.Schemas, scim.SchemaEnterpriseUser)
+		u.Enterprise = &scim.EnterpriseUser{Department: e.Department}
+	}
+	return u
+}
+
+// fullName is the name shown in the employee's signature
+func (e *employee) fullName() string {
+	if e.DisplayName != "" {
+		return e.DisplayName
+	}
+	if name := strings.TrimSpace(e.GivenName + " " + e.FamilyName); name != "" {
+		return name
+	}
+	return e.UserName
+}
+
+// templateData sets the employee's details on a signature's template data,
+// keeping fields the IdP does not manage such as social links
+func (e *employee) templateData(current map[string]interface{}, company string) map[string]interface{} {
+	data := map[string]interface{}{}
+	for key, value := range current {
+		data[key] = value
+	}
+
+	fields := map[string]string{
+		"email":      e.Email,
+		"name":       e.fullName(),
+		"job_title":  e.Title,
+		"company":    company,
+		"phone":      e.Phone,
+		"department": e.Department,
+	}
+	for field, value := range fields {
+		if value == "" {
+			delete(data, field)
+		} else {
+			data[field] = value
+		}
+	}
+	return data
+}
+
+// scimGroup is a group provisioned over SCIM
+type scimGroup struct {
+	ID          string
+	ExternalID  string
+	DisplayName string
+	Members     []scim.MultiValue
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (g *scimGroup) group() scim.Group {
+	members := g.Members
+	if members == nil {
+		members = []scim.MultiValue{}
+	}
+	return scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          g.ID,
+		ExternalID:  g.ExternalID,
+		DisplayName: g.DisplayName,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+			Location:     scimLocation("Groups", g.ID),
+		},
+	}
+}
+
+// GetSCIMServiceProviderConfig godoc
+// @Summary SCIM service provider configuration
+// @Description Describes the SCIM features supported: PATCH and equality filters on userName, externalId and emails for users, and displayName and externalId for groups
+// @Tags SCIM
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /scim/v2/ServiceProviderConfig [get]
+func GetSCIMServiceProviderConfig(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"schemas":        []string{scim.SchemaServiceConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": scim.MaxResults},
+		"changePassword": fiber.Map{"supported": false},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Organization SCIM token created by an organization admin",
+		}},
+	}, scim.ContentType)
+}
+
+// ListSCIMUsers godoc
+// @Summary List provisioned employees
+// @Description Lists the organization's employees as SCIM users, optionally filtered with an equality filter on userName, externalId or emails.value
+// @Tags SCIM
+// @Produce json
+// @Param filter query string false "Filter, e.g. userName eq \"jane@example.com\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size (max 200)"
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} scim.Error
+// @Failure 401 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users [get]
+func ListSCIMUsers(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	filter, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidFilter, err.Error())
+	}
+
+	where := "organization_id = $1"
+	args := []interface{}{organizationID}
+	if filter != nil {
+		switch strings.ToLower(filter.Attribute) {
+		case "username":
+			where += " AND LOWER(user_name) = LOWER($2)"
+		case "externalid":
+			where += " AND external_id = $2"
+		case "emails", "emails.value":
+			where += " AND email = LOWER($2)"
+		default:
+			return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidFilter, fmt.Sprintf("cannot filter on %s", filter.Attribute))
+		}
+		args = append(args, filter.Value)
+	}
+
+	startIndex, count := scimPage(c)
+	ctx := context.Background()
+
+	var total int
+	if err := database.DB.QueryRow(ctx, "SELECT COUNT(*) FROM employees WHERE "+where, args...).Scan(&total); err != nil {
+		log.Printf("Failed to count employees: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch users")
+	}
+
+	rows, err := database.DB.Query(
+		ctx,
+		fmt.Sprintf("SELECT %s FROM employees WHERE %s ORDER BY created_at, id LIMIT %d OFFSET %d", employeeColumns, where, count, startIndex-1),
+		args...,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch employees: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch users")
+	}
+	employees, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*employee, error) {
+		return scanEmployee(row)
+	})
+	if err != nil {
+		log.Printf("Failed to parse employees: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch users")
+	}
+
+	ids := make([]string, 0, len(employees))
+	for _, e := range employees {
+		ids = append(ids, e.ID)
+	}
+	groups, err := employeeGroups(ctx, ids)
+	if err != nil {
+		log.Printf("Failed to fetch employee groups: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch users")
+	}
+
+	users := make([]scim.User, 0, len(employees))
+	for _, e := range employees {
+		users = append(users, e.user(groups[e.ID]))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(scim.NewListResponse(users, total, startIndex, len(users)), scim.ContentType)
+}
+
+// GetSCIMUser godoc
+// @Summary Get a provisioned employee
+// @Tags SCIM
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} scim.User
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [get]
+func GetSCIMUser(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	e, err := loadEmployee(context.Background(), database.DB, organizationID, c.Params("id"))
+	if err != nil {
+		return scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+
+	return scimUserResponse(c, fiber.StatusOK, e)
+}
+
+// CreateSCIMUser godoc
+// @Summary Provision an employee
+// @Description Creates an employee and generates their signature with the organization's default template and brand kit. Inactive users are stored without a signature.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Param request body scim.User true "SCIM user"
+// @Success 201 {object} scim.User
+// @Failure 400 {object} scim.Error
+// @Failure 409 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users [post]
+func CreateSCIMUser(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+	ownerID := c.Locals("user_id").(string)
+
+	var u scim.User
+	if err := json.Unmarshal(c.Body(), &u); err != nil || strings.TrimSpace(u.UserName) == "" {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidSyntax, "userName is required")
+	}
+	e := &employee{}
+	e.setUser(&u)
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create user")
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(
+		ctx,
+		`INSERT INTO employees (organization_id, external_id, user_name, email, given_name, family_name, display_name, title, department, phone, active)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+         ON CONFLICT (organization_id, user_name) DO NOTHING
+         RETURNING `+employeeColumns,
+		organizationID, e.ExternalID, e.UserName, e.Email, e.GivenName, e.FamilyName, e.DisplayName, e.Title, e.Department, e.Phone, e.Active,
+	)
+	created, err := scanEmployee(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scimError(c, fiber.StatusConflict, scim.ErrorUniqueness, "A user with this userName already exists")
+	}
+	if err != nil {
+		log.Printf("Failed to insert employee: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create user")
+	}
+
+	if err := syncEmployeeSignature(ctx, tx, organizationID, ownerID, created); err != nil {
+		log.Printf("Failed to generate employee signature: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create user")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Failed to commit transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create user")
+	}
+	lintEmployeeSignature(created)
+
+	c.Location(scimLocation("Users", created.ID))
+	return scimUserResponse(c, fiber.StatusCreated, created)
+}
+
+// ReplaceSCIMUser godoc
+// @Summary Replace a provisioned employee
+// @Description Replaces the employee's attributes and regenerates their signature; setting active to false archives it
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body scim.User true "SCIM user"
+// @Success 200 {object} scim.User
+// @Failure 400 {object} scim.Error
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [put]
+func ReplaceSCIMUser(c *fiber.Ctx) error {
+	var u scim.User
+	if err := json.Unmarshal(c.Body(), &u); err != nil || strings.TrimSpace(u.UserName) == "" {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidSyntax, "userName is required")
+	}
+
+	return updateSCIMUser(c, func(e *employee) error {
+		e.setUser(&u)
+		return nil
+	})
+}
+
+// PatchSCIMUser godoc
+// @Summary Update a provisioned employee
+// @Description Applies SCIM PATCH operations. Replacing active with false deactivates the employee and archives their signature; reactivating restores it.
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body scim.PatchRequest true "PATCH operations"
+// @Success 200 {object} scim.User
+// @Failure 400 {object} scim.Error
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [patch]
+func PatchSCIMUser(c *fiber.Ctx) error {
+	var patch scim.PatchRequest
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidSyntax, "Invalid PATCH request")
+	}
+
+	return updateSCIMUser(c, func(e *employee) error {
+		u := e.user(nil)
+		if err := u.Apply(patch.Operations); err != nil {
+			return err
+		}
+		if strings.TrimSpace(u.UserName) == "" {
+			return &scim.PatchError{ScimType: scim.ErrorInvalidValue, Detail: "userName cannot be removed"}
+		}
+		e.setUser(&u)
+		return nil
+	})
+}
+
+// updateSCIMUser loads the employee, applies the change and saves it along
+// with their signature
+func updateSCIMUser(c *fiber.Ctx, change func(*employee) error) error {
+	organizationID := c.Locals("organization_id").(string)
+	ownerID := c.Locals("user_id").(string)
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update user")
+	}
+	defer tx.Rollback(ctx)
+
+	e, err := loadEmployee(ctx, tx, organizationID, c.Params("id"))
+	if err != nil {
+		return scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+
+	var patchErr *scim.PatchError
+	if err := change(e); errors.As(err, &patchErr) {
+		return scimError(c, fiber.StatusBadRequest, patchErr.ScimType, patchErr.Detail)
+	} else if err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidValue, err.Error())
+	}
+
+	row := tx.QueryRow(
+		ctx,
+		`UPDATE employees SET external_id = $1, user_name = $2, email = $3, given_name = $4, family_name = $5,
+             display_name = $6, title = $7, department = $8, phone = $9, active = $10, updated_at = NOW()
+         WHERE id = $11
+         RETURNING `+employeeColumns,
+		e.ExternalID, e.UserName, e.Email, e.GivenName, e.FamilyName, e.DisplayName, e.Title, e.Department, e.Phone, e.Active, e.ID,
+	)
+	updated, err := scanEmployee(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return scimError(c, fiber.StatusConflict, scim.ErrorUniqueness, "A user with this userName already exists")
+		}
+		log.Printf("Failed to update employee: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update user")
+	}
+
+	if err := syncEmployeeSignature(ctx, tx, organizationID, ownerID, updated); err != nil {
+		log.Printf("Failed to update employee signature: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update user")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Failed to commit transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update user")
+	}
+	lintEmployeeSignature(updated)
+
+	return scimUserResponse(c, fiber.StatusOK, updated)
+}
+
+// DeleteSCIMUser godoc
+// @Summary Deprovision an employee
+// @Description Removes the employee and archives their signature
+// @Tags SCIM
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [delete]
+func DeleteSCIMUser(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to delete user")
+	}
+	defer tx.Rollback(ctx)
+
+	var signatureID *string
+	err = tx.QueryRow(
+		ctx,
+		"DELETE FROM employees WHERE id = $1 AND organization_id = $2 RETURNING signature_id",
+		c.Params("id"),
+		organizationID,
+	).Scan(&signatureID)
+	if err != nil {
+		return scimError(c, fiber.StatusNotFound, "", "User not found")
+	}
+
+	if signatureID != nil {
+		if _, err := tx.Exec(ctx, "UPDATE signatures SET archived_at = COALESCE(archived_at, NOW()) WHERE id = $1", *signatureID); err != nil {
+			log.Printf("Failed to archive employee signature: %v\n", err)
+			return scimError(c, fiber.StatusInternalServerError, "", "Failed to delete user")
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Failed to commit transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to delete user")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListSCIMGroups godoc
+// @Summary List provisioned groups
+// @Description Lists the organization's groups, optionally filtered with an equality filter on displayName or externalId
+// @Tags SCIM
+// @Produce json
+// @Param filter query string false "Filter, e.g. displayName eq \"Sales\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size (max 200)"
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Groups [get]
+func ListSCIMGroups(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	filter, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidFilter, err.Error())
+	}
+
+	where := "organization_id = $1"
+	args := []interface{}{organizationID}
+	if filter != nil {
+		switch strings.ToLower(filter.Attribute) {
+		case "displayname":
+			where += " AND display_name = $2"
+		case "externalid":
+			where += " AND external_id = $2"
+		default:
+			return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidFilter, fmt.Sprintf("cannot filter on %s", filter.Attribute))
+		}
+		args = append(args, filter.Value)
+	}
+
+	startIndex, count := scimPage(c)
+	ctx := context.Background()
+
+	var total int
+	if err := database.DB.QueryRow(ctx, "SELECT COUNT(*) FROM scim_groups WHERE "+where, args...).Scan(&total); err != nil {
+		log.Printf("Failed to count groups: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch groups")
+	}
+
+	rows, err := database.DB.Query(
+		ctx,
+		fmt.Sprintf("SELECT %s FROM scim_groups WHERE %s ORDER BY created_at, id LIMIT %d OFFSET %d", scimGroupColumns, where, count, startIndex-1),
+		args...,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch groups: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch groups")
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*scimGroup, error) {
+		return scanSCIMGroup(row)
+	})
+	if err != nil {
+		log.Printf("Failed to parse groups: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch groups")
+	}
+
+	groups := make([]scim.Group, 0, len(list))
+	for _, g := range list {
+		if g.Members, err = groupMembers(ctx, database.DB, g.ID); err != nil {
+			log.Printf("Failed to fetch group members: %v\n", err)
+			return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch groups")
+		}
+		groups = append(groups, g.group())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(scim.NewListResponse(groups, total, startIndex, len(groups)), scim.ContentType)
+}
+
+// GetSCIMGroup godoc
+// @Summary Get a provisioned group
+// @Tags SCIM
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} scim.Group
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [get]
+func GetSCIMGroup(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	g, err := loadSCIMGroup(context.Background(), database.DB, organizationID, c.Params("id"))
+	if err != nil {
+		return scimError(c, fiber.StatusNotFound, "", "Group not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(g.group(), scim.ContentType)
+}
+
+// CreateSCIMGroup godoc
+// @Summary Provision a group
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Param request body scim.Group true "SCIM group"
+// @Success 201 {object} scim.Group
+// @Failure 400 {object} scim.Error
+// @Failure 409 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Groups [post]
+func CreateSCIMGroup(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	var g scim.Group
+	if err := json.Unmarshal(c.Body(), &g); err != nil || strings.TrimSpace(g.DisplayName) == "" {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidSyntax, "displayName is required")
+	}
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create group")
+	}
+	defer tx.Rollback(ctx)
+
+	var groupID string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO scim_groups (organization_id, external_id, display_name) VALUES ($1, $2, $3)
+         ON CONFLICT (organization_id, display_name) DO NOTHING
+         RETURNING id`,
+		organizationID,
+		g.ExternalID,
+		strings.TrimSpace(g.DisplayName),
+	).Scan(&groupID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scimError(c, fiber.StatusConflict, scim.ErrorUniqueness, "A group with this displayName already exists")
+	}
+	if err != nil {
+		log.Printf("Failed to insert group: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create group")
+	}
+
+	if err := setGroupMembers(ctx, tx, organizationID, groupID, g.Members); err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidValue, err.Error())
+	}
+
+	created, err := loadSCIMGroup(ctx, tx, organizationID, groupID)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to create group: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to create group")
+	}
+
+	c.Location(scimLocation("Groups", created.ID))
+	return c.Status(fiber.StatusCreated).JSON(created.group(), scim.ContentType)
+}
+
+// PatchSCIMGroup godoc
+// @Summary Update a provisioned group
+// @Description Applies SCIM PATCH operations to the group's displayName and members
+// @Tags SCIM
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param request body scim.PatchRequest true "PATCH operations"
+// @Success 200 {object} scim.Group
+// @Failure 400 {object} scim.Error
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [patch]
+func PatchSCIMGroup(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	var patch scim.PatchRequest
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidSyntax, "Invalid PATCH request")
+	}
+
+	ctx := context.Background()
+	tx, err := database.DB.Begin(ctx)
+	if err != nil {
+		log.Printf("Failed to start transaction: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update group")
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := loadSCIMGroup(ctx, tx, organizationID, c.Params("id"))
+	if err != nil {
+		return scimError(c, fiber.StatusNotFound, "", "Group not found")
+	}
+
+	g := current.group()
+	var patchErr *scim.PatchError
+	if err := g.Apply(patch.Operations); errors.As(err, &patchErr) {
+		return scimError(c, fiber.StatusBadRequest, patchErr.ScimType, patchErr.Detail)
+	} else if err != nil || strings.TrimSpace(g.DisplayName) == "" {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidValue, "displayName is required")
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE scim_groups SET display_name = $1, updated_at = NOW() WHERE id = $2",
+		strings.TrimSpace(g.DisplayName),
+		current.ID,
+	)
+	if isUniqueViolation(err) {
+		return scimError(c, fiber.StatusConflict, scim.ErrorUniqueness, "A group with this displayName already exists")
+	}
+	if err != nil {
+		log.Printf("Failed to update group: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update group")
+	}
+	if err := setGroupMembers(ctx, tx, organizationID, current.ID, g.Members); err != nil {
+		return scimError(c, fiber.StatusBadRequest, scim.ErrorInvalidValue, err.Error())
+	}
+
+	updated, err := loadSCIMGroup(ctx, tx, organizationID, current.ID)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to update group: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to update group")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated.group(), scim.ContentType)
+}
+
+// DeleteSCIMGroup godoc
+// @Summary Delete a provisioned group
+// @Description Deletes the group; its members are not affected
+// @Tags SCIM
+// @Param id path string true "Group ID"
+// @Success 204
+// @Failure 404 {object} scim.Error
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [delete]
+func DeleteSCIMGroup(c *fiber.Ctx) error {
+	organizationID := c.Locals("organization_id").(string)
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM scim_groups WHERE id = $1 AND organization_id = $2",
+		c.Params("id"),
+		organizationID,
+	)
+	if err != nil || result.RowsAffected() == 0 {
+		return scimError(c, fiber.StatusNotFound, "", "Group not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// querier is satisfied by both the pool and transactions
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func loadEmployee(ctx context.Context, q querier, organizationID, employeeID string) (*employee, error) {
+	if _, err := uuid.Parse(employeeID); err != nil {
+		return nil, err
+	}
+	return scanEmployee(q.QueryRow(
+		ctx,
+		"SELECT "+employeeColumns+" FROM employees WHERE id = $1 AND organization_id = $2",
+		employeeID,
+		organizationID,
+	))
+}
+
+// employeeGroups returns the groups of each employee, keyed by employee ID
+func employeeGroups(ctx context.Context, employeeIDs []string) (map[string][]scim.MultiValue, error) {
+	rows, err := database.DB.Query(
+		ctx,
+		`SELECT m.employee_id, g.id, g.display_name
+         FROM scim_group_members m
+         JOIN scim_groups g ON g.id = m.group_id
+         WHERE m.employee_id = ANY($1)
+         ORDER BY g.display_name`,
+		employeeIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := map[string][]scim.MultiValue{}
+	for rows.Next() {
+		var employeeID string
+		var group scim.MultiValue
+		if err := rows.Scan(&employeeID, &group.Value, &group.Display); err != nil {
+			return nil, err
+		}
+		groups[employeeID] = append(groups[employeeID], group)
+	}
+	return groups, rows.Err()
+}
+
+// scimUserResponse writes the employee, with their groups, as a SCIM user
+func scimUserResponse(c *fiber.Ctx, status int, e *employee) error {
+	groups, err := employeeGroups(context.Background(), []string{e.ID})
+	if err != nil {
+		log.Printf("Failed to fetch employee groups: %v\n", err)
+		return scimError(c, fiber.StatusInternalServerError, "", "Failed to fetch user")
+	}
+	return c.Status(status).JSON(e.user(groups[e.ID]), scim.ContentType)
+}
+
+// syncEmployeeSignature keeps the employee's signature in step with their
+// SCIM record. Active employees get a signature generated with the
+// organization's default template and brand kit, or their existing one
+// updated; inactive employees have theirs archived.
+func syncEmployeeSignature(ctx context.Context, tx pgx.Tx, organizationID, ownerID string, e *employee) error {
+	var company, defaultTemplate string
+	var defaultBrandKitID *string
+	err := tx.QueryRow(
+		ctx,
+		"SELECT name, default_template, default_brand_kit_id FROM organizations WHERE id = $1",
+		organizationID,
+	).Scan(&company, &defaultTemplate, &defaultBrandKitID)
+	if err != nil {
+		return err
+	}
+
+	// Find the signature, adopting one already made for the same email in
+	// the organization or by its owner, whose roster imports and
+	// directory syncs leave the organization unset
+	var signatureID *string
+	var current map[string]interface{}
+	err = tx.QueryRow(
+		ctx,
+		`SELECT id, template_data FROM signatures
+         WHERE id = $1
+            OR ((organization_id = $2 OR user_id = $4) AND LOWER(employee_email) = LOWER($3) AND $3 <> '')
+         ORDER BY id = $1 DESC, user_id = $4 DESC
+         LIMIT 1`,
+		e.SignatureID,
+		organizationID,
+		e.Email,
+		ownerID,
+	).Scan(&signatureID, &current)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	switch {
+	case signatureID != nil && !e.Active:
+		_, err = tx.Exec(ctx, "UPDATE signatures SET archived_at = COALESCE(archived_at, NOW()) WHERE id = $1", *signatureID)
+	case signatureID != nil:
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE signatures SET template_data = $1, employee_email = NULLIF($2, ''), organization_id = $3, archived_at = NULL
+             WHERE id = $4`,
+			e.templateData(current, company),
+			e.Email,
+			organizationID,
+			*signatureID,
+		)
+	case e.Active && e.Email != "":
+		err = tx.QueryRow(
+			ctx,
+			`INSERT INTO signatures (user_id, organization_id, employee_email, template, brand_kit_id, template_data)
+             VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			ownerID,
+			organizationID,
+			e.Email,
+			defaultTemplate,
+			defaultBrandKitID,
+			e.templateData(nil, company),
+		).Scan(&signatureID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if signatureID != nil && (e.SignatureID == nil || *e.SignatureID != *signatureID) {
+		e.SignatureID = signatureID
+		_, err = tx.Exec(ctx, "UPDATE employees SET signature_id = $1 WHERE id = $2", *signatureID, e.ID)
+	}
+	return err
+}
+
+// lintEmployeeSignature lints the signature provisioning just generated or
+// updated. The identity provider has no use for the report, so the errors
+// are only logged.
+func lintEmployeeSignature(e *employee) {
+	if !e.Active || e.SignatureID == nil {
+		return
+	}
+	if _, err := LintSavedSignature(*e.SignatureID); err != nil {
+		log.Printf("Failed to lint employee signature %s: %v\n", *e.SignatureID, err)
+	}
+}
+
+// scimGroupColumns selects the columns scanSCIMGroup expects
+const scimGroupColumns = "id, external_id, display_name, created_at, updated_at"
+
+func scanSCIMGroup(row pgx.Row) (*scimGroup, error) {
+	g := &scimGroup{}
+	if err := row.Scan(&g.ID, &g.ExternalID, &g.DisplayName, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func loadSCIMGroup(ctx context.Context, q querier, organizationID, groupID string) (*scimGroup, error) {
+	if _, err := uuid.Parse(groupID); err != nil {
+		return nil, err
+	}
+	g, err := scanSCIMGroup(q.QueryRow(
+		ctx,
+		"SELECT "+scimGroupColumns+" FROM scim_groups WHERE id = $1 AND organization_id = $2",
+		groupID,
+		organizationID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	g.Members, err = groupMembers(ctx, q, groupID)
+	return g, err
+}
+
+func groupMembers(ctx context.Context, q querier, groupID string) ([]scim.MultiValue, error) {
+	rows, err := q.Query(
+		ctx,
+		`SELECT e.id, CASE WHEN e.display_name <> '' THEN e.display_name ELSE e.user_name END
+         FROM scim_group_members m
+         JOIN employees e ON e.id = m.employee_id
+         WHERE m.group_id = $1
+         ORDER BY e.user_name`,
+		groupID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []scim.MultiValue{}
+	for rows.Next() {
+		var member scim.MultiValue
+		if err := rows.Scan(&member.Value, &member.Display); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// setGroupMembers replaces the group's members; every member must be an
+// employee of the organization
+func setGroupMembers(ctx context.Context, tx pgx.Tx, organizationID, groupID string, members []scim.MultiValue) error {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if _, err := uuid.Parse(member.Value); err != nil {
+			return fmt.Errorf("unknown member %q", member.Value)
+		}
+		ids = append(ids, member.Value)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM scim_group_members WHERE group_id = $1", groupID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(
+		ctx,
+		`INSERT INTO scim_group_members (group_id, employee_id)
+         SELECT $1, id FROM employees WHERE organization_id = $2 AND id = ANY($3)`,
+		groupID,
+		organizationID,
+		ids,
+	)
+	if err != nil {
+		return err
+	}
+
+	unique := map[string]bool{}
+	for _, id := range ids {
+		unique[strings.ToLower(id)] = true
+	}
+	if int(result.RowsAffected()) != len(unique) {
+		return errors.New("members must be users of this organization")
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isConstraintViolation reports whether err violates the named constraint
+// or unique index
+func isConstraintViolation(err error, name string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.ConstraintName == name
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// scimPage reads the 1-based startIndex and count query parameters
+func scimPage(c *fiber.Ctx) (int, int) {
+	startIndex := c.QueryInt("startIndex", 1)
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := c.QueryInt("count", scim.MaxResults)
+	if count < 0 {
+		count = 0
+	}
+	if count > scim.MaxResults {
+		count = scim.MaxResults
+	}
+	return startIndex, count
+}
+
+func scimLocation(resource, id string) string {
+	return config.PublicBaseURL() + "/scim/v2/" + resource + "/" + id
+}
+
+func scimError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return c.Status(status).JSON(scim.Error{
+		Schemas:  []string{scim.SchemaError},
+		Status:   fmt.Sprint(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}, scim.ContentType)
+}
@@ -3,12 +3,21 @@ package handlers
 import (
 	"context"
 	"email-signature-backend/database"
+	"email-signature-backend/export"
+	"email-signature-backend/master"
+	"email-signature-backend/social"
+	"email-signature-backend/templates"
 	"fmt"
+	"html"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type SignatureResponse struct {
@@ -19,6 +28,7 @@ type SignatureResponse struct {
 }
 type SignatureRequest struct {
 	TemplateData map[string]interface{} `json:"template_data"`
+	BrandKitID   string                 `json:"brand_kit_id"`
 }
 type SignaturesListResponse struct {
 	Signatures []SignatureResponse `json:"signatures"`
@@ -34,7 +44,7 @@ type ErrorResponse struct {
 
 // CreateSignature godoc
 // @Summary Create a new email signature
-// @Description Allows an authenticated user to create a new email signature
+// @Description Allows an authenticated user to create a new email signature. The response includes a compatibility lint report for every template.
 // @Tags Signatures
 // @Accept json
 // @Produce json
@@ -55,16 +65,45 @@ func CreateSignature(c *fiber.Ctx) error {
 		})
 	}
 
+	// Validate social profile URLs against the network catalog
+	if problems := social.Validate(req.TemplateData); len(problems) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid social links",
+			"details": problems,
+		})
+	}
+
+	// Validate the locale and translated field values
+	if problems := templates.ValidateLocalization(req.TemplateData); len(problems) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid localization",
+			"details": problems,
+		})
+	}
+
+	// Resolve the optional brand kit, which must be accessible to the user
+	var brandKitID interface{}
+	if req.BrandKitID != "" {
+		brandKit, err := loadBrandKit(req.BrandKitID, userID, false)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Brand kit not found",
+			})
+		}
+		brandKitID = brandKit.ID
+	}
+
 	// Generate a new signature ID
 	signatureID := uuid.New()
 
 	// Insert the signature into the database
 	_, err := database.DB.Exec(
 		context.Background(),
-		"INSERT INTO signatures (id, user_id, template_data) VALUES ($1, $2, $3)",
+		"INSERT INTO signatures (id, user_id, template_data, brand_kit_id) VALUES ($1, $2, $3, $4)",
 		signatureID,
 		userID,
 		req.TemplateData,
+		brandKitID,
 	)
 	if err != nil {
 		log.Printf("Failed to insert signature: %v\n", err)
@@ -73,20 +112,31 @@ func CreateSignature(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	// Lint the saved signature against every template so compatibility
+	// problems surface immediately
+	return c.Status(fiber.StatusCreated).JSON(withLint(fiber.Map{
 		"message":      "Signature created successfully",
 		"signature_id": signatureID,
-	})
+	}, signatureID.String()))
 }
 
 // ExportSignature godoc
 // @Summary Export an email signature as HTML
-// @Description Generates an HTML version of the specified signature for email clients
+// @Description Generates an HTML version of the specified signature for email clients, including the banner of any campaign currently targeting it and, when open tracking is on, a tracking pixel. Styles are inlined and the markup minified; the X-Signature-Original-Bytes and X-Signature-Bytes headers report the size before and after.
 // @Tags Signatures
 // @Param id path string true "Signature ID"
-// @Param template query string false "Template type (basic or modern)"
+// @Param template query string false "Template type (basic or modern), defaulting to the signature's template"
+// @Param recipient query string false "Recipient address; each recipient always gets the same A/B test variant"
+// @Param locale query string false "Locale for content rules, defaulting to the signature's locale"
+// @Param region query string false "Region for content rules, defaulting to the signature's region"
+// @Param audience query string false "Audience for content rules (internal or external, the default)"
+// @Param track query bool false "Route every outbound link through a tracked redirect, registering links as needed"
 // @Produce html
 // @Success 200 {string} string "HTML representation of the signature"
+// @Header 200 {integer} X-Signature-Original-Bytes "Size of the rendered HTML before post-processing"
+// @Header 200 {integer} X-Signature-Bytes "Size of the exported HTML"
+// @Header 200 {integer} X-Signature-Lint-Errors "Number of email-client compatibility errors in the exported HTML, see /api/signature/{id}/lint"
+// @Header 200 {string} X-Signature-Variant "A/B test variant served, if the signature has variants"
 // @Failure 404 {object} map[string]interface{} "Signature not found"
 // @Failure 500 {object} map[string]interface{} "Failed to generate HTML"
 // @Security BearerAuth
@@ -98,17 +148,8 @@ func ExportSignature(c *fiber.Ctx) error {
 	// Get signature_id from URL params
 	signatureID := c.Params("id")
 
-	// Optional: Get template type from query params (default to "basic")
-	templateType := c.Query("template", "basic")
-
-	// Fetch the signature data from the database
-	var templateData map[string]interface{}
-	err := database.DB.QueryRow(
-		context.Background(),
-		"SELECT template_data FROM signatures WHERE id = $1 AND user_id = $2",
-		signatureID,
-		userID,
-	).Scan(&templateData)
+	// Fetch the signature and its brand kit from the database
+	signature, err := loadSignature(signatureID, userID)
 	if err != nil {
 		log.Printf("Failed to fetch signature: %v\n", err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -116,25 +157,55 @@ func ExportSignature(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate HTML based on template type
-	var html string
-	switch templateType {
-	case "modern":
-		html = renderModernHTML(templateData)
-	default:
-		html = renderBasicHTML(templateData)
+	// Serve one of the signature's A/B test variants, if it has any
+	variant, err := assignVariant(signature.ID, c.Query("recipient"))
+	if err != nil {
+		log.Printf("Failed to assign variant: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate HTML",
+		})
+	}
+	stored := signature.Template
+	if variant != nil {
+		c.Set("X-Signature-Variant", variant.ID)
+		if variant.Template != "" {
+			stored = variant.Template
+		}
+	}
+
+	// Optional: Get template type from query params, else the signature's own
+	templateType := templateName(c, stored)
+
+	// Generate HTML based on template type, ready for email clients
+	ctx := requestContentContext(c, signature, templates.FormatHTML)
+	result, err := exportSignature(signature, templateType, variant, ctx, c.QueryBool("track"))
+	if err != nil {
+		log.Printf("Failed to export signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate HTML",
+		})
 	}
 
+	// Report the size reduction and compatibility problems alongside the
+	// HTML
+	c.Set("X-Signature-Original-Bytes", strconv.Itoa(result.OriginalBytes))
+	c.Set("X-Signature-Bytes", strconv.Itoa(result.FinalBytes))
+	c.Set("X-Signature-Lint-Errors", strconv.Itoa(lintExport(signature.ID, result.HTML).Errors))
+
 	// Return the HTML as a response
-	return c.Status(fiber.StatusOK).SendString(html)
+	return c.Status(fiber.StatusOK).SendString(result.HTML)
 }
 
 // PreviewSignature godoc
 // @Summary Preview an email signature
-// @Description Renders the signature in an HTML page for browser preview
+// @Description Renders the signature in an HTML page for browser preview, including the banner of any campaign currently targeting it
 // @Tags Signatures
 // @Param id path string true "Signature ID"
-// @Param template query string false "Template type (basic or modern)"
+// @Param template query string false "Template type (basic or modern), defaulting to the signature's template"
+// @Param variant query string false "A/B test variant to preview"
+// @Param locale query string false "Locale for content rules, defaulting to the signature's locale"
+// @Param region query string false "Region for content rules, defaulting to the signature's region"
+// @Param audience query string false "Audience for content rules (internal or external, the default)"
 // @Produce html
 // @Success 200 {string} string "HTML preview of the signature"
 // @Failure 404 {object} map[string]interface{} "Signature not found"
@@ -148,17 +219,8 @@ func PreviewSignature(c *fiber.Ctx) error {
 	// Get signature_id from URL params
 	signatureID := c.Params("id")
 
-	// Optional: Get template type from query params (default to "basic")
-	templateType := c.Query("template", "basic")
-
-	// Fetch the signature data from the database
-	var templateData map[string]interface{}
-	err := database.DB.QueryRow(
-		context.Background(),
-		"SELECT template_data FROM signatures WHERE id = $1 AND user_id = $2",
-		signatureID,
-		userID,
-	).Scan(&templateData)
+	// Fetch the signature and its brand kit from the database
+	signature, err := loadSignature(signatureID, userID)
 	if err != nil {
 		log.Printf("Failed to fetch signature: %v\n", err)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -166,17 +228,201 @@ func PreviewSignature(c *fiber.Ctx) error {
 		})
 	}
 
+	// Optional: Preview one of the signature's A/B test variants
+	var variant *VariantResponse
+	stored := signature.Template
+	if variantID := c.Query("variant"); variantID != "" {
+		variant, err = findVariant(signature.ID, variantID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Variant not found",
+			})
+		}
+		if variant.Template != "" {
+			stored = variant.Template
+		}
+	}
+
+	// Optional: Get template type from query params, else the signature's own
+	templateType := templateName(c, stored)
+
 	// Generate HTML based on the template type
-	var signatureHTML string
-	switch templateType {
-	case "modern":
-		signatureHTML = renderModernHTML(templateData)
-	default:
-		signatureHTML = renderBasicHTML(templateData)
+	signatureHTML, err := renderWithBanner(signature, templateType, variant, requestContentContext(c, signature, templates.FormatPreview))
+	if err != nil {
+		log.Printf("Failed to render signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate preview",
+		})
 	}
 
 	// Wrap the signature in a full HTML document
-	html := fmt.Sprintf(`
+	html := previewDocument(fmt.Sprintf(`<div class="signature-container">%s</div>`, signatureHTML))
+
+	// Return the HTML response
+	return c.Status(fiber.StatusOK).SendString(html)
+}
+
+// PreviewSignatureDarkMode godoc
+// @Summary Preview an email signature in dark mode
+// @Description Renders the signature three ways for checking contrast: in light mode, as clients honoring its dark-mode styles show it (Apple Mail, Outlook.com), and with the forced color inversion of clients that ignore them (Gmail and Outlook apps), where images are not inverted. Dark palette colors below the WCAG AA contrast ratio are listed.
+// @Tags Signatures
+// @Param id path string true "Signature ID"
+// @Param template query string false "Template type (basic or modern), defaulting to the signature's template"
+// @Produce html
+// @Success 200 {string} string "HTML preview of the signature in light and dark mode"
+// @Failure 404 {object} map[string]interface{} "Signature not found"
+// @Failure 500 {object} map[string]interface{} "Failed to generate preview"
+// @Security BearerAuth
+// @Router /api/signature/{id}/preview/dark [get]
+func PreviewSignatureDarkMode(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		log.Printf("Failed to fetch signature: %v\n", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Signature not found",
+		})
+	}
+
+	templateType := templateName(c, signature.Template)
+	signatureHTML, err := renderWithBanner(signature, templateType, nil, requestContentContext(c, signature, templates.FormatPreview))
+	if err != nil {
+		log.Printf("Failed to render signature: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate preview",
+		})
+	}
+
+	// Flag dark palette colors that are hard to read on the dark background
+	var warnings strings.Builder
+	contrast := signature.Brand.DarkContrast()
+	keys := make([]string, 0, len(contrast))
+	for key := range contrast {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if contrast[key] < templates.MinContrast {
+			fmt.Fprintf(&warnings, `<div class="preview-label">Low contrast: %s color is %.2f:1, at least %.1f:1 is recommended</div>`,
+				html.EscapeString(key), contrast[key], templates.MinContrast)
+		}
+	}
+
+	// [data-ogsc] and [data-ogsb] make the signature's Outlook.com dark-mode
+	// rules apply; the inverted container is undone for images, as in
+	// clients that invert colors themselves
+	page := previewDocument(fmt.Sprintf(`
+            <style>
+                .signature-container.dark-mode { background: #1e1e1e; border-color: #333; }
+                .signature-container.inverted { filter: invert(1) hue-rotate(180deg); }
+                .signature-container.inverted img { filter: invert(1) hue-rotate(180deg); }
+            </style>
+            <div class="preview-label">Light mode</div>
+            <div class="signature-container">%[1]s</div>
+            <div class="preview-label">Dark mode, with the signature's dark styles</div>
+            <div class="signature-container dark-mode" data-ogsc data-ogsb>%[1]s</div>
+            %[2]s
+            <div class="preview-label">Dark mode, forced color inversion</div>
+            <div class="signature-container inverted">%[1]s</div>
+    `, signatureHTML, warnings.String()))
+
+	c.Type("html")
+	return c.Status(fiber.StatusOK).SendString(page)
+}
+
+// exportSignature renders the signature as the variant shows it, if any,
+// with its banner and footer, then inlines CSS and minifies the markup so
+// the output survives email clients. Signatures tracking opens get a
+// tracking pixel. With track set, outbound links go through tracked
+// redirects.
+func exportSignature(signature *signatureRecord, templateType string, variant *VariantResponse, ctx templates.Context, track bool) (export.Result, error) {
+	html, err := renderWithBanner(signature, templateType, variant, ctx)
+	if err != nil {
+		return export.Result{}, err
+	}
+
+	if signature.TrackOpens {
+		pixel, err := templates.RenderPixel(openPixelURL(signature.ID, variant))
+		if err != nil {
+			return export.Result{}, err
+		}
+		html += pixel
+	}
+
+	pipeline := export.DefaultPipeline()
+	if track {
+		pipeline = export.NewPipeline(export.InlineCSS, export.RewriteLinks(trackLinks(signature.ID, variant)), export.Minify)
+	}
+	return pipeline.Process(html)
+}
+
+// renderWithBanner renders the signature followed by the variant's banner
+// or else the banner of the campaign currently targeting it, if any, and
+// the footer content its rules choose for the context. Banner clicks
+// record the variant.
+func renderWithBanner(signature *signatureRecord, templateType string, variant *VariantResponse, ctx templates.Context) (string, error) {
+	if variant != nil {
+		signature = variant.apply(signature)
+	}
+	html, err := signature.render(templateType, ctx.Locale)
+	if err != nil {
+		return "", err
+	}
+	footer, err := contentFooter(signature, ctx)
+	if err != nil {
+		return "", err
+	}
+	html, err = appendBanner(html, signature, variant)
+	if err != nil {
+		return "", err
+	}
+	return html + footer, nil
+}
+
+// appendBanner appends the variant's or campaign's banner, if any
+func appendBanner(html string, signature *signatureRecord, variant *VariantResponse) (string, error) {
+	var banner *templates.Banner
+	var err error
+	if variant != nil && variant.Banner != nil {
+		banner, err = variant.banner()
+	} else {
+		banner, err = campaignBanner(signature)
+	}
+	if err != nil || banner == nil {
+		return html, err
+	}
+	if variant != nil {
+		banner.LinkURL += "?v=" + variant.ID
+	}
+
+	bannerHTML, err := templates.RenderBanner(*banner)
+	if err != nil {
+		return "", err
+	}
+	return html + bannerHTML, nil
+}
+
+// templateName returns the template requested in the query string, else
+// the one stored on the signature, falling back to the default template
+// for unknown names
+func templateName(c *fiber.Ctx, stored string) string {
+	return resolveTemplate(c.Query("template", stored))
+}
+
+// resolveTemplate returns the named template, falling back to the default
+// template for empty or unknown names
+func resolveTemplate(name string) string {
+	if !templates.Exists(name) {
+		return templates.DefaultTemplate
+	}
+	return name
+}
+
+// previewDocument wraps rendered signatures in a full HTML page for
+// viewing in a browser
+func previewDocument(content string) string {
+	return fmt.Sprintf(`
         <!DOCTYPE html>
         <html>
         <head>
@@ -196,107 +442,128 @@ func PreviewSignature(c *fiber.Ctx) error {
                     max-width: 600px;
                     margin: auto;
                 }
+                .preview-label {
+                    max-width: 600px;
+                    margin: 20px auto 8px;
+                    color: #666;
+                    font-size: 12px;
+                    text-transform: uppercase;
+                }
             </style>
         </head>
         <body>
-            <div class="signature-container">
-                %s
-            </div>
+            %s
         </body>
         </html>
-    `, signatureHTML)
-
-	// Return the HTML response
-	return c.Status(fiber.StatusOK).SendString(html)
+    `, content)
 }
 
-// renderBasicHTML generates a basic HTML signature
-func renderBasicHTML(data map[string]interface{}) string {
-	// Same implementation as renderHTML above
-	return renderHTML(data)
+// signatureRecord is a stored signature along with the brand kit it uses
+type signatureRecord struct {
+	ID           string
+	TemplateData map[string]interface{}
+	BrandKitID   *string
+	// Template is the template the signature renders with by default
+	Template      string
+	EmployeeEmail string
+	Brand         templates.Brand
+	// MasterTemplateID is set for employee signatures rendered from a
+	// master template, whose TemplateData holds only personal fields
+	MasterTemplateID *string
+	Master           master.Template
+	RegionOverrides  map[string]string
+	// ContentRules choose the footer content, starting with the master
+	// template's rules
+	ContentRules []templates.Rule
+	// TrackOpens embeds a tracking pixel in exports
+	TrackOpens bool
 }
 
-// renderModernHTML generates a modern HTML signature
-func renderModernHTML(data map[string]interface{}) string {
-	name := data["name"].(string)
-	jobTitle := data["job_title"].(string)
-	company := data["company"].(string)
-	phone := data["phone"].(string)
-	website := data["website"].(string)
+// input returns the template input for rendering the signature
+func (s *signatureRecord) input() templates.Input {
+	return templates.Input{Data: s.TemplateData, Brand: s.Brand, Locale: s.locale()}
+}
 
-	socialLinks := data["social_links"].(map[string]interface{})
-	linkedin := socialLinks["linkedin"].(string)
-	twitter := socialLinks["twitter"].(string)
+// locale returns the signature's own locale, else the one its master
+// template sets for every employee, else the default
+func (s *signatureRecord) locale() string {
+	if locale, _ := s.TemplateData["locale"].(string); locale != "" {
+		return locale
+	}
+	if locale := s.Master.Values["locale"]; locale != "" {
+		return locale
+	}
+	return templates.DefaultLocale
+}
 
-	return fmt.Sprintf(`
-        <div style="font-family: Verdana, sans-serif; color: #222; font-size: 16px; line-height: 1.8;">
-            <table style="width: 100%%; border-spacing: 10px; background-color: #f9f9f9; padding: 10px;">
-                <tr>
-                    <td style="padding: 5px;">
-                        <div style="font-size: 20px; font-weight: bold;">%s</div>
-                        <div style="color: #555;">%s</div>
-                        <div style="font-size: 12px; color: #777;">%s</div>
-                    </td>
-                </tr>
-                <tr>
-                    <td style="padding: 5px;">
-                        <a href="tel:%s" style="color: #0a66c2; text-decoration: none; font-size: 14px;">Call: %s</a><br>
-                        <a href="%s" style="color: #0a66c2; text-decoration: none; font-size: 14px;">Website: %s</a>
-                    </td>
-                </tr>
-                <tr>
-                    <td style="padding: 5px;">
-                        <a href="%s" style="color: #0a66c2; text-decoration: none; margin-right: 15px;">LinkedIn</a>
-                        <a href="%s" style="color: #0a66c2; text-decoration: none;">Twitter</a>
-                    </td>
-                </tr>
-            </table>
-        </div>
-    `, name, jobTitle, company, phone, phone, website, website, linkedin, twitter)
+// render renders the signature with the template type in the locale, or
+// with its master template if it has one. An empty locale uses the
+// signature's own.
+func (s *signatureRecord) render(templateType, locale string) (string, error) {
+	if locale == "" {
+		locale = s.locale()
+	}
+	if s.MasterTemplateID != nil {
+		html := s.Master.Render(master.Fields(s.TemplateData), s.RegionOverrides)
+		return templates.WrapDirection(html, locale), nil
+	}
+	input := s.input()
+	input.Locale = locale
+	return templates.Render(templateType, input)
 }
 
-// renderHTML generates an HTML string from the template data
-func renderHTML(data map[string]interface{}) string {
-	name := data["name"].(string)
-	jobTitle := data["job_title"].(string)
-	company := data["company"].(string)
-	phone := data["phone"].(string)
-	website := data["website"].(string)
+// lint exports the signature with each named template, or only with its
+// master template if it has one, and lints the HTML served
+func (s *signatureRecord) lint(names []string) ([]TemplateLintResult, error) {
+	if s.MasterTemplateID != nil {
+		names = []string{MasterTemplateLintName}
+	}
+	results := make([]TemplateLintResult, 0, len(names))
+	for _, name := range names {
+		report, err := s.lintExport(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, TemplateLintResult{Template: name, Passed: report.Passed(), Report: report})
+	}
+	return results, nil
+}
 
-	socialLinks := data["social_links"].(map[string]interface{})
-	linkedin := socialLinks["linkedin"].(string)
-	twitter := socialLinks["twitter"].(string)
+// signatureRecordQuery selects the columns scanSignatureRecord expects
+const signatureRecordQuery = `SELECT s.id, s.template_data, s.brand_kit_id, COALESCE(s.template, ''), COALESCE(s.employee_email, ''),
+         s.master_template_id, COALESCE(mt.regions, '[]'), COALESCE(mt.placeholder_values, '{}'), s.region_overrides,
+         COALESCE(mt.content_rules, '[]'), s.content_rules, s.track_opens, ` + brandKitColumns + `
+         FROM signatures s
+         LEFT JOIN brand_kits bk ON bk.id = s.brand_kit_id
+         LEFT JOIN master_templates mt ON mt.id = s.master_template_id`
+
+// loadSignature fetches a signature owned by the user
+func loadSignature(signatureID, userID string) (*signatureRecord, error) {
+	row := database.DB.QueryRow(
+		context.Background(),
+		signatureRecordQuery+" WHERE s.id = $1 AND s.user_id = $2",
+		signatureID,
+		userID,
+	)
+	return scanSignatureRecord(row)
+}
 
-	return fmt.Sprintf(`
-        <div style="font-family: Arial, sans-serif; color: #444; font-size: 14px; line-height: 1.5;">
-            <table>
-                <tr>
-                    <td>
-                        <div style="font-size: 18px; font-weight: bold; color: #222;">%s</div>
-                        <div style="color: #666;">%s</div>
-                        <div style="color: #999; font-size: 12px;">%s</div>
-                    </td>
-                </tr>
-                <tr>
-                    <td>
-                        <div style="margin-top: 10px;">
-                            <a href="tel:%s" style="color: #0a66c2; text-decoration: none;">%s</a> | 
-                            <a href="%s" style="color: #0a66c2; text-decoration: none;">%s</a>
-                        </div>
-                        <div style="margin-top: 10px;">
-                            <a href="%s" style="color: #0a66c2; text-decoration: none; margin-right: 10px;">LinkedIn</a>
-                            <a href="%s" style="color: #0a66c2; text-decoration: none;">Twitter</a>
-                        </div>
-                    </td>
-                </tr>
-            </table>
-        </div>
-    `, name, jobTitle, company, phone, phone, website, website, linkedin, twitter)
+func scanSignatureRecord(row pgx.Row) (*signatureRecord, error) {
+	signature := &signatureRecord{}
+	var ownRules []templates.Rule
+	dest := append([]interface{}{&signature.ID, &signature.TemplateData, &signature.BrandKitID, &signature.Template, &signature.EmployeeEmail,
+		&signature.MasterTemplateID, &signature.Master.Regions, &signature.Master.Values, &signature.RegionOverrides,
+		&signature.ContentRules, &ownRules, &signature.TrackOpens}, brandKitDest(&signature.Brand)...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	signature.ContentRules = append(signature.ContentRules, ownRules...)
+	return signature, nil
 }
 
 // GetAllSignatures godoc
 // @Summary Get all signatures
-// @Description Retrieve all signatures for the authenticated user.
+// @Description Retrieve all signatures for the authenticated user. Archived signatures are left out.
 // @Tags Signatures
 // @Accept json
 // @Produce json
@@ -310,7 +577,7 @@ func GetAllSignatures(c *fiber.Ctx) error {
 
 	rows, err := database.DB.Query(
 		context.Background(),
-		"SELECT id, user_id, template_data, created_at FROM signatures WHERE user_id = $1",
+		"SELECT id, user_id, template_data, created_at FROM signatures WHERE user_id = $1 AND archived_at IS NULL",
 		userID,
 	)
 	if err != nil {
@@ -429,7 +696,7 @@ func CountSignatures(c *fiber.Ctx) error {
 
 	// Query to count signatures for the user
 	var count int
-	err := database.DB.QueryRow(context.Background(), "SELECT COUNT(*) FROM signatures WHERE user_id = $1", userID).Scan(&count)
+	err := database.DB.QueryRow(context.Background(), "SELECT COUNT(*) FROM signatures WHERE user_id = $1 AND archived_at IS NULL", userID).Scan(&count)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to count signatures"})
 	}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"email-signature-backend/social"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SocialNetworkResponse struct {
+	social.Network
+	// Icons maps style, then size, to the icon URL
+	Icons map[string]map[string]string `json:"icons"`
+}
+
+type SocialNetworksListResponse struct {
+	Networks []SocialNetworkResponse `json:"networks"`
+	Styles   []string                `json:"styles"`
+	Sizes    []int                   `json:"sizes"`
+}
+
+// GetSocialNetworks godoc
+// @Summary List supported social networks
+// @Description Returns the social network catalog with an example profile URL and hosted icon URLs in every style and size. Add networks to a signature as an ordered "social" list of {network, url} entries in template_data.
+// @Tags Social
+// @Produce json
+// @Success 200 {object} SocialNetworksListResponse
+// @Router /api/social-networks [get]
+func GetSocialNetworks(c *fiber.Ctx) error {
+	networks := make([]SocialNetworkResponse, 0, len(social.Catalog))
+	for _, network := range social.Catalog {
+		icons := map[string]map[string]string{}
+		for _, style := range social.Styles {
+			icons[style] = map[string]string{}
+			for _, size := range social.Sizes {
+				icons[style][strconv.Itoa(size)] = social.IconURL(style, size, network.Key)
+			}
+		}
+		networks = append(networks, SocialNetworkResponse{Network: network, Icons: icons})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SocialNetworksListResponse{
+		Networks: networks,
+		Styles:   social.Styles,
+		Sizes:    social.Sizes,
+	})
+}
+
+// ServeSocialIcon godoc
+// @Summary Serve a social network icon
+// @Description Public PNG icon for a social network. Images are twice the requested size for retina screens.
+// @Tags Social
+// @Produce png
+// @Param style path string true "Icon style (color or mono)"
+// @Param size path int true "Icon size in CSS pixels (16, 24 or 32)"
+// @Param file path string true "Network key followed by .png"
+// @Success 200 {file} binary
+// @Failure 404 {string} string "Not found"
+// @Router /icons/{style}/{size}/{file} [get]
+func ServeSocialIcon(c *fiber.Ctx) error {
+	size, err := strconv.Atoi(c.Params("size"))
+	key, isPNG := strings.CutSuffix(c.Params("file"), ".png")
+	if err != nil || !isPNG {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	icon, err := social.Icon(c.Params("style"), size, key)
+	if errors.Is(err, social.ErrUnknownNetwork) || errors.Is(err, social.ErrUnknownStyle) || errors.Is(err, social.ErrUnknownSize) {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		log.Printf("Failed to generate social icon: %v\n", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=604800")
+	return c.Status(fiber.StatusOK).Send(icon)
+}
@@ -0,0 +1,542 @@
+package handlers
+
+import (
+	"context"
+	"email-signature-backend/abtest"
+	"email-signature-backend/database"
+	"email-signature-backend/lint"
+	"email-signature-backend/social"
+	"email-signature-backend/templates"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxVariants is the most variants a signature can be tested with
+const MaxVariants = 10
+
+// MaxVariantWeight bounds variant weights
+const MaxVariantWeight = 1000
+
+type VariantBanner struct {
+	ImageURL    string `json:"image_url"`
+	ImageWidth  int    `json:"image_width"`
+	ImageHeight int    `json:"image_height"`
+	AltText     string `json:"alt_text"`
+	LinkURL     string `json:"link_url"`
+}
+
+type VariantRequest struct {
+	Name string `json:"name"`
+	// Weight is the variant's share of traffic relative to the others,
+	// 1 by default
+	Weight int `json:"weight"`
+	// Template overrides the signature's template
+	Template string `json:"template"`
+	// TemplateData overrides fields of the signature's template data
+	TemplateData map[string]interface{} `json:"template_data"`
+	// BannerAssetID fills in the banner image from an uploaded banner asset
+	BannerAssetID string `json:"banner_asset_id"`
+	// Banner replaces any campaign banner while the variant is shown
+	Banner *VariantBanner `json:"banner"`
+}
+
+type VariantResponse struct {
+	ID           string                 `json:"id"`
+	SignatureID  string                 `json:"signature_id"`
+	Name         string                 `json:"name"`
+	Weight       int                    `json:"weight"`
+	Template     string                 `json:"template,omitempty"`
+	TemplateData map[string]interface{} `json:"template_data"`
+	Banner       *VariantBanner         `json:"banner"`
+	// Exports is how often the variant has been exported or deployed
+	Exports   int       `json:"exports"`
+	CreatedAt time.Time `json:"created_at"`
+	// Lint reports the signature as the variant exports it, after the
+	// variant is created or updated
+	Lint        *lint.Report `json:"lint,omitempty"`
+	LintWarning string       `json:"lint_warning,omitempty"`
+}
+
+type VariantsListResponse struct {
+	Variants []VariantResponse `json:"variants"`
+}
+
+type VariantReport struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Weight  int     `json:"weight"`
+	Exports int     `json:"exports"`
+	Clicks  int     `json:"clicks"`
+	CTR     float64 `json:"ctr"`
+	Control bool    `json:"control"`
+	// Lift is the relative change in click-through rate over the control
+	Lift *float64 `json:"lift,omitempty"`
+	// Significance compares the variant with the control
+	Significance *abtest.Significance `json:"significance,omitempty"`
+}
+
+type VariantReportResponse struct {
+	SignatureID string          `json:"signature_id"`
+	Confidence  float64         `json:"confidence"`
+	MinSample   int             `json:"min_sample"`
+	Variants    []VariantReport `json:"variants"`
+	// Winner is the variant that beats the control significantly, if any
+	Winner *string `json:"winner"`
+}
+
+// variantColumns selects the columns scanVariant expects
+const variantColumns = "id, signature_id, name, weight, template, template_data, banner, exports, created_at"
+
+// CreateVariant godoc
+// @Summary Add an A/B test variant to a signature
+// @Description Adds a variant that overrides the signature's template, template data or banner. Once a signature has variants, every export and deployment serves one of them in proportion to their weights, and clicks on tracked links record the variant. The first variant is the control.
+// @Tags Variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param request body VariantRequest true "Variant payload"
+// @Success 201 {object} VariantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/variants [post]
+func CreateVariant(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	req := new(VariantRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.validate(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	variants, err := loadVariants(signature.ID)
+	if err != nil {
+		log.Printf("Failed to fetch variants: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create variant"})
+	}
+	if len(variants) >= MaxVariants {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: fmt.Sprintf("A signature can have at most %d variants", MaxVariants)})
+	}
+
+	variant, err := scanVariant(database.DB.QueryRow(
+		context.Background(),
+		`INSERT INTO signature_variants (signature_id, name, weight, template, template_data, banner)
+         VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+variantColumns,
+		signature.ID,
+		req.Name,
+		req.Weight,
+		req.Template,
+		req.TemplateData,
+		req.Banner,
+	))
+	if err != nil {
+		log.Printf("Failed to insert variant: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to create variant"})
+	}
+	variant.lint(signature)
+
+	return c.Status(fiber.StatusCreated).JSON(variant)
+}
+
+// GetVariants godoc
+// @Summary List a signature's variants
+// @Tags Variants
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Success 200 {object} VariantsListResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/variants [get]
+func GetVariants(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	variants, err := loadVariants(signature.ID)
+	if err != nil {
+		log.Printf("Failed to fetch variants: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch variants"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(VariantsListResponse{Variants: variants})
+}
+
+// UpdateVariant godoc
+// @Summary Update a variant
+// @Description Replaces the variant's overrides and weight. Its export and click counts are kept.
+// @Tags Variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body VariantRequest true "Variant payload"
+// @Success 200 {object} VariantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/variants/{variantId} [put]
+func UpdateVariant(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	req := new(VariantRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "Invalid request payload"})
+	}
+	if err := req.validate(userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: err.Error()})
+	}
+
+	ctx := context.Background()
+	variant, err := scanVariant(database.DB.QueryRow(
+		ctx,
+		`UPDATE signature_variants SET name = $3, weight = $4, template = $5, template_data = $6, banner = $7
+         WHERE id::text = $1 AND signature_id = $2
+         RETURNING `+variantColumns,
+		c.Params("variantId"),
+		signature.ID,
+		req.Name,
+		req.Weight,
+		req.Template,
+		req.TemplateData,
+		req.Banner,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Variant not found"})
+	}
+	if err != nil {
+		log.Printf("Failed to update variant: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to update variant"})
+	}
+
+	// Banner links already sent follow the new destination
+	if variant.Banner != nil {
+		_, err = database.DB.Exec(ctx, "UPDATE links SET url = $1 WHERE variant_id = $2", variant.Banner.LinkURL, variant.ID)
+		if err != nil {
+			log.Printf("Failed to update variant banner link: %v\n", err)
+		}
+	}
+	variant.lint(signature)
+
+	return c.Status(fiber.StatusOK).JSON(variant)
+}
+
+// DeleteVariant godoc
+// @Summary Delete a variant
+// @Description Deletes a variant. Its clicks are kept without a variant.
+// @Tags Variants
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} MessageResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/variants/{variantId} [delete]
+func DeleteVariant(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	result, err := database.DB.Exec(
+		context.Background(),
+		"DELETE FROM signature_variants WHERE id::text = $1 AND signature_id = $2",
+		c.Params("variantId"),
+		signature.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to delete variant: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to delete variant"})
+	}
+	if result.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Variant not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Variant deleted successfully"})
+}
+
+// GetVariantReport godoc
+// @Summary Compare a signature's variants
+// @Description Reports exports, clicks by people and click-through rate per variant, with each variant's lift over the control (the first variant) and a two-proportion z-test. A variant is significant at 95% confidence once both it and the control have at least min_sample exports.
+// @Tags Variants
+// @Produce json
+// @Param id path string true "Signature ID"
+// @Success 200 {object} VariantReportResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/signature/{id}/variants/report [get]
+func GetVariantReport(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	signature, err := loadSignature(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "Signature not found"})
+	}
+
+	rows, err := database.DB.Query(
+		context.Background(),
+		`SELECT v.id, v.name, v.weight, v.exports, COUNT(cl.id)
+         FROM signature_variants v
+         LEFT JOIN clicks cl ON cl.variant_id = v.id AND cl.traffic = 'human'
+         WHERE v.signature_id = $1
+         GROUP BY v.id
+         ORDER BY v.created_at, v.id`,
+		signature.ID,
+	)
+	if err != nil {
+		log.Printf("Failed to fetch variant report: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch variant report"})
+	}
+	reports, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (VariantReport, error) {
+		var report VariantReport
+		err := row.Scan(&report.ID, &report.Name, &report.Weight, &report.Exports, &report.Clicks)
+		report.CTR = abtest.Rate(report.Clicks, report.Exports)
+		return report, err
+	})
+	if err != nil {
+		log.Printf("Failed to fetch variant report: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "Failed to fetch variant report"})
+	}
+
+	response := VariantReportResponse{
+		SignatureID: signature.ID,
+		Confidence:  abtest.Confidence,
+		MinSample:   abtest.MinSample,
+		Variants:    reports,
+	}
+	if len(reports) > 0 {
+		control := &reports[0]
+		control.Control = true
+		var best *VariantReport
+		for i := range reports[1:] {
+			report := &reports[i+1]
+			significance := abtest.Compare(control.Clicks, control.Exports, report.Clicks, report.Exports)
+			report.Significance = &significance
+			if control.CTR > 0 {
+				lift := report.CTR/control.CTR - 1
+				report.Lift = &lift
+			}
+			if significance.Significant && significance.ZScore > 0 && (best == nil || report.CTR > best.CTR) {
+				best = report
+			}
+		}
+		if best != nil {
+			response.Winner = &best.ID
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// validate checks the request, applies defaults and fills in the banner
+// image from a banner asset
+func (r *VariantRequest) validate(userID string) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Weight == 0 {
+		r.Weight = 1
+	}
+	if r.Weight < 1 || r.Weight > MaxVariantWeight {
+		return errors.New("weight must be between 1 and 1000")
+	}
+	if r.Template != "" && !templates.Exists(r.Template) {
+		return errors.New("unknown template")
+	}
+	if r.TemplateData == nil {
+		r.TemplateData = map[string]interface{}{}
+	}
+	if problems := social.Validate(r.TemplateData); len(problems) > 0 {
+		return errors.New("invalid social links")
+	}
+
+	if r.BannerAssetID != "" {
+		asset, err := scanAsset(database.DB.QueryRow(
+			context.Background(),
+			"SELECT "+assetColumns+" FROM assets WHERE id = $1 AND user_id = $2 AND kind = 'banner'",
+			r.BannerAssetID,
+			userID,
+		))
+		if err != nil {
+			return errors.New("banner asset not found")
+		}
+		if r.Banner == nil {
+			r.Banner = &VariantBanner{}
+		}
+		r.Banner.ImageURL, r.Banner.ImageWidth, r.Banner.ImageHeight = asset.URL, asset.DisplayWidth, asset.DisplayHeight
+	}
+	if r.Banner != nil {
+		if !isHTTPURL(r.Banner.ImageURL) || !isHTTPURL(r.Banner.LinkURL) {
+			return errors.New("banner image_url and link_url must be http or https URLs")
+		}
+		if r.Banner.ImageWidth <= 0 || r.Banner.ImageHeight <= 0 {
+			return errors.New("banner image_width and image_height are required")
+		}
+	}
+	return nil
+}
+
+// apply returns a copy of the signature as the variant shows it
+func (v *VariantResponse) apply(signature *signatureRecord) *signatureRecord {
+	varied := *signature
+	varied.TemplateData = make(map[string]interface{}, len(signature.TemplateData)+len(v.TemplateData))
+	for key, value := range signature.TemplateData {
+		varied.TemplateData[key] = value
+	}
+	for key, value := range v.TemplateData {
+		varied.TemplateData[key] = value
+	}
+	if v.Template != "" {
+		varied.Template = v.Template
+	}
+	return &varied
+}
+
+// lint lints the signature as the variant exports it, with the variant's
+// template or else the signature's. The variant is saved by then, so a
+// failure to lint only leaves a warning.
+func (v *VariantResponse) lint(signature *signatureRecord) {
+	templateName := signature.Template
+	if v.Template != "" {
+		templateName = v.Template
+	}
+	report, err := signature.lintExport(resolveTemplate(templateName), v)
+	if err != nil {
+		log.Printf("Failed to lint variant %s: %v\n", v.ID, err)
+		v.LintWarning = lintWarning
+		return
+	}
+	v.Lint = &report
+}
+
+// banner returns the variant's banner linked through its tracked link
+func (v *VariantResponse) banner() (*templates.Banner, error) {
+	var code string
+	err := insertLink(func() error {
+		return database.DB.QueryRow(
+			context.Background(),
+			`INSERT INTO links (signature_id, url, variant_id) VALUES ($1, $2, $3)
+             ON CONFLICT (variant_id) WHERE variant_id IS NOT NULL DO UPDATE SET url = EXCLUDED.url
+             RETURNING code`,
+			v.SignatureID,
+			v.Banner.LinkURL,
+			v.ID,
+		).Scan(&code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &templates.Banner{
+		ImageURL: v.Banner.ImageURL,
+		Width:    v.Banner.ImageWidth,
+		Height:   v.Banner.ImageHeight,
+		Alt:      v.Banner.AltText,
+		LinkURL:  trackedLinkURL(code),
+	}, nil
+}
+
+// assignVariant picks the variant to serve and counts the export. With a
+// recipient the choice is stable per recipient; without one exports are
+// spread exactly by weight. Signatures without variants return nil.
+func assignVariant(signatureID, recipient string) (*VariantResponse, error) {
+	variants, err := loadVariants(signatureID)
+	if err != nil || len(variants) == 0 {
+		return nil, err
+	}
+
+	arms := make([]abtest.Arm, len(variants))
+	for i, variant := range variants {
+		arms[i] = abtest.Arm{Weight: variant.Weight, Exports: variant.Exports}
+	}
+	var chosen int
+	if recipient != "" {
+		chosen = abtest.Assign(arms, signatureID+":"+strings.ToLower(recipient))
+	} else {
+		chosen = abtest.Next(arms)
+	}
+
+	variant := variants[chosen]
+	_, err = database.DB.Exec(
+		context.Background(),
+		"UPDATE signature_variants SET exports = exports + 1 WHERE id = $1",
+		variant.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	variant.Exports++
+	return &variant, nil
+}
+
+// findVariant returns one of the signature's variants by ID
+func findVariant(signatureID, variantID string) (*VariantResponse, error) {
+	if _, err := uuid.Parse(variantID); err != nil {
+		return nil, err
+	}
+	return scanVariant(database.DB.QueryRow(
+		context.Background(),
+		"SELECT "+variantColumns+" FROM signature_variants WHERE id = $1 AND signature_id = $2",
+		variantID,
+		signatureID,
+	))
+}
+
+func loadVariants(signatureID string) ([]VariantResponse, error) {
+	rows, err := database.DB.Query(
+		context.Background(),
+		"SELECT "+variantColumns+" FROM signature_variants WHERE signature_id = $1 ORDER BY created_at, id",
+		signatureID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (VariantResponse, error) {
+		variant, err := scanVariant(row)
+		if err != nil {
+			return VariantResponse{}, err
+		}
+		return *variant, nil
+	})
+	if variants == nil {
+		variants = []VariantResponse{}
+	}
+	return variants, err
+}
+
+func scanVariant(row pgx.Row) (*VariantResponse, error) {
+	v := &VariantResponse{}
+	err := row.Scan(&v.ID, &v.SignatureID, &v.Name, &v.Weight, &v.Template, &v.TemplateData, &v.Banner, &v.Exports, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,289 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MaxSignatureBytes is the largest signature Gmail accepts; Outlook and
+// Apple Mail truncate or reject much larger payloads as well
+const MaxSignatureBytes = 10000
+
+// Severity levels reported for each issue
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rule identifiers reported for each issue
+const (
+	RuleUnsupportedCSS  = "unsupported-css"
+	RuleImageDimensions = "image-dimensions"
+	RuleRelativeURL     = "relative-url"
+	RuleNonTableLayout  = "non-table-layout"
+	RuleSizeLimit       = "size-limit"
+	RuleMissingAltText  = "missing-alt-text"
+	RuleUnparseableHTML = "unparseable-html"
+)
+
+type Issue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Element  string `json:"element,omitempty"`
+}
+
+type Report struct {
+	Size   int     `json:"size"`
+	Errors int     `json:"errors"`
+	Issues []Issue `json:"issues"`
+}
+
+// Passed reports whether the signature has no error-level issues
+func (r Report) Passed() bool {
+	return r.Errors == 0
+}
+
+var (
+	flexboxPattern  = regexp.MustCompile(`(?i)(^|;)\s*(display\s*:\s*(inline-)?(flex|grid)|flex(-[a-z]+)?\s*:|justify-content\s*:|align-items\s*:|gap\s*:)`)
+	positionPattern = regexp.MustCompile(`(?i)(^|;)\s*(float\s*:|position\s*:\s*(absolute|fixed|relative))`)
+	marginPattern   = regexp.MustCompile(`(?i)(^|;)\s*margin(-[a-z]+)?\s*:`)
+	absoluteURL     = regexp.MustCompile(`(?i)^(https?:|mailto:|tel:|cid:|data:image/)`)
+	darkModeMedia   = regexp.MustCompile(`(?i)^@media\s*\(\s*prefers-color-scheme\s*:\s*dark\s*\)$`)
+	darkModeMarker  = regexp.MustCompile(`^\[data-ogs[cb]\]\s`)
+)
+
+// Lint checks rendered signature HTML for markup that email clients,
+// Outlook desktop in particular, are known to break
+func Lint(signatureHTML string) Report {
+	report := Report{Size: len(signatureHTML), Issues: []Issue{}}
+
+	if report.Size > MaxSignatureBytes {
+		report.add(Issue{
+			Rule:     RuleSizeLimit,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Signature is %d bytes, email clients accept at most %d", report.Size, MaxSignatureBytes),
+		})
+	}
+
+	doc, err := html.Parse(strings.NewReader(signatureHTML))
+	if err != nil {
+		report.add(Issue{
+			Rule:     RuleUnparseableHTML,
+			Severity: SeverityError,
+			Message:  "Signature HTML could not be parsed",
+		})
+		return report
+	}
+
+	hasTable := false
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+
+		switch n.Data {
+		case "table":
+			hasTable = true
+		case "style":
+			if darkModeOnly(text(n)) {
+				break
+			}
+			report.add(Issue{
+				Rule:     RuleUnsupportedCSS,
+				Severity: SeverityError,
+				Message:  "<style> blocks are stripped by Gmail and Outlook, use inline styles",
+				Element:  "style",
+			})
+		case "img":
+			report.checkImage(n)
+		}
+
+		report.checkStyle(n)
+		report.checkURL(n, "href")
+		report.checkURL(n, "src")
+	})
+
+	if !hasTable {
+		report.add(Issue{
+			Rule:     RuleNonTableLayout,
+			Severity: SeverityError,
+			Message:  "Signature layout must use tables, Outlook desktop ignores CSS layout",
+		})
+	}
+
+	return report
+}
+
+func (r *Report) add(issue Issue) {
+	if issue.Severity == SeverityError {
+		r.Errors++
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// checkImage flags images without explicit dimensions or alt text
+func (r *Report) checkImage(n *html.Node) {
+	src := attr(n, "src")
+	for _, name := range []string{"width", "height"} {
+		if _, ok := lookupAttr(n, name); !ok {
+			r.add(Issue{
+				Rule:     RuleImageDimensions,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Image %q has no %s attribute, Outlook renders it at its natural size", src, name),
+				Element:  "img",
+			})
+		}
+	}
+
+	if alt, ok := lookupAttr(n, "alt"); !ok || strings.TrimSpace(alt) == "" {
+		r.add(Issue{
+			Rule:     RuleMissingAltText,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Image %q has no alt text, it is blank when images are blocked", src),
+			Element:  "img",
+		})
+	}
+}
+
+// checkStyle flags inline CSS that Outlook's Word rendering engine ignores
+func (r *Report) checkStyle(n *html.Node) {
+	style, ok := lookupAttr(n, "style")
+	if !ok {
+		return
+	}
+
+	if flexboxPattern.MatchString(style) {
+		r.add(Issue{
+			Rule:     RuleUnsupportedCSS,
+			Severity: SeverityError,
+			Message:  "Flexbox and grid are not supported by Outlook desktop",
+			Element:  n.Data,
+		})
+	}
+
+	if positionPattern.MatchString(style) {
+		r.add(Issue{
+			Rule:     RuleNonTableLayout,
+			Severity: SeverityError,
+			Message:  "Floats and positioning are ignored by Outlook desktop, use table cells",
+			Element:  n.Data,
+		})
+	}
+
+	if n.Data == "p" && marginPattern.MatchString(style) {
+		r.add(Issue{
+			Rule:     RuleUnsupportedCSS,
+			Severity: SeverityWarning,
+			Message:  "Margins on <p> are ignored by Outlook desktop, use padding on a table cell",
+			Element:  "p",
+		})
+	}
+}
+
+// checkURL flags relative and protocol-relative URLs, which break once the
+// signature is pasted into a mail client
+func (r *Report) checkURL(n *html.Node, name string) {
+	value, ok := lookupAttr(n, name)
+	if !ok {
+		return
+	}
+
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		r.add(Issue{
+			Rule:     RuleRelativeURL,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("<%s> has an empty %s", n.Data, name),
+			Element:  n.Data,
+		})
+	case strings.HasPrefix(value, "#"), absoluteURL.MatchString(value):
+		return
+	default:
+		r.add(Issue{
+			Rule:     RuleRelativeURL,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s %q is not an absolute URL", name, value),
+			Element:  n.Data,
+		})
+	}
+}
+
+// darkModeOnly reports whether a stylesheet holds nothing but dark-mode
+// rules: prefers-color-scheme media queries and Outlook.com [data-ogsc] /
+// [data-ogsb] rules. Clients that strip them keep the light styles, so
+// such blocks are safe progressive enhancement.
+func darkModeOnly(css string) bool {
+	rest := strings.TrimSpace(css)
+	for rest != "" {
+		open := strings.Index(rest, "{")
+		if open < 0 {
+			return false
+		}
+		prelude := strings.TrimSpace(rest[:open])
+
+		// Find the matching brace, allowing one level of nesting for
+		// media queries
+		depth, end := 0, -1
+		for i := open; i < len(rest); i++ {
+			if rest[i] == '{' {
+				depth++
+			} else if rest[i] == '}' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return false
+		}
+
+		if !darkModeMedia.MatchString(prelude) {
+			for _, selector := range strings.Split(prelude, ",") {
+				if !darkModeMarker.MatchString(strings.TrimSpace(selector)) {
+					return false
+				}
+			}
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return true
+}
+
+// text returns the text content of a node's children
+func text(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return b.String()
+}
+
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, name string) string {
+	value, _ := lookupAttr(n, name)
+	return value
+}
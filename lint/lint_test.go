@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+const cleanSignature = `<table cellpadding="0" cellspacing="0"><tr><td style="padding: 4px; color: #333;">
+<img src="https://example.com/logo.png" width="120" height="40" alt="Example">
+<a href="https://example.com">example.com</a> <a href="mailto:jane@example.com">Mail</a>
+</td></tr></table>`
+
+func TestLintCleanSignature(t *testing.T) {
+	report := Lint(cleanSignature)
+	if !report.Passed() || len(report.Issues) != 0 {
+		t.Fatalf("clean signature reported issues: %+v", report.Issues)
+	}
+	if report.Size != len(cleanSignature) {
+		t.Errorf("Size = %d, want %d", report.Size, len(cleanSignature))
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		rule     string
+		severity string
+	}{
+		{"style block", `<style>td { color: red; }</style><table></table>`, RuleUnsupportedCSS, SeverityError},
+		{"flexbox", `<table><tr><td><div style="display: flex">x</div></td></tr></table>`, RuleUnsupportedCSS, SeverityError},
+		{"paragraph margin", `<table><tr><td><p style="margin: 0 0 8px">x</p></td></tr></table>`, RuleUnsupportedCSS, SeverityWarning},
+		{"float", `<table><tr><td><div style="float: left">x</div></td></tr></table>`, RuleNonTableLayout, SeverityError},
+		{"no table", `<div>Jane Doe</div>`, RuleNonTableLayout, SeverityError},
+		{"image without size", `<table><tr><td><img src="https://example.com/a.png" alt="A"></td></tr></table>`, RuleImageDimensions, SeverityError},
+		{"image without alt", `<table><tr><td><img src="https://example.com/a.png" width="1" height="1"></td></tr></table>`, RuleMissingAltText, SeverityWarning},
+		{"relative link", `<table><tr><td><a href="/about">About</a></td></tr></table>`, RuleRelativeURL, SeverityError},
+		{"protocol-relative image", `<table><tr><td><img src="//cdn.example.com/a.png" width="1" height="1" alt="A"></td></tr></table>`, RuleRelativeURL, SeverityError},
+		{"empty link", `<table><tr><td><a href="">x</a></td></tr></table>`, RuleRelativeURL, SeverityWarning},
+		{"too large", `<table><tr><td>` + strings.Repeat("x", MaxSignatureBytes) + `</td></tr></table>`, RuleSizeLimit, SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Lint(tt.html)
+			for _, issue := range report.Issues {
+				if issue.Rule == tt.rule && issue.Severity == tt.severity {
+					if tt.severity == SeverityError && report.Passed() {
+						t.Errorf("report with an error passed")
+					}
+					return
+				}
+			}
+			t.Errorf("no %s %s issue in %+v", tt.severity, tt.rule, report.Issues)
+		})
+	}
+}
+
+func TestLintAllowsDarkModeStyles(t *testing.T) {
+	html := `<style>@media (prefers-color-scheme: dark) { td { color: #eee !important; } }
+[data-ogsc] td { color: #eee !important; }</style>` + cleanSignature
+	if report := Lint(html); !report.Passed() {
+		t.Fatalf("dark-mode-only stylesheet reported errors: %+v", report.Issues)
+	}
+
+	mixed := `<style>@media (prefers-color-scheme: dark) { td { color: #eee; } } td { color: red; }</style>` + cleanSignature
+	if report := Lint(mixed); report.Passed() {
+		t.Fatal("stylesheet with light-mode rules passed")
+	}
+}
+
+func TestLintAllowsAnchorsAndInlineImages(t *testing.T) {
+	html := `<table><tr><td><a href="#top">Top</a> <a href="tel:+15550100">Call</a>
+<img src="data:image/png;base64,iVBORw0KGgo=" width="1" height="1" alt="dot"></td></tr></table>`
+	if report := Lint(html); len(report.Issues) != 0 {
+		t.Fatalf("unexpected issues: %+v", report.Issues)
+	}
+}
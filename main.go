@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"email-signature-backend/config"
 	"email-signature-backend/database"
+	"email-signature-backend/deploy"
+	"email-signature-backend/directory"
+	"email-signature-backend/handlers"
 	"email-signature-backend/routes"
+	"email-signature-backend/storage"
+	"email-signature-backend/tracking"
 	"log"
 	"os"
 
@@ -36,8 +42,37 @@ func main() {
 	//run migrations
 	RunMigrations()
 
-	// Create a new Fiber instance
-	app := fiber.New()
+	// Initialize asset storage
+	storage.Setup()
+
+	// Load the IP ranges of mail security gateways and the geo-IP database
+	tracking.Setup()
+
+	// Purge clicks and opens past their retention period
+	tracking.StartRetention(context.Background())
+
+	// Periodically sync signatures from connected directories, whose bind
+	// passwords are stored encrypted, linting the signatures they write
+	directory.Lint = handlers.LintSavedSignature
+	directory.Setup(context.Background())
+	directory.StartScheduler(context.Background())
+
+	// Deploy queued signatures to mailboxes
+	deploy.Setup()
+	deploy.StartWorker(context.Background())
+
+	// Create a new Fiber instance, reading client IPs from the proxy
+	// header when one is configured and the request comes from a trusted
+	// proxy
+	if config.ProxyIPHeader() != "" && len(config.TrustedProxies()) == 0 {
+		log.Println("PROXY_IP_HEADER is set without TRUSTED_PROXIES, so the header is ignored and clicks record the proxy's IP address")
+	}
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             config.ProxyIPHeader(),
+		EnableIPValidation:      true,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.TrustedProxies(),
+	})
 
 	// Middleware
 	app.Use(logger.New())
@@ -45,7 +80,7 @@ func main() {
 		cors.Config{
 			AllowOrigins: "*",
 			AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-			AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+			AllowMethods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 		},
 	))
 	app.Options("/*", func(c *fiber.Ctx) error {
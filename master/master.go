@@ -0,0 +1,193 @@
+package master
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Region is one section of a master design. Employees cannot change
+// locked regions; they may replace the text of editable ones, which
+// otherwise show the master's content.
+type Region struct {
+	Key    string `json:"key"`
+	HTML   string `json:"html"`
+	Locked bool   `json:"locked"`
+}
+
+// Template is an organization-wide signature design with {{placeholder}}
+// fields filled in per employee
+type Template struct {
+	Regions []Region `json:"regions"`
+	// Values fill placeholders with the same value for every employee,
+	// e.g. the company name; employees cannot override them
+	Values map[string]string `json:"values"`
+}
+
+// MaxRegions is the most regions a master template may have
+const MaxRegions = 20
+
+// placeholderPattern matches placeholders such as {{name}} or {{ job_title }}
+var placeholderPattern = regexp.MustCompile(`{{\s*([a-z][a-z0-9_]*)\s*}}`)
+
+// keyPattern matches region keys and placeholder names
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Validate checks the regions and values are well formed
+func (t *Template) Validate() error {
+	if len(t.Regions) == 0 {
+		return errors.New("a master template needs at least one region")
+	}
+	if len(t.Regions) > MaxRegions {
+		return fmt.Errorf("a master template can have at most %d regions", MaxRegions)
+	}
+
+	seen := map[string]bool{}
+	for _, region := range t.Regions {
+		if !keyPattern.MatchString(region.Key) {
+			return fmt.Errorf("region key %q must be lowercase letters, digits and underscores", region.Key)
+		}
+		if seen[region.Key] {
+			return fmt.Errorf("duplicate region %q", region.Key)
+		}
+		seen[region.Key] = true
+
+		if strings.TrimSpace(region.HTML) == "" {
+			return fmt.Errorf("region %q is empty", region.Key)
+		}
+		if err := checkPlaceholders(region.HTML); err != nil {
+			return fmt.Errorf("region %q: %v", region.Key, err)
+		}
+	}
+
+	for key := range t.Values {
+		if !keyPattern.MatchString(key) {
+			return fmt.Errorf("value %q must be a lowercase placeholder name", key)
+		}
+	}
+	return nil
+}
+
+// checkPlaceholders rejects braces that are not valid placeholders, which
+// would otherwise show up verbatim in every signature
+func checkPlaceholders(text string) error {
+	rest := placeholderPattern.ReplaceAllString(text, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return errors.New("placeholders must look like {{name}}")
+	}
+	return nil
+}
+
+// Placeholders returns the placeholder names used by the regions in the
+// order they first appear
+func (t *Template) Placeholders() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, region := range t.Regions {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(region.HTML, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// PersonalFields returns the placeholders employees fill in themselves:
+// every placeholder the master does not set a value for
+func (t *Template) PersonalFields() []string {
+	fields := []string{}
+	for _, name := range t.Placeholders() {
+		if _, ok := t.Values[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// EditableRegions returns the keys of the regions employees may replace
+func (t *Template) EditableRegions() []string {
+	keys := []string{}
+	for _, region := range t.Regions {
+		if !region.Locked {
+			keys = append(keys, region.Key)
+		}
+	}
+	return keys
+}
+
+// CheckEmployee checks an employee only fills personal fields and only
+// replaces editable regions
+func (t *Template) CheckEmployee(fields, overrides map[string]string) error {
+	personal := map[string]bool{}
+	for _, name := range t.PersonalFields() {
+		personal[name] = true
+	}
+	for _, name := range sortedKeys(fields) {
+		if !personal[name] {
+			return fmt.Errorf("field %q is not a personal field of this template", name)
+		}
+	}
+
+	editable := map[string]bool{}
+	for _, key := range t.EditableRegions() {
+		editable[key] = true
+	}
+	for _, key := range sortedKeys(overrides) {
+		if !editable[key] {
+			return fmt.Errorf("region %q is locked or does not exist", key)
+		}
+		if err := checkPlaceholders(overrides[key]); err != nil {
+			return fmt.Errorf("region %q: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Render renders the master for one employee. Overrides are plain text,
+// escaped and with line breaks kept; overrides for regions that have since
+// been locked or removed are ignored, as are fields the master now sets.
+func (t *Template) Render(fields, overrides map[string]string) string {
+	var b strings.Builder
+	for _, region := range t.Regions {
+		source := region.HTML
+		if override, ok := overrides[region.Key]; ok && !region.Locked {
+			source = strings.ReplaceAll(html.EscapeString(override), "\n", "<br>")
+		}
+
+		b.WriteString(placeholderPattern.ReplaceAllStringFunc(source, func(placeholder string) string {
+			name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+			if value, ok := t.Values[name]; ok {
+				return html.EscapeString(value)
+			}
+			return html.EscapeString(fields[name])
+		}))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Fields returns the string values in signature template data, which is
+// where employee signatures keep their personal fields
+func Fields(data map[string]interface{}) map[string]string {
+	fields := map[string]string{}
+	for key, value := range data {
+		if text, ok := value.(string); ok {
+			fields[key] = text
+		}
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,154 @@
+package master
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testTemplate() *Template {
+	return &Template{
+		Regions: []Region{
+			{Key: "header", HTML: `<b>{{name}}</b>, {{ job_title }} at {{company}}`, Locked: true},
+			{Key: "tagline", HTML: `Building {{company}} since 2010`},
+			{Key: "contact", HTML: `<a href="tel:{{phone}}">{{phone}}</a>`},
+		},
+		Values: map[string]string{"company": "Acme & Co"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions []Region
+		values  map[string]string
+		wantErr string
+	}{
+		{"valid", testTemplate().Regions, nil, ""},
+		{"no regions", nil, nil, "at least one region"},
+		{"bad key", []Region{{Key: "Header", HTML: "x"}}, nil, "lowercase"},
+		{"duplicate key", []Region{{Key: "a", HTML: "x"}, {Key: "a", HTML: "y"}}, nil, "duplicate"},
+		{"empty region", []Region{{Key: "a", HTML: " "}}, nil, "empty"},
+		{"malformed placeholder", []Region{{Key: "a", HTML: "{{Name}}"}}, nil, "placeholders must look like"},
+		{"stray braces", []Region{{Key: "a", HTML: "{{name}} }}"}}, nil, "placeholders must look like"},
+		{"bad value name", []Region{{Key: "a", HTML: "x"}}, map[string]string{"Company": "Acme"}, "lowercase placeholder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Template{Regions: tt.regions, Values: tt.values}).Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	tooMany := make([]Region, MaxRegions+1)
+	for i := range tooMany {
+		tooMany[i] = Region{Key: "r" + strings.Repeat("x", i), HTML: "x"}
+	}
+	if err := (&Template{Regions: tooMany}).Validate(); err == nil {
+		t.Errorf("%d regions passed validation", len(tooMany))
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	tmpl := testTemplate()
+	if got, want := tmpl.Placeholders(), []string{"name", "job_title", "company", "phone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Placeholders() = %v, want %v", got, want)
+	}
+	// The master sets the company, so employees fill in the rest
+	if got, want := tmpl.PersonalFields(), []string{"name", "job_title", "phone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PersonalFields() = %v, want %v", got, want)
+	}
+	if got, want := tmpl.EditableRegions(), []string{"tagline", "contact"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EditableRegions() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckEmployee(t *testing.T) {
+	tests := []struct {
+		name      string
+		fields    map[string]string
+		overrides map[string]string
+		wantErr   string
+	}{
+		{"personal fields and editable regions", map[string]string{"name": "Jane", "phone": "+1 555"}, map[string]string{"tagline": "Hello"}, ""},
+		{"nothing", nil, nil, ""},
+		{"field the master sets", map[string]string{"company": "Other"}, nil, `field "company"`},
+		{"unknown field", map[string]string{"nickname": "JD"}, nil, `field "nickname"`},
+		{"locked region", nil, map[string]string{"header": "Mine"}, `region "header" is locked`},
+		{"unknown region", nil, map[string]string{"footer": "Mine"}, `region "footer" is locked or does not exist`},
+		{"braces in an override", nil, map[string]string{"tagline": "{{ oops"}, "placeholders must look like"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testTemplate().CheckEmployee(tt.fields, tt.overrides)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CheckEmployee() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(*Template)
+		fields    map[string]string
+		overrides map[string]string
+		want      string
+	}{
+		{
+			name:   "placeholders filled and escaped",
+			fields: map[string]string{"name": "Jane <Doe>", "job_title": "CTO", "phone": "+1 555"},
+			want:   "<b>Jane &lt;Doe&gt;</b>, CTO at Acme &amp; Co\nBuilding Acme &amp; Co since 2010\n<a href=\"tel:+1 555\">+1 555</a>\n",
+		},
+		{
+			name:   "missing fields left empty",
+			fields: map[string]string{"name": "Jane"},
+			want:   "<b>Jane</b>,  at Acme &amp; Co\nBuilding Acme &amp; Co since 2010\n<a href=\"tel:\"></a>\n",
+		},
+		{
+			name:      "override is escaped text with line breaks",
+			fields:    map[string]string{"name": "Jane"},
+			overrides: map[string]string{"tagline": "<i>Hi</i>\n{{company}} rocks"},
+			want:      "<b>Jane</b>,  at Acme &amp; Co\n&lt;i&gt;Hi&lt;/i&gt;<br>Acme &amp; Co rocks\n<a href=\"tel:\"></a>\n",
+		},
+		{
+			name:      "override of a region locked since",
+			modify:    func(t *Template) { t.Regions[1].Locked = true },
+			overrides: map[string]string{"tagline": "Mine"},
+			want:      "<b></b>,  at Acme &amp; Co\nBuilding Acme &amp; Co since 2010\n<a href=\"tel:\"></a>\n",
+		},
+		{
+			name:      "override of a region removed since",
+			modify:    func(t *Template) { t.Regions = t.Regions[:1] },
+			overrides: map[string]string{"tagline": "Mine"},
+			want:      "<b></b>,  at Acme &amp; Co\n",
+		},
+		{
+			name:   "field the master now sets",
+			modify: func(t *Template) { t.Values["name"] = "Team Acme" },
+			fields: map[string]string{"name": "Jane"},
+			want:   "<b>Team Acme</b>,  at Acme &amp; Co\nBuilding Acme &amp; Co since 2010\n<a href=\"tel:\"></a>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := testTemplate()
+			if tt.modify != nil {
+				tt.modify(tmpl)
+			}
+			if got := tmpl.Render(tt.fields, tt.overrides); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	got := Fields(map[string]interface{}{"name": "Jane", "social": []interface{}{"x"}, "age": 40})
+	if !reflect.DeepEqual(got, map[string]string{"name": "Jane"}) {
+		t.Errorf("Fields() = %v, want only string values", got)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"email-signature-backend/scim"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SCIMAuthenticate checks the bearer token an IdP provisions with and sets
+// the organization it belongs to, and the admin who created it, in the
+// request context
+func SCIMAuthenticate(c *fiber.Ctx) error {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return scimUnauthorized(c)
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	var organizationID, createdBy string
+	err := database.DB.QueryRow(
+		context.Background(),
+		`UPDATE scim_tokens SET last_used_at = NOW() WHERE token_hash = $1
+         RETURNING organization_id, created_by`,
+		scim.HashToken(token),
+	).Scan(&organizationID, &createdBy)
+	if err != nil {
+		return scimUnauthorized(c)
+	}
+
+	c.Locals("organization_id", organizationID)
+	c.Locals("user_id", createdBy)
+
+	return c.Next()
+}
+
+func scimUnauthorized(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, scim.ContentType)
+	return c.Status(fiber.StatusUnauthorized).JSON(scim.Error{
+		Schemas: []string{scim.SchemaError},
+		Status:  "401",
+		Detail:  "Missing or invalid token",
+	}, scim.ContentType)
+}
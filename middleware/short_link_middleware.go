@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"email-signature-backend/config"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShortLinks serves requests to the short link domain from the tracked
+// link redirect, so https://<short domain>/<code> works like /r/<code>
+func ShortLinks(c *fiber.Ctx) error {
+	host := config.ShortLinkHost()
+	if host != "" && strings.EqualFold(c.Hostname(), host) && !strings.HasPrefix(c.Path(), "/r/") {
+		c.Path("/r" + c.Path())
+	}
+	return c.Next()
+}
@@ -0,0 +1,166 @@
+package roster
+
+import (
+	"email-signature-backend/social"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// Signature fields roster columns can be mapped to. Social networks are
+// mapped by their catalog key, e.g. "linkedin".
+const (
+	FieldEmail    = "email"
+	FieldName     = "name"
+	FieldJobTitle = "job_title"
+	FieldCompany  = "company"
+	FieldPhone    = "phone"
+	FieldWebsite  = "website"
+)
+
+// Fields lists the plain text fields in template data order
+var Fields = []string{FieldEmail, FieldName, FieldJobTitle, FieldCompany, FieldPhone, FieldWebsite}
+
+// Mapping maps signature fields to the roster column holding them
+type Mapping map[string]string
+
+// Validate checks that every mapped field is known and that the required
+// email and name fields are mapped
+func (m Mapping) Validate() error {
+	for field, column := range m {
+		if !knownField(field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if strings.TrimSpace(column) == "" {
+			return fmt.Errorf("field %q is mapped to an empty column", field)
+		}
+	}
+	for _, field := range []string{FieldEmail, FieldName} {
+		if m[field] == "" {
+			return fmt.Errorf("field %q must be mapped", field)
+		}
+	}
+	return nil
+}
+
+func knownField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	_, ok := social.Lookup(field)
+	return ok
+}
+
+// DefaultMapping maps headers that match a field name, ignoring case and
+// treating spaces as underscores, e.g. "Job Title" to job_title
+func DefaultMapping(headers []string) Mapping {
+	mapping := Mapping{}
+	for _, header := range headers {
+		field := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(header)), " ", "_")
+		if knownField(field) {
+			if _, taken := mapping[field]; !taken {
+				mapping[field] = header
+			}
+		}
+	}
+	return mapping
+}
+
+// Employee is one validated roster row
+type Employee struct {
+	Line  int    `json:"row"`
+	Email string `json:"email"`
+	// TemplateData is the signature data built from the row
+	TemplateData map[string]interface{} `json:"-"`
+	Errors       []string               `json:"errors,omitempty"`
+}
+
+// Employees applies the mapping to every row of the sheet. Rows are
+// validated independently, so every problem in the file is reported at
+// once; repeated emails are reported on each row after the first.
+func (m Mapping) Employees(sheet *Sheet) ([]Employee, error) {
+	columns := map[string]int{}
+	for field, column := range m {
+		index := headerIndex(sheet.Headers, column)
+		if index < 0 {
+			return nil, fmt.Errorf("column %q for field %q is not in the file", column, field)
+		}
+		columns[field] = index
+	}
+
+	employees := make([]Employee, 0, len(sheet.Rows))
+	firstLine := map[string]int{}
+	for _, row := range sheet.Rows {
+		employee := m.employee(row, columns)
+		if employee.Email != "" {
+			if line, seen := firstLine[employee.Email]; seen {
+				employee.Errors = append(employee.Errors, fmt.Sprintf("email %s is also on row %d", employee.Email, line))
+			} else {
+				firstLine[employee.Email] = row.Line
+			}
+		}
+		employees = append(employees, employee)
+	}
+	return employees, nil
+}
+
+func (m Mapping) employee(row Row, columns map[string]int) Employee {
+	cell := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(row.Cells) {
+			return ""
+		}
+		return strings.TrimSpace(row.Cells[index])
+	}
+
+	employee := Employee{Line: row.Line, TemplateData: map[string]interface{}{}}
+
+	email := cell(FieldEmail)
+	if address, err := mail.ParseAddress(email); email == "" || err != nil || address.Address != email {
+		employee.Errors = append(employee.Errors, fmt.Sprintf("email %q is not a valid email address", email))
+	} else {
+		employee.Email = strings.ToLower(email)
+	}
+	if cell(FieldName) == "" {
+		employee.Errors = append(employee.Errors, "name is required")
+	}
+	if website := cell(FieldWebsite); website != "" {
+		if u, err := url.Parse(website); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			employee.Errors = append(employee.Errors, fmt.Sprintf("website %q must be an absolute http(s) URL", website))
+		}
+	}
+
+	for _, field := range Fields {
+		if value := cell(field); value != "" {
+			employee.TemplateData[field] = value
+		}
+	}
+	if employee.Email != "" {
+		employee.TemplateData[FieldEmail] = employee.Email
+	}
+
+	// Social columns become the ordered social list, in catalog order
+	var links []interface{}
+	for _, network := range social.Catalog {
+		if value := cell(network.Key); value != "" {
+			links = append(links, map[string]interface{}{"network": network.Key, "url": value})
+		}
+	}
+	if len(links) > 0 {
+		employee.TemplateData["social"] = links
+		employee.Errors = append(employee.Errors, social.Validate(employee.TemplateData)...)
+	}
+	return employee
+}
+
+func headerIndex(headers []string, column string) int {
+	for i, header := range headers {
+		if strings.EqualFold(header, strings.TrimSpace(column)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,138 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxFileBytes is the largest roster file accepted for import
+const MaxFileBytes = 2 << 20
+
+// MaxRows is the most employees imported in one file
+const MaxRows = 5000
+
+var (
+	ErrTooLarge    = fmt.Errorf("file exceeds the %d byte import limit", MaxFileBytes)
+	ErrTooManyRows = fmt.Errorf("file has more than %d rows", MaxRows)
+	ErrEmpty       = errors.New("file has no header row")
+)
+
+// Sheet is a parsed roster: a header row and the data rows below it
+type Sheet struct {
+	Headers []string
+	// Rows hold the cells of each data row; Line is its 1-based line in the
+	// file, so errors can point at the spreadsheet row
+	Rows []Row
+}
+
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// xlsxMagic is the zip signature every XLSX file starts with
+var xlsxMagic = []byte("PK\x03\x04")
+
+// Parse reads a CSV or XLSX roster. The format is detected from the file
+// contents; XLSX rosters are read from their first sheet.
+func Parse(data []byte) (*Sheet, error) {
+	if len(data) > MaxFileBytes {
+		return nil, ErrTooLarge
+	}
+
+	var records []Row
+	var err error
+	if bytes.HasPrefix(data, xlsxMagic) {
+		records, err = readXLSX(data)
+	} else {
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The first non-blank row is the header
+	start := 0
+	for start < len(records) && blank(records[start].Cells) {
+		start++
+	}
+	if start == len(records) {
+		return nil, ErrEmpty
+	}
+
+	sheet := &Sheet{}
+	for _, header := range records[start].Cells {
+		sheet.Headers = append(sheet.Headers, strings.TrimSpace(header))
+	}
+	for _, record := range records[start+1:] {
+		if blank(record.Cells) {
+			continue
+		}
+		if len(sheet.Rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+		sheet.Rows = append(sheet.Rows, record)
+	}
+	return sheet, nil
+}
+
+func readCSV(data []byte) ([]Row, error) {
+	// Spreadsheet exports often start with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		// The reader skips empty lines, so ask it where the record started
+		line, _ := reader.FieldPos(0)
+		records = append(records, Row{Line: line, Cells: record})
+	}
+}
+
+func readXLSX(data []byte) ([]Row, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmpty
+	}
+	rows, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+
+	records := make([]Row, 0, len(rows))
+	for i, cells := range rows {
+		records = append(records, Row{Line: i + 1, Cells: cells})
+	}
+	return records, nil
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package roster
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseCSV(t *testing.T) {
+	data := "\xef\xbb\xbf\n Email , Name,Job Title\njane@example.com,Jane Doe,CTO\n,,\n\njohn@example.com,John\n"
+	sheet, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"Email", "Name", "Job Title"}; !reflect.DeepEqual(sheet.Headers, want) {
+		t.Errorf("headers = %q, want %q", sheet.Headers, want)
+	}
+	// Blank rows are skipped and lines point at the row in the file
+	want := []Row{{Line: 3, Cells: []string{"jane@example.com", "Jane Doe", "CTO"}}, {Line: 6, Cells: []string{"john@example.com", "John"}}}
+	if !reflect.DeepEqual(sheet.Rows, want) {
+		t.Errorf("rows = %+v, want %+v", sheet.Rows, want)
+	}
+}
+
+func TestParseXLSX(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+	file.SetSheetRow("Sheet1", "A1", &[]interface{}{"email", "name"})
+	file.SetSheetRow("Sheet1", "A3", &[]interface{}{"jane@example.com", "Jane Doe"})
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("write XLSX: %v", err)
+	}
+
+	sheet, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(sheet.Rows) != 1 || sheet.Rows[0].Line != 3 || sheet.Rows[0].Cells[1] != "Jane Doe" {
+		t.Errorf("sheet = %+v", sheet)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tooMany := "email,name\n" + strings.Repeat("a@example.com,A\n", MaxRows+1)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", []byte("\n , \n"), ErrEmpty},
+		{"too large", make([]byte, MaxFileBytes+1), ErrTooLarge},
+		{"too many rows", []byte(tooMany), ErrTooManyRows},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := Parse([]byte("email,name\n\"unterminated,A\n")); err == nil {
+		t.Errorf("invalid CSV parsed without error")
+	}
+	if _, err := Parse([]byte("PK\x03\x04 not a zip")); err == nil {
+		t.Errorf("invalid XLSX parsed without error")
+	}
+}
+
+func TestDefaultMapping(t *testing.T) {
+	got := DefaultMapping([]string{"E-mail", "Email", "NAME", " Job Title ", "LinkedIn", "email", "Department"})
+	want := Mapping{FieldEmail: "Email", FieldName: "NAME", FieldJobTitle: " Job Title ", "linkedin": "LinkedIn"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultMapping = %v, want %v", got, want)
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	tests := []struct {
+		mapping Mapping
+		wantErr bool
+	}{
+		{Mapping{FieldEmail: "Email", FieldName: "Name", "github": "GitHub"}, false},
+		{Mapping{FieldEmail: "Email"}, true},
+		{Mapping{FieldEmail: "Email", FieldName: "Name", "department": "Dept"}, true},
+		{Mapping{FieldEmail: "Email", FieldName: " "}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.mapping.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%v) = %v, want error %v", tt.mapping, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEmployees(t *testing.T) {
+	sheet := &Sheet{
+		Headers: []string{"Work Email", "Full Name", "Site", "LinkedIn", "X"},
+		Rows: []Row{
+			{Line: 2, Cells: []string{" Jane.Doe@Example.COM ", "Jane Doe", "https://example.com", "", "https://x.com/jane"}},
+			{Line: 3, Cells: []string{"jane.doe@example.com", "Jane Again"}},
+			{Line: 4, Cells: []string{"not an email", "", "example.com", "https://example.com/jane"}},
+			{Line: 5, Cells: []string{"Jane Doe <jane@example.com>", "Jane"}},
+		},
+	}
+	mapping := Mapping{FieldEmail: "work email", FieldName: "Full Name", FieldWebsite: "Site", "linkedin": "LinkedIn", "x": "X"}
+
+	employees, err := mapping.Employees(sheet)
+	if err != nil {
+		t.Fatalf("Employees: %v", err)
+	}
+
+	jane := employees[0]
+	if jane.Email != "jane.doe@example.com" || len(jane.Errors) != 0 {
+		t.Errorf("employee = %+v, want the email normalised to lower case", jane)
+	}
+	wantData := map[string]interface{}{
+		FieldEmail:   "jane.doe@example.com",
+		FieldName:    "Jane Doe",
+		FieldWebsite: "https://example.com",
+		"social":     []interface{}{map[string]interface{}{"network": "x", "url": "https://x.com/jane"}},
+	}
+	if !reflect.DeepEqual(jane.TemplateData, wantData) {
+		t.Errorf("template data = %v, want %v", jane.TemplateData, wantData)
+	}
+
+	if want := []string{"email jane.doe@example.com is also on row 2"}; !reflect.DeepEqual(employees[1].Errors, want) {
+		t.Errorf("repeated email errors = %q, want %q", employees[1].Errors, want)
+	}
+
+	invalid := employees[2]
+	if invalid.Email != "" || len(invalid.Errors) != 4 {
+		t.Errorf("invalid row errors = %q, want email, name, website and linkedin errors", invalid.Errors)
+	}
+
+	// Only bare addresses are accepted, not display name forms
+	if employees[3].Email != "" || len(employees[3].Errors) != 1 {
+		t.Errorf("display name address = %+v", employees[3])
+	}
+
+	missing := Mapping{FieldEmail: "Email", FieldName: "Full Name"}
+	if _, err := missing.Employees(sheet); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("%q", "Email")) {
+		t.Errorf("err = %v, want the missing column reported", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 )
 
 func SetupRoutes(app *fiber.App) {
+	// Requests to the short link domain go to the tracked link redirect
+	app.Use(middleware.ShortLinks)
+
 	api := app.Group("/api")
 
 	// Authentication routes
@@ -16,7 +19,13 @@ func SetupRoutes(app *fiber.App) {
 	// Protected routes
 	api.Post("/signature", middleware.Authenticate, handlers.CreateSignature)
 	api.Get("/signature/:id/preview", middleware.Authenticate, handlers.PreviewSignature)
+	api.Get("/signature/:id/preview/dark", middleware.Authenticate, handlers.PreviewSignatureDarkMode)
 	api.Get("/signature/:id/export", middleware.Authenticate, handlers.ExportSignature)
+	api.Get("/signature/:id/lint", middleware.Authenticate, handlers.LintSignature)
+	api.Put("/signature/:id/brand-kit", middleware.Authenticate, handlers.SetSignatureBrandKit)
+	api.Put("/signature/:id/open-tracking", middleware.Authenticate, handlers.SetOpenTracking)
+	api.Get("/signature/:id/opens", middleware.Authenticate, handlers.GetOpenStats)
+	api.Put("/signature/:id/utm", middleware.Authenticate, handlers.SetSignatureUTM)
 	api.Get("/signatures", middleware.Authenticate, handlers.GetAllSignatures)           // Get all signatures
 	api.Delete("/signature/:id", middleware.Authenticate, handlers.DeleteSignature)      // Delete a specific signature
 	api.Get("/analytics/count", middleware.Authenticate, handlers.CountAnalyticsEntries) // Total analytics entries
@@ -24,9 +33,120 @@ func SetupRoutes(app *fiber.App) {
 	api.Get("/links/count", middleware.Authenticate, handlers.CountLinks)                // Total links
 
 	api.Post("/links", middleware.Authenticate, handlers.CreateLink)
+	api.Get("/links", middleware.Authenticate, handlers.GetLinks)
+	api.Get("/links/:id", middleware.Authenticate, handlers.GetLink)
+	api.Put("/links/:id", middleware.Authenticate, handlers.UpdateLink)
+	api.Delete("/links/:id", middleware.Authenticate, handlers.DeleteLink)
+	api.Post("/links/:id/archive", middleware.Authenticate, handlers.ArchiveLink)
+	api.Post("/links/:id/restore", middleware.Authenticate, handlers.RestoreLink)
 	api.Post("/track", middleware.Authenticate, handlers.TrackClick)
 	api.Get("/analytics", middleware.Authenticate, handlers.GetAnalytics)
 
+	// Bulk imports from employee rosters
+	api.Post("/signatures/import", middleware.Authenticate, handlers.ImportSignatures)
+	api.Post("/import-mappings", middleware.Authenticate, handlers.CreateImportMapping)
+	api.Get("/import-mappings", middleware.Authenticate, handlers.GetImportMappings)
+	api.Delete("/import-mappings/:id", middleware.Authenticate, handlers.DeleteImportMapping)
+
+	// Directory sync
+	api.Post("/directories", middleware.Authenticate, handlers.CreateDirectory)
+	api.Get("/directories", middleware.Authenticate, handlers.GetDirectories)
+	api.Delete("/directories/:id", middleware.Authenticate, handlers.DeleteDirectory)
+	api.Post("/directories/:id/sync", middleware.Authenticate, handlers.SyncDirectory)
+	api.Get("/directories/:id/runs", middleware.Authenticate, handlers.GetDirectorySyncRuns)
+
+	// Deploying signatures to mailboxes
+	api.Post("/signature/:id/deploy", middleware.Authenticate, handlers.DeploySignature)
+	api.Get("/deploy-jobs/:id", middleware.Authenticate, handlers.GetDeployJob)
+	api.Post("/deploy-jobs/:id/retry", middleware.Authenticate, handlers.RetryDeployJob)
+
+	// Banner campaigns
+	api.Post("/campaigns", middleware.Authenticate, handlers.CreateCampaign)
+	api.Get("/campaigns", middleware.Authenticate, handlers.GetCampaigns)
+	api.Get("/campaigns/:id", middleware.Authenticate, handlers.GetCampaign)
+	api.Put("/campaigns/:id", middleware.Authenticate, handlers.UpdateCampaign)
+	api.Delete("/campaigns/:id", middleware.Authenticate, handlers.DeleteCampaign)
+
+	// A/B test variants
+	api.Post("/signature/:id/variants", middleware.Authenticate, handlers.CreateVariant)
+	api.Get("/signature/:id/variants", middleware.Authenticate, handlers.GetVariants)
+	api.Get("/signature/:id/variants/report", middleware.Authenticate, handlers.GetVariantReport)
+	api.Put("/signature/:id/variants/:variantId", middleware.Authenticate, handlers.UpdateVariant)
+	api.Delete("/signature/:id/variants/:variantId", middleware.Authenticate, handlers.DeleteVariant)
+
+	// Public redirect behind tracked links; /c/ serves campaign banner
+	// links exported before /r/ existed
+	app.Get("/r/:code", handlers.FollowLink)
+	app.Get("/c/:code", handlers.FollowLink)
+	app.Get("/r/:prefix/:slug", handlers.FollowVanityLink)
+
+	// Public tracking pixel of signatures that track opens
+	app.Get("/o/:id", handlers.OpenPixel)
+
+	// Organizations
+	api.Post("/organizations", middleware.Authenticate, handlers.CreateOrganization)
+	api.Get("/organizations", middleware.Authenticate, handlers.GetOrganizations)
+	api.Post("/organizations/:id/members", middleware.Authenticate, handlers.AddOrganizationMember)
+	api.Put("/organizations/:id/defaults", middleware.Authenticate, handlers.UpdateOrganizationDefaults)
+	api.Put("/organizations/:id/retention", middleware.Authenticate, handlers.SetOrganizationRetention)
+	api.Post("/organizations/:id/deploy", middleware.Authenticate, handlers.DeployOrganization)
+
+	// Master templates
+	api.Post("/organizations/:id/master-templates", middleware.Authenticate, handlers.CreateMasterTemplate)
+	api.Get("/organizations/:id/master-templates", middleware.Authenticate, handlers.GetMasterTemplates)
+	api.Get("/master-templates/:id", middleware.Authenticate, handlers.GetMasterTemplate)
+	api.Put("/master-templates/:id", middleware.Authenticate, handlers.UpdateMasterTemplate)
+	api.Delete("/master-templates/:id", middleware.Authenticate, handlers.DeleteMasterTemplate)
+	api.Post("/master-templates/:id/signatures", middleware.Authenticate, handlers.CreateMasterSignature)
+	api.Put("/signature/:id/fields", middleware.Authenticate, handlers.UpdateSignatureFields)
+
+	// Disclaimers and conditional footer content
+	api.Post("/organizations/:id/disclaimers", middleware.Authenticate, handlers.CreateDisclaimer)
+	api.Get("/organizations/:id/disclaimers", middleware.Authenticate, handlers.GetDisclaimers)
+	api.Get("/disclaimers/:id", middleware.Authenticate, handlers.GetDisclaimer)
+	api.Put("/disclaimers/:id", middleware.Authenticate, handlers.UpdateDisclaimer)
+	api.Delete("/disclaimers/:id", middleware.Authenticate, handlers.DeleteDisclaimer)
+	api.Put("/signature/:id/content-rules", middleware.Authenticate, handlers.SetSignatureContentRules)
+
+	api.Post("/organizations/:id/scim-tokens", middleware.Authenticate, handlers.CreateSCIMToken)
+	api.Get("/organizations/:id/scim-tokens", middleware.Authenticate, handlers.GetSCIMTokens)
+	api.Delete("/organizations/:id/scim-tokens/:tokenId", middleware.Authenticate, handlers.DeleteSCIMToken)
+
+	// Brand kits
+	api.Post("/brand-kits", middleware.Authenticate, handlers.CreateBrandKit)
+	api.Get("/brand-kits", middleware.Authenticate, handlers.GetBrandKits)
+	api.Get("/brand-kits/:id", middleware.Authenticate, handlers.GetBrandKit)
+	api.Put("/brand-kits/:id", middleware.Authenticate, handlers.UpdateBrandKit)
+	api.Delete("/brand-kits/:id", middleware.Authenticate, handlers.DeleteBrandKit)
+	api.Post("/brand-kits/:id/preview", middleware.Authenticate, handlers.PreviewBrandKit)
+
+	// Assets
+	api.Post("/assets", middleware.Authenticate, handlers.UploadAsset)
+	api.Get("/assets", middleware.Authenticate, handlers.GetAssets)
+	api.Delete("/assets/:id", middleware.Authenticate, handlers.DeleteAsset)
+
+	// Public asset URLs referenced from signatures
+	app.Get("/assets/:id/:file", handlers.ServeAsset)
+
+	// Social network catalog and the icons it references
+	api.Get("/social-networks", handlers.GetSocialNetworks)
+	app.Get("/icons/:style/:size/:file", handlers.ServeSocialIcon)
+
+	// SCIM 2.0 provisioning, authenticated with organization SCIM tokens
+	scim := app.Group("/scim/v2", middleware.SCIMAuthenticate)
+	scim.Get("/ServiceProviderConfig", handlers.GetSCIMServiceProviderConfig)
+	scim.Get("/Users", handlers.ListSCIMUsers)
+	scim.Post("/Users", handlers.CreateSCIMUser)
+	scim.Get("/Users/:id", handlers.GetSCIMUser)
+	scim.Put("/Users/:id", handlers.ReplaceSCIMUser)
+	scim.Patch("/Users/:id", handlers.PatchSCIMUser)
+	scim.Delete("/Users/:id", handlers.DeleteSCIMUser)
+	scim.Get("/Groups", handlers.ListSCIMGroups)
+	scim.Post("/Groups", handlers.CreateSCIMGroup)
+	scim.Get("/Groups/:id", handlers.GetSCIMGroup)
+	scim.Patch("/Groups/:id", handlers.PatchSCIMGroup)
+	scim.Delete("/Groups/:id", handlers.DeleteSCIMGroup)
+
 	// Swagger routes
 	SetupSwaggerRoutes(app)
 }
@@ -0,0 +1,313 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type PatchRequest struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// Operation is one PATCH operation. Op is add, replace or remove; IdPs
+// differ in capitalisation, so it is compared case-insensitively.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// PatchError is a PATCH operation that cannot be applied
+type PatchError struct {
+	ScimType string
+	Detail   string
+}
+
+func (e *PatchError) Error() string {
+	return e.Detail
+}
+
+func invalidPath(path string) error {
+	return &PatchError{ScimType: ErrorInvalidPath, Detail: fmt.Sprintf("unsupported path %q", path)}
+}
+
+func invalidValue(path string) error {
+	return &PatchError{ScimType: ErrorInvalidValue, Detail: fmt.Sprintf("invalid value for %q", path)}
+}
+
+// valuePathPattern matches paths selecting one entry of a multi-valued
+// attribute, e.g. emails[type eq "work"].value
+var valuePathPattern = regexp.MustCompile(`(?i)^(\w+)\[(\w+) eq "([^"]*)"\](?:\.(\w+))?$`)
+
+// Apply applies PATCH operations to the user
+func (u *User) Apply(operations []Operation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return &PatchError{ScimType: ErrorInvalidSyntax, Detail: fmt.Sprintf("unsupported op %q", operation.Op)}
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return &PatchError{ScimType: ErrorInvalidPath, Detail: "remove requires a path"}
+			}
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				return invalidValue("")
+			}
+			for path, value := range values {
+				if err := u.set(path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		value := operation.Value
+		if op == "remove" {
+			value = nil
+		}
+		if err := u.set(operation.Path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set sets the attribute at path to the JSON value; a nil value removes it
+func (u *User) set(path string, value json.RawMessage) error {
+	// Extension attributes may be addressed by their full URN
+	path = strings.TrimPrefix(path, SchemaUser+":")
+	if strings.HasPrefix(path, SchemaEnterpriseUser) {
+		attribute := strings.TrimPrefix(strings.TrimPrefix(path, SchemaEnterpriseUser), ":")
+		if attribute == "" {
+			var enterprise EnterpriseUser
+			if value != nil && json.Unmarshal(value, &enterprise) != nil {
+				return invalidValue(path)
+			}
+			u.Enterprise = &enterprise
+			return nil
+		}
+		if !strings.EqualFold(attribute, "department") {
+			return invalidPath(path)
+		}
+		if u.Enterprise == nil {
+			u.Enterprise = &EnterpriseUser{}
+		}
+		return setString(&u.Enterprise.Department, path, value)
+	}
+
+	if match := valuePathPattern.FindStringSubmatch(path); match != nil {
+		var target *[]MultiValue
+		switch strings.ToLower(match[1]) {
+		case "emails":
+			target = &u.Emails
+		case "phonenumbers":
+			target = &u.PhoneNumbers
+		default:
+			return invalidPath(path)
+		}
+		if !strings.EqualFold(match[2], "type") || (match[4] != "" && !strings.EqualFold(match[4], "value")) {
+			return invalidPath(path)
+		}
+		return setTyped(target, match[3], path, value)
+	}
+
+	switch strings.ToLower(path) {
+	case "username":
+		return setString(&u.UserName, path, value)
+	case "externalid":
+		return setString(&u.ExternalID, path, value)
+	case "displayname":
+		return setString(&u.DisplayName, path, value)
+	case "title":
+		return setString(&u.Title, path, value)
+	case "active":
+		return u.setActive(path, value)
+	case "name":
+		var name Name
+		if value != nil && json.Unmarshal(value, &name) != nil {
+			return invalidValue(path)
+		}
+		u.Name = &name
+		return nil
+	case "name.givenname", "name.familyname", "name.formatted":
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		field := map[string]*string{
+			"name.givenname":  &u.Name.GivenName,
+			"name.familyname": &u.Name.FamilyName,
+			"name.formatted":  &u.Name.Formatted,
+		}[strings.ToLower(path)]
+		return setString(field, path, value)
+	case "emails":
+		return setList(&u.Emails, path, value)
+	case "phonenumbers":
+		return setList(&u.PhoneNumbers, path, value)
+	}
+	return invalidPath(path)
+}
+
+// setActive accepts JSON booleans and the "True"/"False" strings some IdPs
+// send
+func (u *User) setActive(path string, value json.RawMessage) error {
+	if value == nil {
+		return invalidValue(path)
+	}
+	var active bool
+	if err := json.Unmarshal(value, &active); err != nil {
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			return invalidValue(path)
+		}
+		parsed, err := strconv.ParseBool(strings.ToLower(text))
+		if err != nil {
+			return invalidValue(path)
+		}
+		active = parsed
+	}
+	u.Active = &active
+	return nil
+}
+
+func setString(field *string, path string, value json.RawMessage) error {
+	if value == nil {
+		*field = ""
+		return nil
+	}
+	if json.Unmarshal(value, field) != nil {
+		return invalidValue(path)
+	}
+	return nil
+}
+
+func setList(field *[]MultiValue, path string, value json.RawMessage) error {
+	if value == nil {
+		*field = nil
+		return nil
+	}
+	var list []MultiValue
+	if json.Unmarshal(value, &list) != nil {
+		return invalidValue(path)
+	}
+	*field = list
+	return nil
+}
+
+// setTyped sets or removes the entry of the given type in a multi-valued
+// attribute
+func setTyped(field *[]MultiValue, kind, path string, value json.RawMessage) error {
+	var text string
+	if value != nil && json.Unmarshal(value, &text) != nil {
+		return invalidValue(path)
+	}
+
+	list := []MultiValue{}
+	found := false
+	for _, entry := range *field {
+		if strings.EqualFold(entry.Type, kind) {
+			found = true
+			if value == nil {
+				continue
+			}
+			entry.Value = text
+		}
+		list = append(list, entry)
+	}
+	if !found && value != nil {
+		list = append(list, MultiValue{Value: text, Type: kind})
+	}
+	*field = list
+	return nil
+}
+
+// memberPathPattern matches paths selecting one group member, e.g.
+// members[value eq "2819c223"]
+var memberPathPattern = regexp.MustCompile(`(?i)^members\[value eq "([^"]*)"\]$`)
+
+// Apply applies PATCH operations to the group's display name and members
+func (g *Group) Apply(operations []Operation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		path := strings.ToLower(operation.Path)
+
+		switch {
+		case path == "displayname" && op != "remove":
+			if err := setString(&g.DisplayName, operation.Path, operation.Value); err != nil {
+				return err
+			}
+		case path == "" && op != "remove":
+			var values struct {
+				DisplayName *string       `json:"displayName"`
+				Members     *[]MultiValue `json:"members"`
+			}
+			if json.Unmarshal(operation.Value, &values) != nil {
+				return invalidValue("")
+			}
+			if values.DisplayName != nil {
+				g.DisplayName = *values.DisplayName
+			}
+			if values.Members != nil {
+				g.setMembers(op, *values.Members)
+			}
+		case path == "members":
+			var members []MultiValue
+			if operation.Value != nil && json.Unmarshal(operation.Value, &members) != nil {
+				return invalidValue(operation.Path)
+			}
+			if op == "remove" {
+				if operation.Value == nil {
+					g.Members = nil
+				} else {
+					g.removeMembers(members)
+				}
+			} else {
+				g.setMembers(op, members)
+			}
+		case op == "remove" && memberPathPattern.MatchString(operation.Path):
+			id := memberPathPattern.FindStringSubmatch(operation.Path)[1]
+			g.removeMembers([]MultiValue{{Value: id}})
+		default:
+			return invalidPath(operation.Path)
+		}
+	}
+	return nil
+}
+
+// setMembers adds members, or replaces them all for a replace op
+func (g *Group) setMembers(op string, members []MultiValue) {
+	if op == "replace" {
+		g.Members = nil
+	}
+	for _, member := range members {
+		exists := false
+		for _, current := range g.Members {
+			if current.Value == member.Value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			g.Members = append(g.Members, MultiValue{Value: member.Value})
+		}
+	}
+}
+
+func (g *Group) removeMembers(members []MultiValue) {
+	remove := map[string]bool{}
+	for _, member := range members {
+		remove[member.Value] = true
+	}
+	kept := []MultiValue{}
+	for _, member := range g.Members {
+		if !remove[member.Value] {
+			kept = append(kept, member)
+		}
+	}
+	g.Members = kept
+}
@@ -0,0 +1,192 @@
+package scim
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema URNs from RFC 7643 and RFC 7644
+const (
+	SchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceConfig  = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of SCIM requests and responses
+const ContentType = "application/scim+json"
+
+// MaxResults caps the page size of list responses
+const MaxResults = 200
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails or
+// group members
+type MultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type EnterpriseUser struct {
+	Department string `json:"department,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+type User struct {
+	Schemas      []string        `json:"schemas"`
+	ID           string          `json:"id,omitempty"`
+	ExternalID   string          `json:"externalId,omitempty"`
+	UserName     string          `json:"userName"`
+	Name         *Name           `json:"name,omitempty"`
+	DisplayName  string          `json:"displayName,omitempty"`
+	Title        string          `json:"title,omitempty"`
+	Active       *bool           `json:"active,omitempty"`
+	Emails       []MultiValue    `json:"emails,omitempty"`
+	PhoneNumbers []MultiValue    `json:"phoneNumbers,omitempty"`
+	Enterprise   *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Groups       []MultiValue    `json:"groups,omitempty"`
+	Meta         *Meta           `json:"meta,omitempty"`
+}
+
+// IsActive reports whether the user is active; users are active unless
+// the IdP says otherwise
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Email returns the primary email, else the work email, else the first
+func (u *User) Email() string {
+	return preferred(u.Emails)
+}
+
+// Phone returns the primary phone number, else the work one, else the first
+func (u *User) Phone() string {
+	return preferred(u.PhoneNumbers)
+}
+
+func preferred(values []MultiValue) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+	for _, v := range values {
+		if strings.EqualFold(v.Type, "work") {
+			return v.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// NewListResponse wraps one page of resources
+func NewListResponse(resources interface{}, total, startIndex, count int) ListResponse {
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+// Error is the SCIM error response body
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// SCIM error types
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorUniqueness    = "uniqueness"
+)
+
+// Filter is an equality filter, the only kind IdPs use when provisioning,
+// e.g. userName eq "jane@example.com"
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+var ErrInvalidFilter = errors.New(`only filters of the form attribute eq "value" are supported`)
+
+var filterPattern = regexp.MustCompile(`(?i)^\s*([A-Za-z][\w.:]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// ParseFilter parses an equality filter; an empty filter matches everything
+// and returns nil
+func ParseFilter(raw string) (*Filter, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	match := filterPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return nil, ErrInvalidFilter
+	}
+	value := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(match[2])
+	return &Filter{Attribute: match[1], Value: value}, nil
+}
+
+// tokenPrefix marks SCIM bearer tokens so they are easy to recognise in
+// IdP settings and secret scanners
+const tokenPrefix = "scim_"
+
+// NewToken returns a random bearer token for an IdP
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hash tokens are stored and looked up by
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		raw       string
+		attribute string
+		value     string
+		wantErr   bool
+	}{
+		{`userName eq "jane@example.com"`, "userName", "jane@example.com", false},
+		{`  externalId EQ "a\"b\\c"  `, "externalId", `a"b\c`, false},
+		{`emails.value eq "x@example.com"`, "emails.value", "x@example.com", false},
+		{`userName sw "jane"`, "", "", true},
+		{`userName eq "a" and active eq "true"`, "", "", true},
+		{`userName eq jane`, "", "", true},
+	}
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.raw)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter(%q): err = %v, want ErrInvalidFilter", tt.raw, err)
+			}
+			continue
+		}
+		if err != nil || filter.Attribute != tt.attribute || filter.Value != tt.value {
+			t.Errorf("ParseFilter(%q) = %+v, %v, want %s %q", tt.raw, filter, err, tt.attribute, tt.value)
+		}
+	}
+
+	if filter, err := ParseFilter(" "); filter != nil || err != nil {
+		t.Errorf("empty filter = %+v, %v, want nil", filter, err)
+	}
+}
+
+func TestUserPreferredValues(t *testing.T) {
+	u := User{
+		Emails:       []MultiValue{{Value: "home@example.com", Type: "home"}, {Value: "work@example.com", Type: "Work"}},
+		PhoneNumbers: []MultiValue{{Value: "1", Type: "work"}, {Value: "2", Primary: true}},
+	}
+	if u.Email() != "work@example.com" {
+		t.Errorf("Email() = %s, want the work email", u.Email())
+	}
+	if u.Phone() != "2" {
+		t.Errorf("Phone() = %s, want the primary phone", u.Phone())
+	}
+	if !u.IsActive() {
+		t.Errorf("IsActive() = false, want users active unless the IdP says otherwise")
+	}
+}
+
+// operations decodes the Operations of a PATCH request body
+func operations(t *testing.T, body string) []Operation {
+	t.Helper()
+	var request PatchRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatalf("decode patch: %v", err)
+	}
+	return request.Operations
+}
+
+func TestUserApply(t *testing.T) {
+	u := User{
+		UserName: "jane@example.com",
+		Title:    "Engineer",
+		Emails:   []MultiValue{{Value: "jane@example.com", Type: "work", Primary: true}},
+	}
+	// Azure AD sends capitalised ops, "False" as a string and
+	// enterprise attributes by URN
+	err := u.Apply(operations(t, `{"Operations": [
+		{"op": "Replace", "path": "title", "value": "CTO"},
+		{"op": "Add", "path": "name.givenName", "value": "Jane"},
+		{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "jane.doe@example.com"},
+		{"op": "Add", "path": "phoneNumbers[type eq \"mobile\"].value", "value": "+1 555 0100"},
+		{"op": "Replace", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "Engineering"},
+		{"op": "Replace", "path": "active", "value": "False"},
+		{"op": "Replace", "value": {"displayName": "Jane Doe"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if u.Title != "CTO" || u.Name == nil || u.Name.GivenName != "Jane" || u.DisplayName != "Jane Doe" {
+		t.Errorf("user = %+v", u)
+	}
+	if u.Email() != "jane.doe@example.com" || len(u.Emails) != 1 || !u.Emails[0].Primary {
+		t.Errorf("emails = %+v, want the work email replaced in place", u.Emails)
+	}
+	if u.Phone() != "+1 555 0100" {
+		t.Errorf("phone = %s", u.Phone())
+	}
+	if u.Enterprise == nil || u.Enterprise.Department != "Engineering" {
+		t.Errorf("enterprise = %+v", u.Enterprise)
+	}
+	if u.IsActive() {
+		t.Errorf("IsActive() = true after active was set to \"False\"")
+	}
+
+	if err := u.Apply(operations(t, `{"Operations": [{"op": "remove", "path": "emails[type eq \"work\"]"}, {"op": "remove", "path": "title"}]}`)); err != nil {
+		t.Fatalf("Apply remove: %v", err)
+	}
+	if len(u.Emails) != 0 || u.Title != "" {
+		t.Errorf("after remove: emails = %+v, title = %q", u.Emails, u.Title)
+	}
+}
+
+func TestUserApplyErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		scimType string
+	}{
+		{"unknown op", `{"Operations": [{"op": "move", "path": "title", "value": "x"}]}`, ErrorInvalidSyntax},
+		{"unknown path", `{"Operations": [{"op": "replace", "path": "nickName", "value": "x"}]}`, ErrorInvalidPath},
+		{"remove without path", `{"Operations": [{"op": "remove"}]}`, ErrorInvalidPath},
+		{"wrong type", `{"Operations": [{"op": "replace", "path": "title", "value": 1}]}`, ErrorInvalidValue},
+		{"bad active", `{"Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`, ErrorInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := User{}
+			var patchErr *PatchError
+			if err := u.Apply(operations(t, tt.body)); !errors.As(err, &patchErr) || patchErr.ScimType != tt.scimType {
+				t.Errorf("err = %v, want a %s PatchError", err, tt.scimType)
+			}
+		})
+	}
+}
+
+func TestGroupApply(t *testing.T) {
+	g := Group{DisplayName: "Sales", Members: []MultiValue{{Value: "a"}, {Value: "b"}}}
+	err := g.Apply(operations(t, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "b"}, {"value": "c"}]},
+		{"op": "remove", "path": "members[value eq \"a\"]"},
+		{"op": "replace", "path": "displayName", "value": "Sales EMEA"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if g.DisplayName != "Sales EMEA" || members(g) != "b,c" {
+		t.Errorf("group = %s %s, want Sales EMEA b,c", g.DisplayName, members(g))
+	}
+
+	if err := g.Apply(operations(t, `{"Operations": [{"op": "replace", "value": {"members": [{"value": "d"}]}}]}`)); err != nil {
+		t.Fatalf("Apply replace: %v", err)
+	}
+	if members(g) != "d" {
+		t.Errorf("members = %s, want a replace to drop existing members", members(g))
+	}
+
+	if err := g.Apply(operations(t, `{"Operations": [{"op": "remove", "path": "members"}]}`)); err != nil {
+		t.Fatalf("Apply remove all: %v", err)
+	}
+	if len(g.Members) != 0 {
+		t.Errorf("members = %s, want none", members(g))
+	}
+
+	if err := g.Apply(operations(t, `{"Operations": [{"op": "remove", "path": "displayName"}]}`)); err == nil {
+		t.Errorf("removing the display name succeeded")
+	}
+}
+
+func members(g Group) string {
+	values := []string{}
+	for _, member := range g.Members {
+		values = append(values, member.Value)
+	}
+	return strings.Join(values, ",")
+}
+
+func TestTokens(t *testing.T) {
+	token, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) || len(token) != len(tokenPrefix)+64 {
+		t.Errorf("token = %s", token)
+	}
+	if other, _ := NewToken(); other == token {
+		t.Errorf("NewToken returned the same token twice")
+	}
+	if HashToken(token) != HashToken(token) || HashToken(token) == HashToken(token+"x") || strings.Contains(HashToken(token), token) {
+		t.Errorf("HashToken is not a stable hash of the token")
+	}
+}
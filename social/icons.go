@@ -0,0 +1,171 @@
+package social
+
+import (
+	"bytes"
+	"email-signature-backend/config"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/vector"
+)
+
+// Icon styles; they match the brand kit icon styles that render images
+const (
+	StyleColor = "color"
+	StyleMono  = "mono"
+)
+
+// Styles and Sizes are the icon variants that are served. Sizes are in
+// CSS pixels, the images themselves are twice as large for retina screens.
+var (
+	Styles = []string{StyleColor, StyleMono}
+	Sizes  = []int{16, 24, 32}
+)
+
+// DefaultSize is the icon size used when a signature does not pick one
+const DefaultSize = 24
+
+// monoColor is the color of mono icons
+const monoColor = "#4a4a4a"
+
+var (
+	ErrUnknownNetwork = errors.New("unknown social network")
+	ErrUnknownStyle   = errors.New("unknown icon style")
+	ErrUnknownSize    = errors.New("unknown icon size")
+)
+
+var iconCache sync.Map
+
+// IconURL returns the public URL of a network's icon. ICON_BASE_URL can
+// point at a CDN hosting icons with the same /{style}/{size}/{key}.png
+// layout; otherwise the generated icons served by this API are used.
+func IconURL(style string, size int, key string) string {
+	baseURL := strings.TrimRight(os.Getenv("ICON_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = config.PublicBaseURL() + "/icons"
+	}
+	return fmt.Sprintf("%s/%s/%d/%s.png", baseURL, style, size, key)
+}
+
+// ValidSize reports whether icons are served at the given size
+func ValidSize(size int) bool {
+	for _, s := range Sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Icon returns the PNG icon for a network, generating and caching it on
+// first use
+func Icon(style string, size int, key string) ([]byte, error) {
+	network, ok := Lookup(key)
+	if !ok {
+		return nil, ErrUnknownNetwork
+	}
+	if style != StyleColor && style != StyleMono {
+		return nil, ErrUnknownStyle
+	}
+	if !ValidSize(size) {
+		return nil, ErrUnknownSize
+	}
+
+	cacheKey := style + "/" + strconv.Itoa(size) + "/" + key
+	if cached, ok := iconCache.Load(cacheKey); ok {
+		return cached.([]byte), nil
+	}
+
+	fill := network.Color
+	if style == StyleMono {
+		fill = monoColor
+	}
+
+	data, err := drawIcon(shapes[key], parseHexColor(fill), size*2)
+	if err != nil {
+		return nil, err
+	}
+	iconCache.Store(cacheKey, data)
+	return data, nil
+}
+
+// drawIcon fills the logo shape in one color on a transparent square,
+// leaving a small margin around it
+func drawIcon(shape string, fill color.NRGBA, px int) ([]byte, error) {
+	margin := float32(px) / 16
+	rasterizer := vector.NewRasterizer(px, px)
+	if err := traceShape(rasterizer, shape, margin, (float32(px)-2*margin)/24); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, px, px))
+	rasterizer.Draw(img, img.Bounds(), image.NewUniform(fill), image.Point{})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// traceShape adds a shape's path to the rasterizer, scaled from the 24x24
+// box and offset by the margin
+func traceShape(rasterizer *vector.Rasterizer, shape string, margin, scale float32) error {
+	fields := strings.Fields(shape)
+	if len(fields) == 0 {
+		return errors.New("empty icon shape")
+	}
+	for i := 0; i < len(fields); i++ {
+		command := fields[i]
+		var arguments int
+		switch command {
+		case "M", "L":
+			arguments = 2
+		case "Q":
+			arguments = 4
+		case "Z":
+		default:
+			return fmt.Errorf("unknown icon shape command %q", command)
+		}
+		if i+arguments >= len(fields) {
+			return fmt.Errorf("icon shape command %q is missing coordinates", command)
+		}
+
+		points := make([]float32, arguments)
+		for k := range points {
+			i++
+			value, err := strconv.ParseFloat(fields[i], 32)
+			if err != nil {
+				return fmt.Errorf("invalid icon shape coordinate %q", fields[i])
+			}
+			points[k] = margin + float32(value)*scale
+		}
+		switch command {
+		case "M":
+			rasterizer.MoveTo(points[0], points[1])
+		case "L":
+			rasterizer.LineTo(points[0], points[1])
+		case "Q":
+			rasterizer.QuadTo(points[0], points[1], points[2], points[3])
+		case "Z":
+			rasterizer.ClosePath()
+		}
+	}
+	return nil
+}
+
+// parseHexColor parses #rrggbb, falling back to black
+func parseHexColor(hex string) color.NRGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.NRGBA{A: 255}
+	}
+	return color.NRGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}
+}
@@ -0,0 +1,75 @@
+package social
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+)
+
+func TestIcon(t *testing.T) {
+	for _, network := range Catalog {
+		for _, style := range Styles {
+			data, err := Icon(style, DefaultSize, network.Key)
+			if err != nil {
+				t.Fatalf("Icon(%s, %s): %v", style, network.Key, err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decode %s %s icon: %v", style, network.Key, err)
+			}
+			if size := img.Bounds().Dx(); size != DefaultSize*2 {
+				t.Errorf("%s icon is %dpx, want %dpx", network.Key, size, DefaultSize*2)
+			}
+
+			// The logo is drawn in the network or mono color, with the
+			// corners left transparent
+			want := parseHexColor(network.Color)
+			if style == StyleMono {
+				want = parseHexColor(monoColor)
+			}
+			filled := 0
+			bounds := img.Bounds()
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					r, g, b, a := img.At(x, y).RGBA()
+					if a == 0xffff && uint8(r>>8) == want.R && uint8(g>>8) == want.G && uint8(b>>8) == want.B {
+						filled++
+					}
+				}
+			}
+			if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+				t.Errorf("%s %s icon has an opaque corner", style, network.Key)
+			}
+			if area := bounds.Dx() * bounds.Dy(); filled < area/16 || filled > area*7/8 {
+				t.Errorf("%s %s icon fills %d of %d pixels", style, network.Key, filled, area)
+			}
+		}
+	}
+}
+
+func TestIconErrors(t *testing.T) {
+	tests := []struct {
+		style string
+		size  int
+		key   string
+		want  error
+	}{
+		{StyleColor, DefaultSize, "myspace", ErrUnknownNetwork},
+		{"neon", DefaultSize, "x", ErrUnknownStyle},
+		{StyleMono, 20, "x", ErrUnknownSize},
+	}
+	for _, tt := range tests {
+		if _, err := Icon(tt.style, tt.size, tt.key); !errors.Is(err, tt.want) {
+			t.Errorf("Icon(%s, %d, %s) = %v, want %v", tt.style, tt.size, tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestTraceShapeErrors(t *testing.T) {
+	for _, shape := range []string{"", "M 1", "M 1 1 C 2 2 3 3 4 4 Z", "M 1 x Z"} {
+		if _, err := drawIcon(shape, parseHexColor("#000000"), 8); err == nil {
+			t.Errorf("drawIcon(%q) succeeded, want an error", shape)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package social
+
+// shapes are the network logos as paths in a 24x24 box, y pointing down,
+// using absolute M, L, Q and Z commands. Holes wind opposite to their
+// outline.
+//
+// The LinkedIn, GitHub, Instagram, YouTube, Facebook, Pinterest, Medium,
+// Dribbble, Behance, WhatsApp and Telegram logos are outlines from Font
+// Awesome 4.7 by Dave Gandy (https://fontawesome.com/v4), licensed under
+// the SIL Open Font License 1.1. The X, Mastodon, TikTok, Bluesky and
+// Threads logos, which Font Awesome 4.7 lacks, are simplified drawings.
+var shapes = map[string]string{
+	"linkedin":  "M 3.7 20.09 L 7.31 20.09 L 7.31 9.25 L 3.7 9.25 L 3.7 20.09 Z M 7.55 5.91 Q 7.53 5.09 6.98 4.56 Q 6.44 4.03 5.53 4.03 Q 4.62 4.03 4.05 4.56 Q 3.48 5.09 3.48 5.91 Q 3.48 6.7 4.04 7.24 Q 4.59 7.78 5.48 7.78 L 5.5 7.78 Q 6.42 7.78 6.98 7.24 Q 7.55 6.7 7.55 5.91 Z M 16.69 20.09 L 20.3 20.09 L 20.3 13.88 Q 20.3 11.47 19.16 10.23 Q 18.02 9 16.14 9 Q 14.02 9 12.88 10.83 L 12.91 10.83 L 12.91 9.25 L 9.3 9.25 Q 9.34 10.28 9.3 20.09 L 12.91 20.09 L 12.91 14.03 Q 12.91 13.44 13.02 13.16 Q 13.25 12.61 13.72 12.23 Q 14.19 11.84 14.88 11.84 Q 16.69 11.84 16.69 14.3 L 16.69 20.09 Z M 24 4.5 L 24 19.5 Q 24 21.36 22.68 22.68 Q 21.36 24 19.5 24 L 4.5 24 Q 2.64 24 1.32 22.68 Q 0 21.36 0 19.5 L 0 4.5 Q 0 2.64 1.32 1.32 Q 2.64 0 4.5 0 L 19.5 0 Q 21.36 0 22.68 1.32 Q 24 2.64 24 4.5 Z",
+	"github":    "M 12 0.27 Q 15.27 0.27 18.02 1.88 Q 20.78 3.48 22.39 6.24 Q 24 9 24 12.27 Q 24 16.19 21.71 19.32 Q 19.42 22.45 15.8 23.66 Q 15.38 23.73 15.17 23.55 Q 14.97 23.36 14.97 23.08 Q 14.97 23.03 14.98 21.88 Q 14.98 20.73 14.98 19.78 Q 14.98 18.27 14.17 17.56 Q 15.06 17.47 15.77 17.28 Q 16.48 17.09 17.24 16.67 Q 18 16.25 18.51 15.63 Q 19.02 15.02 19.34 13.99 Q 19.66 12.97 19.66 11.64 Q 19.66 9.78 18.42 8.42 Q 19 7 18.3 5.23 Q 17.86 5.09 17.03 5.41 Q 16.2 5.72 15.59 6.09 L 15 6.47 Q 13.55 6.06 12 6.06 Q 10.45 6.06 9 6.47 Q 8.75 6.3 8.34 6.05 Q 7.92 5.8 7.03 5.45 Q 6.14 5.09 5.7 5.23 Q 5 7 5.58 8.42 Q 4.34 9.78 4.34 11.64 Q 4.34 12.97 4.66 13.98 Q 4.98 15 5.48 15.62 Q 5.98 16.25 6.74 16.67 Q 7.5 17.09 8.21 17.28 Q 8.92 17.47 9.81 17.56 Q 9.2 18.12 9.05 19.17 Q 8.72 19.33 8.34 19.41 Q 7.97 19.48 7.45 19.48 Q 6.94 19.48 6.43 19.15 Q 5.92 18.81 5.56 18.17 Q 5.27 17.67 4.8 17.36 Q 4.34 17.05 4.03 16.98 L 3.72 16.94 Q 3.39 16.94 3.27 17.01 Q 3.14 17.08 3.19 17.19 Q 3.23 17.3 3.33 17.41 Q 3.42 17.52 3.53 17.59 L 3.64 17.67 Q 3.98 17.83 4.32 18.27 Q 4.66 18.7 4.81 19.06 L 4.97 19.42 Q 5.17 20.02 5.66 20.38 Q 6.14 20.75 6.7 20.85 Q 7.27 20.95 7.79 20.96 Q 8.31 20.97 8.66 20.91 L 9.02 20.84 Q 9.02 21.44 9.02 22.23 Q 9.03 23.02 9.03 23.08 Q 9.03 23.36 8.83 23.55 Q 8.62 23.73 8.2 23.66 Q 4.58 22.45 2.29 19.32 Q 0 16.19 0 12.27 Q 0 9 1.61 6.24 Q 3.22 3.48 5.98 1.88 Q 8.73 0.27 12 0.27 Z M 4.55 17.5 Q 4.59 17.39 4.44 17.31 Q 4.28 17.27 4.23 17.34 Q 4.19 17.45 4.34 17.53 Q 4.48 17.62 4.55 17.5 Z M 5.03 18.03 Q 5.14 17.95 5 17.78 Q 4.84 17.64 4.75 17.73 Q 4.64 17.81 4.78 17.98 Q 4.94 18.14 5.03 18.03 Z M 5.5 18.73 Q 5.64 18.62 5.5 18.44 Q 5.38 18.23 5.23 18.34 Q 5.09 18.42 5.23 18.62 Q 5.38 18.83 5.5 18.73 Z M 6.16 19.39 Q 6.28 19.27 6.09 19.09 Q 5.91 18.91 5.78 19.05 Q 5.64 19.17 5.84 19.34 Q 6.03 19.53 6.16 19.39 Z M 7.05 19.78 Q 7.09 19.61 6.84 19.53 Q 6.61 19.47 6.55 19.64 Q 6.48 19.81 6.75 19.88 Q 6.98 19.97 7.05 19.78 Z M 8.03 19.86 Q 8.03 19.66 7.77 19.69 Q 7.52 19.69 7.52 19.86 Q 7.52 20.06 7.78 20.03 Q 8.03 20.03 8.03 19.86 Z M 8.94 19.7 Q 8.91 19.53 8.66 19.56 Q 8.41 19.61 8.44 19.8 Q 8.47 19.98 8.72 19.92 Q 8.97 19.86 8.94 19.7 Z",
+	"instagram": "M 16 12 Q 16 10.34 14.83 9.17 Q 13.66 8 12 8 Q 10.34 8 9.17 9.17 Q 8 10.34 8 12 Q 8 13.66 9.17 14.83 Q 10.34 16 12 16 Q 13.66 16 14.83 14.83 Q 16 13.66 16 12 Z M 18.16 12 Q 18.16 14.56 16.36 16.36 Q 14.56 18.16 12 18.16 Q 9.44 18.16 7.64 16.36 Q 5.84 14.56 5.84 12 Q 5.84 9.44 7.64 7.64 Q 9.44 5.84 12 5.84 Q 14.56 5.84 16.36 7.64 Q 18.16 9.44 18.16 12 Z M 19.84 5.59 Q 19.84 6.19 19.42 6.61 Q 19 7.03 18.41 7.03 Q 17.81 7.03 17.39 6.61 Q 16.97 6.19 16.97 5.59 Q 16.97 5 17.39 4.58 Q 17.81 4.16 18.41 4.16 Q 19 4.16 19.42 4.58 Q 19.84 5 19.84 5.59 Z M 12 2.16 Q 11.89 2.16 10.8 2.15 Q 9.72 2.14 9.16 2.15 Q 8.59 2.16 7.65 2.2 Q 6.7 2.23 6.04 2.35 Q 5.38 2.47 4.92 2.64 Q 4.14 2.95 3.55 3.55 Q 2.95 4.14 2.64 4.92 Q 2.47 5.38 2.35 6.04 Q 2.23 6.7 2.2 7.65 Q 2.16 8.59 2.15 9.16 Q 2.14 9.72 2.15 10.8 Q 2.16 11.89 2.16 12 Q 2.16 12.11 2.15 13.2 Q 2.14 14.28 2.15 14.84 Q 2.16 15.41 2.2 16.35 Q 2.23 17.3 2.35 17.96 Q 2.47 18.62 2.64 19.08 Q 2.95 19.86 3.55 20.45 Q 4.14 21.05 4.92 21.36 Q 5.38 21.53 6.04 21.65 Q 6.7 21.77 7.65 21.8 Q 8.59 21.84 9.16 21.85 Q 9.72 21.86 10.8 21.85 Q 11.89 21.84 12 21.84 Q 12.11 21.84 13.2 21.85 Q 14.28 21.86 14.84 21.85 Q 15.41 21.84 16.35 21.8 Q 17.3 21.77 17.96 21.65 Q 18.62 21.53 19.08 21.36 Q 19.86 21.05 20.45 20.45 Q 21.05 19.86 21.36 19.08 Q 21.53 18.62 21.65 17.96 Q 21.77 17.3 21.8 16.35 Q 21.84 15.41 21.85 14.84 Q 21.86 14.28 21.85 13.2 Q 21.84 12.11 21.84 12 Q 21.84 11.89 21.85 10.8 Q 21.86 9.72 21.85 9.16 Q 21.84 8.59 21.8 7.65 Q 21.77 6.7 21.65 6.04 Q 21.53 5.38 21.36 4.92 Q 21.05 4.14 20.45 3.55 Q 19.86 2.95 19.08 2.64 Q 18.62 2.47 17.96 2.35 Q 17.3 2.23 16.35 2.2 Q 15.41 2.16 14.84 2.15 Q 14.28 2.14 13.2 2.15 Q 12.11 2.16 12 2.16 Z M 24 12 Q 24 15.58 23.92 16.95 Q 23.77 20.2 21.98 21.98 Q 20.2 23.77 16.95 23.92 Q 15.58 24 12 24 Q 8.42 24 7.05 23.92 Q 3.8 23.77 2.02 21.98 Q 0.23 20.2 0.08 16.95 Q 0 15.58 0 12 Q 0 8.42 0.08 7.05 Q 0.23 3.8 2.02 2.02 Q 3.8 0.23 7.05 0.08 Q 8.42 0 12 0 Q 15.58 0 16.95 0.08 Q 20.2 0.23 21.98 2.02 Q 23.77 3.8 23.92 7.05 Q 24 8.42 24 12 Z",
+	"youtube":   "M 9.53 15.1 L 16 11.76 L 9.53 8.37 L 9.53 15.1 Z M 12 3.57 Q 14.25 3.57 16.34 3.63 Q 18.43 3.69 19.41 3.76 L 20.39 3.81 Q 20.4 3.81 20.62 3.83 Q 20.83 3.85 20.92 3.87 Q 21.02 3.89 21.24 3.93 Q 21.46 3.97 21.62 4.04 Q 21.78 4.11 21.99 4.21 Q 22.21 4.32 22.41 4.47 Q 22.61 4.63 22.8 4.83 Q 22.88 4.91 23 5.08 Q 23.13 5.24 23.39 5.86 Q 23.65 6.47 23.75 7.21 Q 23.85 8.07 23.91 9.04 Q 23.97 10.01 23.99 10.56 L 23.99 11.09 L 23.99 12.91 Q 24 14.85 23.75 16.79 Q 23.65 17.53 23.41 18.12 Q 23.17 18.72 22.98 18.94 L 22.8 19.17 Q 22.61 19.37 22.41 19.53 Q 22.21 19.68 21.99 19.78 Q 21.78 19.88 21.62 19.95 Q 21.46 20.01 21.24 20.05 Q 21.02 20.09 20.92 20.11 Q 20.82 20.13 20.61 20.15 Q 20.4 20.17 20.39 20.17 Q 17.03 20.43 12 20.43 Q 9.23 20.4 7.19 20.34 Q 5.15 20.28 4.51 20.24 L 3.85 20.19 L 3.37 20.13 Q 2.89 20.07 2.64 20 Q 2.39 19.93 1.96 19.72 Q 1.53 19.51 1.2 19.17 Q 1.12 19.09 1 18.92 Q 0.87 18.76 0.61 18.14 Q 0.35 17.53 0.25 16.79 Q 0.15 15.93 0.09 14.96 Q 0.03 13.99 0.01 13.44 L 0.01 12.91 L 0.01 11.09 Q 0 9.15 0.25 7.21 Q 0.35 6.47 0.59 5.88 Q 0.83 5.28 1.02 5.06 L 1.2 4.83 Q 1.39 4.63 1.59 4.47 Q 1.79 4.32 2.01 4.21 Q 2.22 4.11 2.38 4.04 Q 2.54 3.97 2.76 3.93 Q 2.98 3.89 3.08 3.87 Q 3.17 3.85 3.38 3.83 Q 3.6 3.81 3.61 3.81 Q 6.97 3.57 12 3.57 Z",
+	"facebook":  "M 19.5 0 Q 21.36 0 22.68 1.32 Q 24 2.64 24 4.5 L 24 19.5 Q 24 21.36 22.68 22.68 Q 21.36 24 19.5 24 L 16.56 24 L 16.56 14.7 L 19.67 14.7 L 20.14 11.08 L 16.56 11.08 L 16.56 8.77 Q 16.56 7.89 16.93 7.45 Q 17.3 7.02 18.36 7.02 L 20.27 7 L 20.27 3.77 Q 19.28 3.62 17.48 3.62 Q 15.36 3.62 14.09 4.88 Q 12.81 6.12 12.81 8.41 L 12.81 11.08 L 9.69 11.08 L 9.69 14.7 L 12.81 14.7 L 12.81 24 L 4.5 24 Q 2.64 24 1.32 22.68 Q 0 21.36 0 19.5 L 0 4.5 Q 0 2.64 1.32 1.32 Q 2.64 0 4.5 0 L 19.5 0 Z",
+	"pinterest": "M 24 12 Q 24 15.27 22.39 18.02 Q 20.78 20.78 18.02 22.39 Q 15.27 24 12 24 Q 10.27 24 8.59 23.5 Q 9.52 22.05 9.81 20.94 Q 9.95 20.41 10.66 17.64 Q 10.97 18.25 11.8 18.7 Q 12.62 19.14 13.58 19.14 Q 15.47 19.14 16.95 18.07 Q 18.44 17 19.25 15.12 Q 20.06 13.25 20.06 10.91 Q 20.06 9.12 19.13 7.56 Q 18.2 6 16.44 5.02 Q 14.67 4.03 12.45 4.03 Q 10.81 4.03 9.39 4.48 Q 7.97 4.94 6.98 5.69 Q 5.98 6.44 5.27 7.41 Q 4.56 8.39 4.23 9.44 Q 3.89 10.48 3.89 11.53 Q 3.89 13.16 4.52 14.39 Q 5.14 15.62 6.34 16.12 Q 6.81 16.31 6.94 15.81 Q 6.97 15.7 7.06 15.33 Q 7.16 14.95 7.19 14.86 Q 7.28 14.5 7.02 14.19 Q 6.22 13.23 6.22 11.83 Q 6.22 9.47 7.85 7.77 Q 9.48 6.08 12.12 6.08 Q 14.48 6.08 15.8 7.36 Q 17.12 8.64 17.12 10.69 Q 17.12 13.34 16.05 15.2 Q 14.98 17.06 13.31 17.06 Q 12.36 17.06 11.78 16.38 Q 11.2 15.7 11.42 14.75 Q 11.55 14.2 11.84 13.29 Q 12.12 12.38 12.3 11.68 Q 12.48 10.98 12.48 10.5 Q 12.48 9.72 12.06 9.2 Q 11.64 8.69 10.86 8.69 Q 9.89 8.69 9.22 9.58 Q 8.55 10.47 8.55 11.8 Q 8.55 12.94 8.94 13.7 L 7.39 20.23 Q 7.12 21.33 7.19 23 Q 3.97 21.58 1.98 18.61 Q 0 15.64 0 12 Q 0 8.73 1.61 5.98 Q 3.22 3.22 5.98 1.61 Q 8.73 0 12 0 Q 15.27 0 18.02 1.61 Q 20.78 3.22 22.39 5.98 Q 24 8.73 24 12 Z",
+	"medium":    "M 8 5.64 L 8 21.35 Q 8 21.68 7.83 21.92 Q 7.66 22.15 7.34 22.15 Q 7.11 22.15 6.9 22.04 L 0.67 18.92 Q 0.39 18.79 0.19 18.48 Q 0 18.16 0 17.85 L 0 2.58 Q 0 2.32 0.13 2.13 Q 0.27 1.94 0.52 1.94 Q 0.71 1.94 1.11 2.14 L 7.96 5.57 Q 8 5.61 8 5.64 Z M 8.85 6.99 L 16 18.59 L 8.85 15.03 L 8.85 6.99 Z M 24 7.23 L 24 21.35 Q 24 21.68 23.81 21.89 Q 23.62 22.1 23.3 22.1 Q 22.98 22.1 22.67 21.92 L 16.77 18.98 Z M 23.96 5.62 Q 23.96 5.67 20.52 11.24 Q 17.09 16.82 16.5 17.77 L 11.28 9.28 L 15.62 2.22 Q 15.84 1.85 16.31 1.85 Q 16.5 1.85 16.66 1.93 L 23.91 5.54 Q 23.96 5.57 23.96 5.62 Z",
+	"dribbble":  "M 16 21.44 Q 15.34 17.67 13.81 13.66 L 13.78 13.66 L 13.75 13.67 Q 13.5 13.77 13.08 13.93 Q 12.66 14.09 11.5 14.7 Q 10.34 15.3 9.36 15.98 Q 8.38 16.66 7.31 17.77 Q 6.25 18.88 5.7 20.08 L 5.47 19.91 Q 8.34 22.25 12 22.25 Q 14.06 22.25 16 21.44 Z M 13.11 11.95 Q 12.78 11.19 12.28 10.22 Q 7.42 11.67 1.77 11.67 Q 1.75 11.78 1.75 12 Q 1.75 13.94 2.44 15.7 Q 3.12 17.45 4.38 18.84 Q 5.16 17.45 6.3 16.24 Q 7.45 15.03 8.53 14.3 Q 9.61 13.56 10.57 13.03 Q 11.53 12.5 12.12 12.28 L 12.7 12.08 Q 12.77 12.06 12.91 12.02 Q 13.05 11.98 13.11 11.95 Z M 11.44 8.64 Q 9.56 5.31 7.62 2.73 Q 5.47 3.75 3.97 5.64 Q 2.47 7.53 1.97 9.89 Q 6.69 9.89 11.44 8.64 Z M 22.12 13.62 Q 18.84 12.69 15.73 13.17 Q 17.09 16.91 17.73 20.5 Q 19.47 19.33 20.62 17.54 Q 21.78 15.75 22.12 13.62 Z M 9.55 2.05 Q 9.53 2.05 9.52 2.06 Q 9.53 2.05 9.55 2.05 Z M 18.77 4.31 Q 15.88 1.75 12 1.75 Q 10.81 1.75 9.58 2.05 Q 11.62 4.7 13.42 8.02 Q 14.5 7.61 15.45 7.07 Q 16.41 6.53 16.96 6.11 Q 17.52 5.69 17.98 5.22 Q 18.45 4.75 18.57 4.59 Z M 22.25 11.89 Q 22.2 8.27 19.92 5.48 L 19.91 5.5 Q 19.77 5.69 19.61 5.88 Q 19.45 6.08 18.93 6.58 Q 18.41 7.08 17.82 7.52 Q 17.23 7.97 16.26 8.54 Q 15.28 9.11 14.2 9.55 Q 14.59 10.38 14.89 11.03 Q 14.92 11.11 14.99 11.3 Q 15.06 11.48 15.11 11.56 Q 15.67 11.48 16.27 11.45 Q 16.88 11.42 17.42 11.42 Q 17.97 11.42 18.5 11.45 Q 19.03 11.47 19.5 11.51 Q 19.97 11.55 20.38 11.59 Q 20.8 11.64 21.13 11.7 Q 21.47 11.75 21.7 11.79 Q 21.94 11.83 22.09 11.86 Z M 24 12 Q 24 15.27 22.39 18.02 Q 20.78 20.78 18.02 22.39 Q 15.27 24 12 24 Q 8.73 24 5.98 22.39 Q 3.22 20.78 1.61 18.02 Q 0 15.27 0 12 Q 0 8.73 1.61 5.98 Q 3.22 3.22 5.98 1.61 Q 8.73 0 12 0 Q 15.27 0 18.02 1.61 Q 20.78 3.22 22.39 5.98 Q 24 8.73 24 12 Z",
+	"behance":   "M 21.66 5.47 L 15.67 5.47 L 15.67 6.93 L 21.66 6.93 L 21.66 5.47 Z M 18.7 10.46 Q 17.65 10.46 16.99 11.08 Q 16.34 11.7 16.27 12.75 L 21.05 12.75 Q 20.84 10.46 18.7 10.46 Z M 18.89 17.32 Q 19.63 17.32 20.32 16.95 Q 21.01 16.57 21.21 15.93 L 23.8 15.93 Q 22.63 19.52 18.8 19.52 Q 16.29 19.52 14.81 17.98 Q 13.32 16.43 13.32 13.91 Q 13.32 11.47 14.85 9.86 Q 16.38 8.25 18.8 8.25 Q 20.41 8.25 21.62 9.05 Q 22.82 9.84 23.41 11.14 Q 24 12.45 24 14.05 Q 24 14.25 23.98 14.6 L 16.27 14.6 Q 16.27 15.9 16.94 16.61 Q 17.61 17.32 18.89 17.32 Z M 3.25 16.73 L 6.71 16.73 Q 9.12 16.73 9.12 14.78 Q 9.12 12.67 6.79 12.67 L 3.25 12.67 L 3.25 16.73 Z M 3.25 10.44 L 6.54 10.44 Q 7.45 10.44 7.99 10.01 Q 8.52 9.59 8.52 8.68 Q 8.52 7 6.29 7 L 3.25 7 L 3.25 10.44 Z M 0 4.48 L 6.96 4.48 Q 7.98 4.48 8.78 4.64 Q 9.57 4.8 10.26 5.2 Q 10.95 5.59 11.31 6.33 Q 11.68 7.07 11.68 8.13 Q 11.68 10.25 9.67 11.21 Q 11 11.59 11.68 12.56 Q 12.36 13.54 12.36 14.95 Q 12.36 15.83 12.08 16.55 Q 11.79 17.27 11.3 17.77 Q 10.82 18.26 10.15 18.6 Q 9.48 18.94 8.73 19.09 Q 7.98 19.24 7.16 19.24 L 0 19.24 L 0 4.48 Z",
+	"whatsapp":  "M 15.36 13.21 Q 15.57 13.21 16.88 13.89 Q 18.19 14.57 18.26 14.71 Q 18.29 14.79 18.29 14.95 Q 18.29 15.46 18.03 16.12 Q 17.78 16.73 16.93 17.14 Q 16.08 17.55 15.35 17.55 Q 14.47 17.55 12.4 16.59 Q 10.88 15.89 9.77 14.76 Q 8.65 13.63 7.47 11.89 Q 6.36 10.23 6.37 8.88 L 6.37 8.76 Q 6.42 7.35 7.52 6.31 Q 7.89 5.97 8.33 5.97 Q 8.42 5.97 8.6 5.99 Q 8.79 6.02 8.9 6.02 Q 9.19 6.02 9.31 6.12 Q 9.43 6.22 9.55 6.54 Q 9.67 6.85 10.06 7.91 Q 10.45 8.96 10.45 9.07 Q 10.45 9.4 9.91 9.96 Q 9.38 10.53 9.38 10.68 Q 9.38 10.79 9.46 10.91 Q 9.98 12.05 11.04 13.04 Q 11.91 13.86 13.38 14.6 Q 13.57 14.71 13.72 14.71 Q 13.95 14.71 14.56 13.96 Q 15.16 13.21 15.36 13.21 Z M 12.22 21.43 Q 14.19 21.43 15.99 20.65 Q 17.8 19.88 19.1 18.57 Q 20.4 17.27 21.18 15.47 Q 21.95 13.66 21.95 11.69 Q 21.95 9.72 21.18 7.91 Q 20.4 6.11 19.1 4.81 Q 17.8 3.5 15.99 2.73 Q 14.19 1.95 12.22 1.95 Q 10.25 1.95 8.44 2.73 Q 6.64 3.5 5.33 4.81 Q 4.03 6.11 3.26 7.91 Q 2.48 9.72 2.48 11.69 Q 2.48 14.84 4.34 17.4 L 3.12 21.01 L 6.87 19.81 Q 9.32 21.43 12.22 21.43 Z M 12.22 0 Q 14.59 0 16.75 0.93 Q 18.91 1.86 20.48 3.43 Q 22.05 4.99 22.98 7.16 Q 23.91 9.32 23.91 11.69 Q 23.91 14.06 22.98 16.22 Q 22.05 18.39 20.48 19.95 Q 18.91 21.52 16.75 22.45 Q 14.59 23.38 12.22 23.38 Q 9.19 23.38 6.56 21.92 L 0.09 24 L 2.2 17.72 Q 0.53 14.96 0.53 11.69 Q 0.53 9.32 1.46 7.16 Q 2.39 4.99 3.95 3.43 Q 5.52 1.86 7.68 0.93 Q 9.84 0 12.22 0 Z",
+	"telegram":  "M 15.92 17.5 L 17.89 8.22 Q 18.01 7.63 17.75 7.38 Q 17.49 7.12 17.06 7.29 L 5.49 11.75 Q 5.1 11.89 4.96 12.08 Q 4.82 12.27 4.93 12.44 Q 5.04 12.6 5.36 12.7 L 8.32 13.62 L 15.19 9.29 Q 15.47 9.11 15.62 9.21 Q 15.71 9.28 15.56 9.42 L 10 14.44 L 10 14.44 L 10 14.44 L 9.79 17.49 Q 10.1 17.49 10.39 17.2 L 11.84 15.8 L 14.84 18.01 Q 15.7 18.5 15.92 17.5 Z M 24 12 Q 24 14.44 23.05 16.66 Q 22.1 18.88 20.49 20.49 Q 18.88 22.1 16.66 23.05 Q 14.44 24 12 24 Q 9.56 24 7.34 23.05 Q 5.12 22.1 3.51 20.49 Q 1.9 18.88 0.95 16.66 Q 0 14.44 0 12 Q 0 9.56 0.95 7.34 Q 1.9 5.12 3.51 3.51 Q 5.12 1.9 7.34 0.95 Q 9.56 0 12 0 Q 14.44 0 16.66 0.95 Q 18.88 1.9 20.49 3.51 Q 22.1 5.12 23.05 7.34 Q 24 9.56 24 12 Z",
+	"x":         "M 1 1.5 L 8 1.5 L 23 22.5 L 16 22.5 Z M 3.9 2.9 L 17 21.1 L 20.1 21.1 L 7 2.9 Z M 20.4 1.5 L 22.6 1.5 L 14.2 11 L 13.2 9.6 Z M 10.4 13.2 L 11.4 14.6 L 3.6 22.5 L 1.4 22.5 Z",
+	"mastodon":  "M 7 1 Q 2 1 2 6 L 2 14 Q 2 23 11 23 Q 15 23 15 21 Q 11.5 21.5 8.5 20.5 Q 8.5 19.2 11 19 L 17 18.5 Q 22 18 22 13 L 22 6 Q 22 1 17 1 Z M 6.5 14.5 L 6.5 8.5 Q 6.5 6 9.25 6 Q 12 6 12 8.5 Q 12 6 14.75 6 Q 17.5 6 17.5 8.5 L 17.5 14.5 L 15.5 14.5 L 15.5 9 Q 15.5 8 14.75 8 Q 13 8 13 9 L 13 12 L 11 12 L 11 9 Q 11 8 9.25 8 Q 8.5 8 8.5 9 L 8.5 14.5 Z",
+	"tiktok":    "M 12.5 1 L 16.3 1 Q 16.6 3.6 18.4 5.2 Q 20 6.6 22 6.7 L 22 10.5 Q 19 10.5 16.5 8.8 L 16.5 15.5 Q 16.5 19.2 13.9 21.3 Q 11.8 23 9 23 Q 5.6 23 3.6 20.6 Q 2 18.7 2 16 Q 2 12.7 4.5 10.6 Q 7 8.6 10.6 9 L 10.6 12.9 Q 8.6 12.3 7.2 13.3 Q 6 14.2 6 15.9 Q 6 17.4 7 18.3 Q 7.9 19.2 9.2 19.1 Q 10.7 19 11.6 17.9 Q 12.5 16.9 12.5 15.2 Z",
+	"bluesky":   "M 12 10.3 Q 9 4.3 5.2 2.1 Q 2 0.3 2 3.6 Q 2 5.8 2.7 9.9 Q 3.5 13.4 7.7 13.6 Q 4 14.6 5 17.3 Q 6.1 20.3 8.8 20.1 Q 11 19.9 12 16.8 Q 13 19.9 15.2 20.1 Q 17.9 20.3 19 17.3 Q 20 14.6 16.3 13.6 Q 20.5 13.4 21.3 9.9 Q 22 5.8 22 3.6 Q 22 0.3 18.8 2.1 Q 15 4.3 12 10.3 Z",
+	"threads":   "M 19.71 19.71 Q 16.51 22.9 12 22.9 Q 7.49 22.9 4.29 19.71 Q 1.1 16.51 1.1 12 Q 1.1 7.49 4.29 4.29 Q 7.49 1.1 12 1.1 Q 16.51 1.1 19.71 4.29 Q 22.9 7.49 22.9 12 L 20.6 12 Q 20.6 8.44 18.08 5.92 Q 15.56 3.4 12 3.4 Q 8.44 3.4 5.92 5.92 Q 3.4 8.44 3.4 12 Q 3.4 15.56 5.92 18.08 Q 8.44 20.6 12 20.6 Q 15.56 20.6 18.08 18.08 Z M 16.3 12.2 Q 16.3 14.15 14.92 15.52 Q 13.55 16.9 11.6 16.9 Q 9.65 16.9 8.28 15.52 Q 6.9 14.15 6.9 12.2 Q 6.9 10.25 8.28 8.88 Q 9.65 7.5 11.6 7.5 Q 13.55 7.5 14.92 8.88 Q 16.3 10.25 16.3 12.2 Z M 14.1 12.2 Q 14.1 11.16 13.37 10.43 Q 12.64 9.7 11.6 9.7 Q 10.56 9.7 9.83 10.43 Q 9.1 11.16 9.1 12.2 Q 9.1 13.24 9.83 13.97 Q 10.56 14.7 11.6 14.7 Q 12.64 14.7 13.37 13.97 Q 14.1 13.24 14.1 12.2 Z M 15.9 7.6 L 18.1 7.6 L 18.1 15.2 L 15.9 15.2 Z M 20.3 19.6 Q 18.48 19.6 17.19 18.31 Q 15.9 17.02 15.9 15.2 L 18.1 15.2 Q 18.1 16.11 18.74 16.76 Q 19.39 17.4 20.3 17.4 Z",
+}
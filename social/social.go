@@ -0,0 +1,157 @@
+package social
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Network is a social network signatures can link to
+type Network struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	// Hosts are the domains profile URLs may use; subdomains are allowed
+	Hosts []string `json:"hosts,omitempty"`
+	// Path, when set, must match the URL path, e.g. a profile handle
+	Path    *regexp.Regexp `json:"-"`
+	Example string         `json:"example"`
+}
+
+// Catalog lists the supported networks in their default display order
+var Catalog = []Network{
+	{Key: "linkedin", Name: "LinkedIn", Color: "#0a66c2", Hosts: []string{"linkedin.com"}, Path: regexp.MustCompile(`^/(in|company|school|showcase)/[^/]+/?$`), Example: "https://www.linkedin.com/in/janedoe"},
+	{Key: "x", Name: "X", Color: "#000000", Hosts: []string{"x.com", "twitter.com"}, Path: regexp.MustCompile(`^/[A-Za-z0-9_]{1,15}/?$`), Example: "https://x.com/janedoe"},
+	{Key: "github", Name: "GitHub", Color: "#181717", Hosts: []string{"github.com"}, Path: regexp.MustCompile(`^/[A-Za-z0-9-]+/?$`), Example: "https://github.com/janedoe"},
+	{Key: "instagram", Name: "Instagram", Color: "#e4405f", Hosts: []string{"instagram.com"}, Path: regexp.MustCompile(`^/[A-Za-z0-9_.]+/?$`), Example: "https://www.instagram.com/janedoe"},
+	{Key: "youtube", Name: "YouTube", Color: "#ff0000", Hosts: []string{"youtube.com"}, Path: regexp.MustCompile(`^/(@[A-Za-z0-9_.-]+|c/[^/]+|channel/[^/]+|user/[^/]+)/?$`), Example: "https://www.youtube.com/@janedoe"},
+	{Key: "mastodon", Name: "Mastodon", Color: "#6364ff", Path: regexp.MustCompile(`^/@[A-Za-z0-9_]+/?$`), Example: "https://mastodon.social/@janedoe"},
+	{Key: "facebook", Name: "Facebook", Color: "#1877f2", Hosts: []string{"facebook.com", "fb.com"}, Path: regexp.MustCompile(`^/[^/]+/?$`), Example: "https://www.facebook.com/janedoe"},
+	{Key: "tiktok", Name: "TikTok", Color: "#000000", Hosts: []string{"tiktok.com"}, Path: regexp.MustCompile(`^/@[A-Za-z0-9_.]+/?$`), Example: "https://www.tiktok.com/@janedoe"},
+	{Key: "bluesky", Name: "Bluesky", Color: "#0085ff", Hosts: []string{"bsky.app"}, Path: regexp.MustCompile(`^/profile/[^/]+/?$`), Example: "https://bsky.app/profile/janedoe.bsky.social"},
+	{Key: "threads", Name: "Threads", Color: "#000000", Hosts: []string{"threads.net", "threads.com"}, Path: regexp.MustCompile(`^/@[A-Za-z0-9_.]+/?$`), Example: "https://www.threads.net/@janedoe"},
+	{Key: "pinterest", Name: "Pinterest", Color: "#bd081c", Hosts: []string{"pinterest.com"}, Path: regexp.MustCompile(`^/[^/]+/?$`), Example: "https://www.pinterest.com/janedoe"},
+	{Key: "medium", Name: "Medium", Color: "#000000", Hosts: []string{"medium.com"}, Example: "https://medium.com/@janedoe"},
+	{Key: "dribbble", Name: "Dribbble", Color: "#ea4c89", Hosts: []string{"dribbble.com"}, Path: regexp.MustCompile(`^/[^/]+/?$`), Example: "https://dribbble.com/janedoe"},
+	{Key: "behance", Name: "Behance", Color: "#1769ff", Hosts: []string{"behance.net"}, Path: regexp.MustCompile(`^/[^/]+/?$`), Example: "https://www.behance.net/janedoe"},
+	{Key: "whatsapp", Name: "WhatsApp", Color: "#25d366", Hosts: []string{"wa.me"}, Path: regexp.MustCompile(`^/[0-9]+/?$`), Example: "https://wa.me/15551234567"},
+	{Key: "telegram", Name: "Telegram", Color: "#26a5e4", Hosts: []string{"t.me"}, Path: regexp.MustCompile(`^/[A-Za-z0-9_]{5,}/?$`), Example: "https://t.me/janedoe"},
+}
+
+// legacyKeys maps the keys of the old social_links object to networks
+var legacyKeys = map[string]string{
+	"twitter": "x",
+}
+
+// Lookup returns the network with the given key
+func Lookup(key string) (Network, bool) {
+	for _, network := range Catalog {
+		if network.Key == key {
+			return network, true
+		}
+	}
+	return Network{}, false
+}
+
+// ValidateURL checks that raw is an https profile URL on the network
+func (n Network) ValidateURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%s URL %q is not an absolute URL", n.Name, raw)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%s URL %q must use https", n.Name, raw)
+	}
+
+	if len(n.Hosts) > 0 && !hostAllowed(u.Hostname(), n.Hosts) {
+		return fmt.Errorf("%s URL %q must be on %s", n.Name, raw, strings.Join(n.Hosts, " or "))
+	}
+	if n.Path != nil && !n.Path.MatchString(u.Path) {
+		return fmt.Errorf("%s URL %q is not a profile URL, e.g. %s", n.Name, raw, n.Example)
+	}
+	return nil
+}
+
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range allowed {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry is one social link in a signature
+type Entry struct {
+	Network string `json:"network"`
+	URL     string `json:"url"`
+}
+
+// Entries returns a signature's social links in display order. The
+// ordered "social" list in template data takes precedence over the older
+// "social_links" object, which is read in catalog order.
+func Entries(data map[string]interface{}) []Entry {
+	var entries []Entry
+	if list, ok := data["social"].([]interface{}); ok {
+		for _, item := range list {
+			fields, _ := item.(map[string]interface{})
+			network, _ := fields["network"].(string)
+			link, _ := fields["url"].(string)
+			if network != "" && link != "" {
+				entries = append(entries, Entry{Network: network, URL: link})
+			}
+		}
+		return entries
+	}
+
+	links, _ := data["social_links"].(map[string]interface{})
+	for key, value := range links {
+		link, _ := value.(string)
+		if link == "" {
+			continue
+		}
+		if mapped, ok := legacyKeys[key]; ok {
+			key = mapped
+		}
+		entries = append(entries, Entry{Network: key, URL: link})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return catalogIndex(entries[i].Network) < catalogIndex(entries[j].Network)
+	})
+	return entries
+}
+
+func catalogIndex(key string) int {
+	for i, network := range Catalog {
+		if network.Key == key {
+			return i
+		}
+	}
+	return len(Catalog)
+}
+
+// Validate checks every social link in the template data and returns one
+// error per invalid entry
+func Validate(data map[string]interface{}) []string {
+	var problems []string
+	if raw, ok := data["social"]; ok {
+		if _, isList := raw.([]interface{}); !isList {
+			return []string{"social must be a list of {network, url} entries"}
+		}
+	}
+
+	for _, entry := range Entries(data) {
+		network, ok := Lookup(entry.Network)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown social network %q", entry.Network))
+			continue
+		}
+		if err := network.ValidateURL(entry.URL); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
@@ -0,0 +1,134 @@
+package social
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		network string
+		url     string
+		wantErr string
+	}{
+		{"linkedin", "https://www.linkedin.com/in/janedoe", ""},
+		{"linkedin", "https://linkedin.com/company/acme/", ""},
+		{"linkedin", "https://www.linkedin.com/feed", "not a profile URL"},
+		{"linkedin", "https://linkedin.com.evil.example/in/janedoe", "must be on linkedin.com"},
+		{"linkedin", "https://notlinkedin.com/in/janedoe", "must be on linkedin.com"},
+		{"x", "https://x.com/janedoe", ""},
+		{"x", "https://twitter.com/jane_doe", ""},
+		{"x", "https://x.com/a_handle_too_long", "not a profile URL"},
+		{"x", "http://x.com/janedoe", "must use https"},
+		{"github", "https://github.com/jane-doe", ""},
+		{"github", "https://github.com/jane-doe/repo", "not a profile URL"},
+		{"instagram", "https://www.instagram.com/jane.doe", ""},
+		{"youtube", "https://www.youtube.com/@janedoe", ""},
+		{"youtube", "https://www.youtube.com/channel/UC123", ""},
+		{"youtube", "https://www.youtube.com/watch", "not a profile URL"},
+		{"mastodon", "https://mastodon.social/@janedoe", ""},
+		{"mastodon", "https://fosstodon.org/@jane_doe", ""},
+		{"mastodon", "https://mastodon.social/janedoe", "not a profile URL"},
+		{"facebook", "https://fb.com/janedoe", ""},
+		{"tiktok", "https://www.tiktok.com/@jane.doe", ""},
+		{"tiktok", "https://www.tiktok.com/janedoe", "not a profile URL"},
+		{"bluesky", "https://bsky.app/profile/janedoe.bsky.social", ""},
+		{"threads", "https://www.threads.com/@janedoe", ""},
+		{"pinterest", "https://www.pinterest.com/janedoe", ""},
+		{"medium", "https://medium.com/@janedoe/some-post", ""},
+		{"medium", "https://janedoe.medium.com", ""},
+		{"dribbble", "https://dribbble.com/janedoe", ""},
+		{"behance", "https://www.behance.net/janedoe", ""},
+		{"whatsapp", "https://wa.me/15551234567", ""},
+		{"whatsapp", "https://wa.me/+1 555", "not a profile URL"},
+		{"telegram", "https://t.me/janedoe", ""},
+		{"telegram", "https://t.me/jd", "not a profile URL"},
+		{"telegram", "t.me/janedoe", "not an absolute URL"},
+	}
+	for _, tt := range tests {
+		network, ok := Lookup(tt.network)
+		if !ok {
+			t.Fatalf("network %q is not in the catalog", tt.network)
+		}
+		err := network.ValidateURL(tt.url)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s ValidateURL(%q) = %v, want %q", tt.network, tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCatalogExamples checks every network accepts its own example URL
+func TestCatalogExamples(t *testing.T) {
+	for _, network := range Catalog {
+		if err := network.ValidateURL(network.Example); err != nil {
+			t.Errorf("%s example: %v", network.Key, err)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []Entry
+	}{
+		{
+			name: "list keeps its order",
+			data: map[string]interface{}{
+				"social": []interface{}{
+					map[string]interface{}{"network": "github", "url": "https://github.com/jane"},
+					map[string]interface{}{"network": "linkedin", "url": ""},
+					"not an entry",
+					map[string]interface{}{"network": "x", "url": "https://x.com/jane"},
+				},
+				"social_links": map[string]interface{}{"linkedin": "https://linkedin.com/in/jane"},
+			},
+			want: []Entry{{"github", "https://github.com/jane"}, {"x", "https://x.com/jane"}},
+		},
+		{
+			name: "legacy object in catalog order",
+			data: map[string]interface{}{
+				"social_links": map[string]interface{}{
+					"telegram": "https://t.me/janedoe",
+					"twitter":  "https://twitter.com/jane",
+					"github":   "",
+					"linkedin": "https://linkedin.com/in/jane",
+					"myspace":  "https://myspace.com/jane",
+				},
+			},
+			want: []Entry{
+				{"linkedin", "https://linkedin.com/in/jane"},
+				{"x", "https://twitter.com/jane"},
+				{"telegram", "https://t.me/janedoe"},
+				{"myspace", "https://myspace.com/jane"},
+			},
+		},
+		{name: "nothing", data: map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Entries(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	problems := Validate(map[string]interface{}{
+		"social": []interface{}{
+			map[string]interface{}{"network": "linkedin", "url": "https://linkedin.com/in/jane"},
+			map[string]interface{}{"network": "myspace", "url": "https://myspace.com/jane"},
+			map[string]interface{}{"network": "github", "url": "http://github.com/jane"},
+		},
+	})
+	want := []string{`unknown social network "myspace"`, `GitHub URL "http://github.com/jane" must use https`}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("Validate() = %q, want %q", problems, want)
+	}
+
+	if problems := Validate(map[string]interface{}{"social": "https://x.com/jane"}); len(problems) != 1 || !strings.Contains(problems[0], "must be a list") {
+		t.Errorf("Validate() = %q, want the non-list rejected", problems)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores uploaded files such as logos and headshots
+type BlobStore interface {
+	// Put stores data under key, replacing any existing blob
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get opens the blob stored under key along with its content type
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+	// Delete removes the blob stored under key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// Store is the blob store the application was configured with
+var Store BlobStore
+
+// Setup configures Store from the environment. ASSET_STORAGE selects the
+// backend: "local" (default) writes under ASSET_DIR, "s3" uses any
+// S3-compatible service such as AWS S3 or MinIO.
+func Setup() {
+	switch backend := os.Getenv("ASSET_STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("ASSET_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			log.Fatalf("Unable to set up local asset storage: %v\n", err)
+		}
+		Store = store
+		log.Printf("Asset storage: local directory %s\n", dir)
+	case "s3":
+		store, err := NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			VirtualHosted:   os.Getenv("S3_VIRTUAL_HOSTED") == "true",
+		})
+		if err != nil {
+			log.Fatalf("Unable to set up S3 asset storage: %v\n", err)
+		}
+		Store = store
+		log.Printf("Asset storage: S3 bucket %s\n", os.Getenv("S3_BUCKET"))
+	default:
+		log.Fatalf("Unknown ASSET_STORAGE backend %q\n", backend)
+	}
+}
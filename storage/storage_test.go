@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBlobStore checks the BlobStore contract against a store
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+	key := "assets/test/" + time.Now().Format("20060102150405.000000000") + "/logo 1.png"
+
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Put: err = %v, want ErrNotFound", err)
+	}
+
+	for _, data := range []string{"first", "second"} {
+		if err := store.Put(ctx, key, []byte(data), "image/png"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		body, contentType, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		got, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Fatalf("read blob: %v", err)
+		}
+		if string(got) != data || contentType != "image/png" {
+			t.Errorf("Get = %q %s, want %q image/png", got, contentType, data)
+		}
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	testBlobStore(t, store)
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	for _, key := range []string{"../outside.png", "assets/../../outside.png", "/"} {
+		if err := store.Put(context.Background(), key, []byte("x"), "image/png"); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid key error", key)
+		}
+	}
+}
+
+// TestS3StoreMinIO runs against a MinIO server, e.g.
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	MINIO_ENDPOINT=http://localhost:9000 go test ./storage/
+//
+// MINIO_ACCESS_KEY and MINIO_SECRET_KEY default to MinIO's minioadmin.
+func TestS3StoreMinIO(t *testing.T) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT is not set")
+	}
+	store, err := NewS3Store(S3Config{
+		Endpoint:        endpoint,
+		Bucket:          "signature-assets-test",
+		AccessKeyID:     envOr("MINIO_ACCESS_KEY", "minioadmin"),
+		SecretAccessKey: envOr("MINIO_SECRET_KEY", "minioadmin"),
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	if err := createBucket(store); err != nil {
+		t.Fatalf("create bucket: %v", err)
+	}
+	testBlobStore(t, store)
+}
+
+// createBucket creates the store's bucket unless it exists
+func createBucket(s *S3Store) error {
+	u := *s.endpoint
+	u.Path += "/" + s.config.Bucket
+	req, err := http.NewRequest(http.MethodPut, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, nil, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && !strings.Contains(string(body), "BucketAlreadyOwnedByYou") {
+		return s.responseError(&http.Response{StatusCode: resp.StatusCode, Body: io.NopCloser(strings.NewReader(string(body)))})
+	}
+	return nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func TestS3StoreRequests(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "assets", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+
+	if err := store.Put(context.Background(), "logos/a b.png", []byte("x"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got.URL.EscapedPath() != "/assets/logos/a%20b.png" {
+		t.Errorf("path = %s, want the path-style object URL", got.URL.EscapedPath())
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date") {
+		t.Errorf("Authorization = %s", auth)
+	}
+	if got.Header.Get("X-Amz-Content-Sha256") != sha256Hex([]byte("x")) {
+		t.Errorf("payload hash = %s", got.Header.Get("X-Amz-Content-Sha256"))
+	}
+
+	if _, _, err := store.Get(context.Background(), "missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing object: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3StoreVirtualHostedURL(t *testing.T) {
+	store, err := NewS3Store(S3Config{Endpoint: "https://s3.eu-west-1.amazonaws.com", Bucket: "assets", AccessKeyID: "a", SecretAccessKey: "b", VirtualHosted: true})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	if got := store.objectURL("logos/x+y.png").String(); got != "https://assets.s3.eu-west-1.amazonaws.com/logos/x%2By.png" {
+		t.Errorf("objectURL = %s", got)
+	}
+}
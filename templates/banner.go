@@ -0,0 +1,39 @@
+package templates
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// Banner is a promotional image appended below a signature
+type Banner struct {
+	ImageURL string
+	Width    int
+	Height   int
+	Alt      string
+	// LinkURL is where the banner leads, usually a tracked redirect
+	LinkURL string
+}
+
+var bannerTemplate = template.Must(template.New("banner").Parse(bannerHTML))
+
+// RenderBanner renders the banner as a linked image with the explicit
+// dimensions email clients need
+func RenderBanner(banner Banner) (string, error) {
+	var buf bytes.Buffer
+	if err := bannerTemplate.Execute(&buf, banner); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+const bannerHTML = `
+        <table cellpadding="0" cellspacing="0" border="0" style="margin-top: 12px;">
+            <tr>
+                <td>
+                    <a href="{{.LinkURL}}" style="text-decoration: none;">
+                        <img src="{{.ImageURL}}" width="{{.Width}}" height="{{.Height}}" alt="{{.Alt}}" style="display: block; border: 0;">
+                    </a>
+                </td>
+            </tr>
+        </table>`
@@ -0,0 +1,302 @@
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultLinkColor is the link color used when a brand kit does not set one
+const DefaultLinkColor = "#0a66c2"
+
+// Icon styles a brand kit can use for social links
+const (
+	IconStyleText  = "text"
+	IconStyleColor = "color"
+	IconStyleMono  = "mono"
+)
+
+// PaletteKeys are the named colors templates look up in a brand palette
+var PaletteKeys = []string{"primary", "heading", "text", "secondary", "muted", "background"}
+
+// DarkPaletteDefaults are the dark-mode colors used when a brand kit does
+// not set its own, chosen to keep contrast on dark backgrounds
+var DarkPaletteDefaults = map[string]string{
+	"primary":    "#6cb4ff",
+	"heading":    "#ffffff",
+	"text":       "#e6e6e6",
+	"secondary":  "#cccccc",
+	"muted":      "#a6a6a6",
+	"background": "#1f1f1f",
+}
+
+var (
+	colorPattern     = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{8}|[a-zA-Z]{3,20})$`)
+	fontStackPattern = regexp.MustCompile(`^[A-Za-z0-9 ,'"\-]{1,200}$`)
+)
+
+// Brand is the shared styling a brand kit applies to every signature
+// that uses it
+type Brand struct {
+	Palette    map[string]string `json:"palette"`
+	FontStack  string            `json:"font_stack"`
+	LogoURL    string            `json:"logo_url"`
+	LogoWidth  int               `json:"logo_width"`
+	LogoHeight int               `json:"logo_height"`
+	IconStyle  string            `json:"icon_style"`
+	LinkColor  string            `json:"link_color"`
+	// DarkPalette and DarkLinkColor replace the palette and link color in
+	// email clients using dark mode
+	DarkPalette   map[string]string `json:"dark_palette"`
+	DarkLinkColor string            `json:"dark_link_color"`
+	// DarkLogoURL is shown instead of the logo in dark mode, e.g. a white
+	// version of a black logo
+	DarkLogoURL    string `json:"dark_logo_url"`
+	DarkLogoWidth  int    `json:"dark_logo_width"`
+	DarkLogoHeight int    `json:"dark_logo_height"`
+}
+
+// Validate checks that every value is safe to place in an inline style
+func (b Brand) Validate() error {
+	for key, color := range b.Palette {
+		if !isPaletteKey(key) {
+			return fmt.Errorf("unknown palette color %q", key)
+		}
+		if !colorPattern.MatchString(color) {
+			return fmt.Errorf("invalid color %q for palette color %q", color, key)
+		}
+	}
+	for key, color := range b.DarkPalette {
+		if !isPaletteKey(key) {
+			return fmt.Errorf("unknown dark palette color %q", key)
+		}
+		if !colorPattern.MatchString(color) {
+			return fmt.Errorf("invalid color %q for dark palette color %q", color, key)
+		}
+	}
+
+	if b.LinkColor != "" && !colorPattern.MatchString(b.LinkColor) {
+		return fmt.Errorf("invalid link color %q", b.LinkColor)
+	}
+	if b.DarkLinkColor != "" && !colorPattern.MatchString(b.DarkLinkColor) {
+		return fmt.Errorf("invalid dark link color %q", b.DarkLinkColor)
+	}
+
+	if b.FontStack != "" && !fontStackPattern.MatchString(b.FontStack) {
+		return fmt.Errorf("invalid font stack %q", b.FontStack)
+	}
+
+	switch b.IconStyle {
+	case "", IconStyleText, IconStyleColor, IconStyleMono:
+	default:
+		return fmt.Errorf("unknown icon style %q", b.IconStyle)
+	}
+
+	if b.LogoURL != "" {
+		u, err := url.Parse(b.LogoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("logo URL must be an absolute http(s) URL")
+		}
+		if b.LogoWidth <= 0 || b.LogoHeight <= 0 {
+			return fmt.Errorf("logo width and height are required with a logo URL")
+		}
+	}
+
+	if b.DarkLogoURL != "" {
+		if b.LogoURL == "" {
+			return fmt.Errorf("a dark logo needs a logo to replace")
+		}
+		u, err := url.Parse(b.DarkLogoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("dark logo URL must be an absolute http(s) URL")
+		}
+		if b.DarkLogoWidth <= 0 || b.DarkLogoHeight <= 0 {
+			return fmt.Errorf("dark logo width and height are required with a dark logo URL")
+		}
+	}
+
+	return nil
+}
+
+func isPaletteKey(key string) bool {
+	for _, k := range PaletteKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// brandView exposes a brand to templates. Values are validated before
+// they are marked as safe CSS, anything else falls back to the template's
+// own default.
+type brandView struct {
+	brand          Brand
+	LogoURL        string
+	LogoWidth      int
+	LogoHeight     int
+	DarkLogoURL    string
+	DarkLogoWidth  int
+	DarkLogoHeight int
+	IconStyle      string
+}
+
+func newBrandView(brand Brand) brandView {
+	view := brandView{brand: brand, IconStyle: brand.IconStyle}
+	if view.IconStyle == "" {
+		view.IconStyle = IconStyleText
+	}
+	if brand.LogoURL != "" && brand.LogoWidth > 0 && brand.LogoHeight > 0 {
+		view.LogoURL = brand.LogoURL
+		view.LogoWidth = brand.LogoWidth
+		view.LogoHeight = brand.LogoHeight
+		if brand.DarkLogoURL != "" && brand.DarkLogoWidth > 0 && brand.DarkLogoHeight > 0 {
+			view.DarkLogoURL = brand.DarkLogoURL
+			view.DarkLogoWidth = brand.DarkLogoWidth
+			view.DarkLogoHeight = brand.DarkLogoHeight
+		}
+	}
+	return view
+}
+
+// Font returns the brand font stack, or fallback if none is set
+func (b brandView) Font(fallback string) template.CSS {
+	if b.brand.FontStack != "" && fontStackPattern.MatchString(b.brand.FontStack) {
+		return template.CSS(b.brand.FontStack)
+	}
+	return template.CSS(fallback)
+}
+
+// Color returns the named palette color, or fallback if none is set
+func (b brandView) Color(name, fallback string) template.CSS {
+	if color := b.brand.Palette[name]; color != "" && colorPattern.MatchString(color) {
+		return template.CSS(color)
+	}
+	return template.CSS(fallback)
+}
+
+// Link returns the link color, falling back to the primary palette color
+func (b brandView) Link() template.CSS {
+	if b.brand.LinkColor != "" && colorPattern.MatchString(b.brand.LinkColor) {
+		return template.CSS(b.brand.LinkColor)
+	}
+	return b.Color("primary", DefaultLinkColor)
+}
+
+// DarkColor returns the named dark palette color, or its default
+func (b brandView) DarkColor(name string) template.CSS {
+	if color := b.brand.DarkPalette[name]; color != "" && colorPattern.MatchString(color) {
+		return template.CSS(color)
+	}
+	return template.CSS(DarkPaletteDefaults[name])
+}
+
+// DarkLink returns the dark link color, falling back to the dark primary
+// color
+func (b brandView) DarkLink() template.CSS {
+	if b.brand.DarkLinkColor != "" && colorPattern.MatchString(b.brand.DarkLinkColor) {
+		return template.CSS(b.brand.DarkLinkColor)
+	}
+	return b.DarkColor("primary")
+}
+
+// darkClasses maps the classes templates put on colored elements to the
+// dark palette color and property each one switches
+var darkClasses = []struct {
+	class, property, color string
+}{
+	{"sig-text", "color", "text"},
+	{"sig-heading", "color", "heading"},
+	{"sig-secondary", "color", "secondary"},
+	{"sig-muted", "color", "muted"},
+	{"sig-bg", "background-color", "background"},
+}
+
+// DarkCSS returns the dark-mode rules: a prefers-color-scheme media query
+// for Apple Mail and Outlook for Mac, and [data-ogsc] / [data-ogsb]
+// attribute rules for Outlook.com, which marks dark-mode messages that way.
+// Clients that strip <style> blocks keep the light styles.
+func (b brandView) DarkCSS() template.CSS {
+	var rules []string
+	for _, c := range darkClasses {
+		rules = append(rules, fmt.Sprintf(".%s { %s: %s !important; }", c.class, c.property, b.DarkColor(c.color)))
+	}
+	rules = append(rules, fmt.Sprintf(".sig-link { color: %s !important; }", b.DarkLink()))
+	if b.DarkLogoURL != "" {
+		rules = append(rules,
+			".sig-logo-light { display: none !important; }",
+			".sig-logo-dark { display: block !important; max-height: none !important; }",
+		)
+	}
+
+	var css strings.Builder
+	css.WriteString("@media (prefers-color-scheme: dark) { ")
+	css.WriteString(strings.Join(rules, " "))
+	css.WriteString(" }")
+	for _, rule := range rules {
+		attribute := "[data-ogsc]"
+		if strings.Contains(rule, "background-color") {
+			attribute = "[data-ogsb]"
+		}
+		css.WriteString(" " + attribute + " " + rule)
+	}
+	return template.CSS(css.String())
+}
+
+// MinContrast is the WCAG AA contrast ratio for normal-size text
+const MinContrast = 4.5
+
+// DarkContrast is the WCAG contrast ratio of each dark palette text color,
+// and the dark link color as "link", against the dark background. Colors
+// given by name rather than hex are left out.
+func (b Brand) DarkContrast() map[string]float64 {
+	view := newBrandView(b)
+	background, ok := luminance(string(view.DarkColor("background")))
+	if !ok {
+		return nil
+	}
+
+	colors := map[string]string{"link": string(view.DarkLink())}
+	for _, key := range PaletteKeys {
+		if key != "background" && key != "primary" {
+			colors[key] = string(view.DarkColor(key))
+		}
+	}
+
+	ratios := map[string]float64{}
+	for key, color := range colors {
+		if l, ok := luminance(color); ok {
+			lighter, darker := math.Max(l, background), math.Min(l, background)
+			ratios[key] = math.Round((lighter+0.05)/(darker+0.05)*100) / 100
+		}
+	}
+	return ratios
+}
+
+// luminance returns the WCAG relative luminance of a #rgb or #rrggbb color
+func luminance(color string) (float64, bool) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) < 6 || !strings.HasPrefix(color, "#") {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(hex[:6], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	channel := func(shift uint) float64 {
+		c := float64((value>>shift)&0xff) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(16) + 0.7152*channel(8) + 0.0722*channel(0), true
+}
@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDarkCSS(t *testing.T) {
+	got := string(newBrandView(Brand{}).DarkCSS())
+	want := "@media (prefers-color-scheme: dark) { " +
+		".sig-text { color: #e6e6e6 !important; } " +
+		".sig-heading { color: #ffffff !important; } " +
+		".sig-secondary { color: #cccccc !important; } " +
+		".sig-muted { color: #a6a6a6 !important; } " +
+		".sig-bg { background-color: #1f1f1f !important; } " +
+		".sig-link { color: #6cb4ff !important; } }" +
+		" [data-ogsc] .sig-text { color: #e6e6e6 !important; }" +
+		" [data-ogsc] .sig-heading { color: #ffffff !important; }" +
+		" [data-ogsc] .sig-secondary { color: #cccccc !important; }" +
+		" [data-ogsc] .sig-muted { color: #a6a6a6 !important; }" +
+		" [data-ogsb] .sig-bg { background-color: #1f1f1f !important; }" +
+		" [data-ogsc] .sig-link { color: #6cb4ff !important; }"
+	if got != want {
+		t.Errorf("DarkCSS() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDarkCSSBrand(t *testing.T) {
+	brand := Brand{
+		Palette:        map[string]string{"text": "#111111"},
+		DarkPalette:    map[string]string{"text": "#fafafa", "muted": "red; } body { display: none"},
+		DarkLinkColor:  "#ffcc00",
+		LogoURL:        "https://cdn.example.com/logo.png",
+		LogoWidth:      120,
+		LogoHeight:     40,
+		DarkLogoURL:    "https://cdn.example.com/logo-white.png",
+		DarkLogoWidth:  120,
+		DarkLogoHeight: 40,
+	}
+	css := string(newBrandView(brand).DarkCSS())
+	for _, want := range []string{
+		".sig-text { color: #fafafa !important; }",
+		// Invalid colors fall back to the default
+		".sig-muted { color: #a6a6a6 !important; }",
+		"[data-ogsc] .sig-link { color: #ffcc00 !important; }",
+		"[data-ogsc] .sig-logo-light { display: none !important; }",
+		".sig-logo-dark { display: block !important; max-height: none !important; }",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("DarkCSS() lacks %q:\n%s", want, css)
+		}
+	}
+	if strings.Contains(css, "body") {
+		t.Errorf("DarkCSS() includes an invalid color: %s", css)
+	}
+
+	// Without a dark logo, the logo is left alone
+	brand.DarkLogoURL = ""
+	if css := string(newBrandView(brand).DarkCSS()); strings.Contains(css, "sig-logo") {
+		t.Errorf("DarkCSS() switches logos without a dark logo: %s", css)
+	}
+}
+
+func TestRenderDarkMode(t *testing.T) {
+	brand := Brand{
+		DarkPalette:    map[string]string{"heading": "#fefefe"},
+		LogoURL:        "https://cdn.example.com/logo.png",
+		LogoWidth:      120,
+		LogoHeight:     40,
+		DarkLogoURL:    "https://cdn.example.com/logo-white.png",
+		DarkLogoWidth:  100,
+		DarkLogoHeight: 30,
+	}
+	for _, name := range Names() {
+		html, err := Render(name, Input{Data: map[string]interface{}{"name": "Jane"}, Brand: brand})
+		if err != nil {
+			t.Fatalf("Render(%s): %v", name, err)
+		}
+		for _, want := range []string{
+			`<meta name="color-scheme" content="light dark">`,
+			"<style>@media (prefers-color-scheme: dark) { .sig-text",
+			".sig-heading { color: #fefefe !important; }",
+			`class="sig-heading"`,
+			`class="sig-logo-dark" src="https://cdn.example.com/logo-white.png" width="100" height="30"`,
+			"display: none; mso-hide: all; max-height: 0;",
+		} {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s: dark-mode signature lacks %q", name, want)
+			}
+		}
+	}
+}
+
+func TestBrandValidateDark(t *testing.T) {
+	logo := Brand{LogoURL: "https://cdn.example.com/logo.png", LogoWidth: 120, LogoHeight: 40}
+	withDarkLogo := logo
+	withDarkLogo.DarkLogoURL = "https://cdn.example.com/logo-white.png"
+
+	tests := []struct {
+		name    string
+		brand   Brand
+		wantErr string
+	}{
+		{"dark palette", Brand{DarkPalette: map[string]string{"text": "#eee", "background": "black"}, DarkLinkColor: "#6cb4ff"}, ""},
+		{"unknown dark color", Brand{DarkPalette: map[string]string{"accent": "#fff"}}, "unknown dark palette color"},
+		{"invalid dark color", Brand{DarkPalette: map[string]string{"text": "url(x)"}}, "invalid color"},
+		{"invalid dark link", Brand{DarkLinkColor: "#12"}, "invalid dark link color"},
+		{"dark logo without a logo", Brand{DarkLogoURL: "https://cdn.example.com/logo-white.png", DarkLogoWidth: 1, DarkLogoHeight: 1}, "needs a logo"},
+		{"dark logo without size", withDarkLogo, "dark logo width and height"},
+	}
+	for _, tt := range tests {
+		err := tt.brand.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Validate() = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDarkContrast(t *testing.T) {
+	for key, ratio := range (Brand{}).DarkContrast() {
+		if ratio < MinContrast {
+			t.Errorf("default dark %s contrast is %.2f, below %.1f", key, ratio, MinContrast)
+		}
+	}
+
+	ratios := Brand{DarkPalette: map[string]string{"muted": "#333", "secondary": "silver", "background": "#000000"}, DarkLinkColor: "#ffffff"}.DarkContrast()
+	if ratios["link"] != 21 {
+		t.Errorf("white link contrast = %v, want 21", ratios["link"])
+	}
+	if ratios["muted"] >= MinContrast {
+		t.Errorf("#333 on black contrast = %v, want it flagged", ratios["muted"])
+	}
+	if _, ok := ratios["secondary"]; ok {
+		t.Errorf("named color contrast = %v, want it left out", ratios["secondary"])
+	}
+
+	if ratios := (Brand{DarkPalette: map[string]string{"background": "black"}}).DarkContrast(); ratios != nil {
+		t.Errorf("contrast against a named background = %v, want none", ratios)
+	}
+}
@@ -0,0 +1,154 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Audiences a signature can be rendered for
+const (
+	AudienceInternal = "internal"
+	AudienceExternal = "external"
+)
+
+// Export formats every signature can be rendered in. Mailbox deployments
+// use the deploy provider's name as the format.
+const (
+	FormatHTML    = "html"
+	FormatPreview = "preview"
+)
+
+// MaxRules is the most content rules a signature may have
+const MaxRules = 20
+
+// Context describes where a signature is being rendered, for choosing the
+// footer content that applies
+type Context struct {
+	Locale     string
+	Department string
+	Region     string
+	Format     string
+	Audience   string
+}
+
+// Condition restricts a rule to some contexts. Every non-empty list must
+// contain the context's value; an empty condition always matches.
+type Condition struct {
+	// Locales match by language too, so "fr" matches "fr-CA"
+	Locales     []string `json:"locales,omitempty"`
+	Departments []string `json:"departments,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	Formats     []string `json:"formats,omitempty"`
+	Audiences   []string `json:"audiences,omitempty"`
+}
+
+// Matches reports whether the condition holds in the context
+func (c Condition) Matches(ctx Context) bool {
+	return matchesLocale(c.Locales, ctx.Locale) &&
+		matchesAny(c.Departments, ctx.Department) &&
+		matchesAny(c.Regions, ctx.Region) &&
+		matchesAny(c.Formats, ctx.Format) &&
+		matchesAny(c.Audiences, ctx.Audience)
+}
+
+// Rule adds a block of footer text to a signature when its condition holds.
+// The text is either a reusable disclaimer or written inline.
+type Rule struct {
+	DisclaimerID string    `json:"disclaimer_id,omitempty"`
+	Text         string    `json:"text,omitempty"`
+	When         Condition `json:"when"`
+}
+
+// ValidateRules checks each rule has exactly one source of text and only
+// names known audiences and formats
+func ValidateRules(rules []Rule, formats []string) error {
+	if len(rules) > MaxRules {
+		return fmt.Errorf("a signature can have at most %d content rules", MaxRules)
+	}
+	for i, rule := range rules {
+		if (rule.DisclaimerID == "") == (strings.TrimSpace(rule.Text) == "") {
+			return fmt.Errorf("rule %d needs either a disclaimer_id or text", i+1)
+		}
+		for _, audience := range rule.When.Audiences {
+			if audience != AudienceInternal && audience != AudienceExternal {
+				return fmt.Errorf("rule %d: audience must be %s or %s", i+1, AudienceInternal, AudienceExternal)
+			}
+		}
+		for _, format := range rule.When.Formats {
+			if !matchesAny(formats, format) {
+				return fmt.Errorf("rule %d: format must be one of %s", i+1, strings.Join(formats, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// SelectRules returns the rules whose conditions hold in the context, in
+// their original order
+func SelectRules(rules []Rule, ctx Context) []Rule {
+	var selected []Rule
+	for _, rule := range rules {
+		if rule.When.Matches(ctx) {
+			selected = append(selected, rule)
+		}
+	}
+	return selected
+}
+
+// matchesAny reports whether value is in values, ignoring case, or values
+// is empty
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLocale is matchesAny where a bare language also matches its
+// regional locales
+func matchesLocale(locales []string, locale string) bool {
+	if matchesAny(locales, locale) {
+		return true
+	}
+	language, _, found := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return found && matchesAny(locales, language)
+}
+
+var footerTemplate = template.Must(template.New("footer").Parse(footerHTML))
+
+// RenderFooter renders blocks of plain text below a signature, keeping
+// their line breaks
+func RenderFooter(blocks []string) (string, error) {
+	if len(blocks) == 0 {
+		return "", errors.New("no footer blocks")
+	}
+	lines := make([][]string, len(blocks))
+	for i, block := range blocks {
+		lines[i] = strings.Split(strings.TrimSpace(strings.ReplaceAll(block, "\r\n", "\n")), "\n")
+	}
+
+	var buf bytes.Buffer
+	if err := footerTemplate.Execute(&buf, lines); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+const footerHTML = `
+        <table cellpadding="0" cellspacing="0" border="0" style="margin-top: 12px; max-width: 600px;">
+            {{- range .}}
+            <tr>
+                <td style="padding-top: 6px; color: #999; font-family: Arial, sans-serif; font-size: 10px; line-height: 1.4;">
+                    {{- range $i, $line := .}}{{if $i}}<br>{{end}}{{$line}}{{end -}}
+                </td>
+            </tr>
+            {{- end}}
+        </table>`
@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConditionMatches(t *testing.T) {
+	ctx := Context{Locale: "fr-CA", Department: "Sales", Region: "EMEA", Format: FormatHTML, Audience: AudienceExternal}
+	tests := []struct {
+		name string
+		when Condition
+		want bool
+	}{
+		{"empty condition", Condition{}, true},
+		{"exact locale", Condition{Locales: []string{"fr-CA"}}, true},
+		{"language matches regional locale", Condition{Locales: []string{"fr"}}, true},
+		{"other region of the language", Condition{Locales: []string{"fr-FR"}}, false},
+		{"department ignoring case and spaces", Condition{Departments: []string{"Support", " sales "}}, true},
+		{"other department", Condition{Departments: []string{"Support"}}, false},
+		{"every list must match", Condition{Regions: []string{"EMEA"}, Audiences: []string{AudienceInternal}}, false},
+		{"all lists match", Condition{Regions: []string{"EMEA"}, Formats: []string{FormatHTML}, Audiences: []string{AudienceExternal}}, true},
+		{"other format", Condition{Formats: []string{FormatPreview}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.when.Matches(ctx); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A bare language context does not match regional locales
+	if (Condition{Locales: []string{"fr-CA"}}).Matches(Context{Locale: "fr"}) {
+		t.Errorf("fr-CA matched the fr locale")
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	formats := []string{FormatHTML, FormatPreview, "gmail"}
+	tooMany := make([]Rule, MaxRules+1)
+	for i := range tooMany {
+		tooMany[i] = Rule{Text: "x"}
+	}
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr string
+	}{
+		{"valid", []Rule{{DisclaimerID: "d1"}, {Text: "Confidential", When: Condition{Formats: []string{"gmail"}, Audiences: []string{AudienceExternal}}}}, ""},
+		{"no rules", nil, ""},
+		{"too many", tooMany, "at most"},
+		{"no text", []Rule{{Text: "  "}}, "rule 1 needs either"},
+		{"both sources", []Rule{{Text: "x"}, {DisclaimerID: "d1", Text: "x"}}, "rule 2 needs either"},
+		{"unknown audience", []Rule{{Text: "x", When: Condition{Audiences: []string{"partners"}}}}, "audience must be"},
+		{"unknown format", []Rule{{Text: "x", When: Condition{Formats: []string{"pdf"}}}}, "format must be one of html, preview, gmail"},
+	}
+	for _, tt := range tests {
+		err := ValidateRules(tt.rules, formats)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: ValidateRules() = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSelectRules(t *testing.T) {
+	rules := []Rule{
+		{Text: "Everyone"},
+		{Text: "Internal", When: Condition{Audiences: []string{AudienceInternal}}},
+		{Text: "German", When: Condition{Locales: []string{"de"}}},
+		{Text: "External", When: Condition{Audiences: []string{AudienceExternal}}},
+	}
+	selected := SelectRules(rules, Context{Locale: "de-AT", Audience: AudienceExternal})
+
+	var texts []string
+	for _, rule := range selected {
+		texts = append(texts, rule.Text)
+	}
+	if got, want := strings.Join(texts, ","), "Everyone,German,External"; got != want {
+		t.Errorf("SelectRules() = %s, want %s in rule order", got, want)
+	}
+}
+
+func TestRenderFooter(t *testing.T) {
+	html, err := RenderFooter([]string{"Line one\r\nLine <two>\n", "Second block"})
+	if err != nil {
+		t.Fatalf("RenderFooter: %v", err)
+	}
+	if !strings.Contains(html, "Line one<br>Line &lt;two&gt;</td>") || strings.Count(html, "<tr>") != 2 {
+		t.Errorf("footer = %s, want two escaped blocks with their line breaks", html)
+	}
+
+	if _, err := RenderFooter(nil); err == nil {
+		t.Errorf("RenderFooter(nil) succeeded")
+	}
+}
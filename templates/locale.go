@@ -0,0 +1,156 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLocale is used when a signature does not set a locale
+const DefaultLocale = "en"
+
+// Labels are the fixed words templates show around signature fields
+type Labels struct {
+	Call    string
+	Website string
+}
+
+// labels holds the labels of each supported language
+var labels = map[string]Labels{
+	"en": {Call: "Call", Website: "Website"},
+	"ar": {Call: "اتصل", Website: "الموقع الإلكتروني"},
+	"he": {Call: "טלפון", Website: "אתר"},
+	"fr": {Call: "Appeler", Website: "Site web"},
+	"de": {Call: "Anrufen", Website: "Webseite"},
+	"es": {Call: "Llamar", Website: "Sitio web"},
+}
+
+// rtlLanguages are written right to left
+var rtlLanguages = map[string]bool{"ar": true, "he": true, "fa": true, "ur": true}
+
+// localePattern matches locales such as "ar", "he-IL" or "pt_BR"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+// Language returns the lowercase language of a locale, e.g. "ar" for "ar-AE"
+func Language(locale string) string {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToLower(language)
+}
+
+// Region returns the uppercase region of a locale, e.g. "AE" for "ar-AE",
+// or an empty string
+func Region(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for _, part := range parts[1:] {
+		if len(part) == 2 {
+			return strings.ToUpper(part)
+		}
+	}
+	return ""
+}
+
+// IsRTL reports whether the locale's language is written right to left
+func IsRTL(locale string) bool {
+	return rtlLanguages[Language(locale)]
+}
+
+// LabelsFor returns the labels in the locale's language, falling back to
+// English
+func LabelsFor(locale string) Labels {
+	if l, ok := labels[Language(locale)]; ok {
+		return l
+	}
+	return labels[DefaultLocale]
+}
+
+// Locale returns the locale stored in the template data, or the default
+func Locale(data map[string]interface{}) string {
+	if locale := stringField(data, "locale"); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Localize returns the template data with the field values translated for
+// the locale. Translations are stored under "translations", keyed by
+// locale or language, e.g. {"ar": {"name": "..."}}; an exact locale wins
+// over its language and untranslated fields keep their value.
+func Localize(data map[string]interface{}, locale string) map[string]interface{} {
+	translations, _ := data["translations"].(map[string]interface{})
+	if len(translations) == 0 {
+		return data
+	}
+
+	var translated map[string]interface{}
+	for _, key := range []string{locale, Language(locale)} {
+		for candidate, values := range translations {
+			if strings.EqualFold(strings.ReplaceAll(candidate, "_", "-"), strings.ReplaceAll(key, "_", "-")) {
+				translated, _ = values.(map[string]interface{})
+				break
+			}
+		}
+		if translated != nil {
+			break
+		}
+	}
+	if len(translated) == 0 {
+		return data
+	}
+
+	localized := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		localized[key] = value
+	}
+	for key, value := range translated {
+		if text, ok := value.(string); ok && key != "locale" && key != "translations" {
+			localized[key] = text
+		}
+	}
+	return localized
+}
+
+// ValidateLocalization checks the locale and translations in the template
+// data, returning a problem per invalid entry
+func ValidateLocalization(data map[string]interface{}) []string {
+	var problems []string
+	if raw, ok := data["locale"]; ok {
+		if locale, isString := raw.(string); !isString || !localePattern.MatchString(locale) {
+			problems = append(problems, "locale must look like \"en\" or \"ar-AE\"")
+		}
+	}
+
+	raw, ok := data["translations"]
+	if !ok {
+		return problems
+	}
+	translations, isMap := raw.(map[string]interface{})
+	if !isMap {
+		return append(problems, "translations must map locales to translated fields")
+	}
+	for locale, values := range translations {
+		if !localePattern.MatchString(locale) {
+			problems = append(problems, fmt.Sprintf("translation locale %q is invalid", locale))
+			continue
+		}
+		fields, isMap := values.(map[string]interface{})
+		if !isMap {
+			problems = append(problems, fmt.Sprintf("translations for %q must map fields to text", locale))
+			continue
+		}
+		for field, value := range fields {
+			if _, isString := value.(string); !isString {
+				problems = append(problems, fmt.Sprintf("translation of %q for %q must be text", field, locale))
+			}
+		}
+	}
+	return problems
+}
+
+// WrapDirection wraps HTML rendered outside the built-in templates so it
+// lays out right to left for RTL locales
+func WrapDirection(html, locale string) string {
+	if !IsRTL(locale) {
+		return html
+	}
+	return `<div dir="rtl" style="direction: rtl; text-align: right;">` + html + `</div>`
+}
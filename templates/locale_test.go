@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLocaleParts(t *testing.T) {
+	tests := []struct {
+		locale, language, region string
+		rtl                      bool
+	}{
+		{"en", "en", "", false},
+		{"ar-AE", "ar", "AE", true},
+		{"he_il", "he", "IL", true},
+		{"FA", "fa", "", true},
+		{"zh-Hant-TW", "zh", "TW", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := Language(tt.locale); got != tt.language {
+			t.Errorf("Language(%q) = %q, want %q", tt.locale, got, tt.language)
+		}
+		if got := Region(tt.locale); got != tt.region {
+			t.Errorf("Region(%q) = %q, want %q", tt.locale, got, tt.region)
+		}
+		if got := IsRTL(tt.locale); got != tt.rtl {
+			t.Errorf("IsRTL(%q) = %v, want %v", tt.locale, got, tt.rtl)
+		}
+	}
+
+	if got := LabelsFor("fr-CA"); got.Call != "Appeler" {
+		t.Errorf("LabelsFor(fr-CA) = %+v, want French", got)
+	}
+	if got := LabelsFor("ja"); got != labels[DefaultLocale] {
+		t.Errorf("LabelsFor(ja) = %+v, want English", got)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	data := map[string]interface{}{
+		"name":      "Jane Doe",
+		"job_title": "CTO",
+		"translations": map[string]interface{}{
+			"ar":    map[string]interface{}{"name": "جين دو", "job_title": "المديرة التقنية"},
+			"ar_AE": map[string]interface{}{"name": "جين", "locale": "en", "phone": 5},
+		},
+	}
+
+	// The exact locale wins over the language, other fields keep their value
+	got := Localize(data, "ar-AE")
+	if got["name"] != "جين" || got["job_title"] != "CTO" || got["locale"] != nil || got["phone"] != nil {
+		t.Errorf("Localize(ar-AE) = %v", got)
+	}
+	if got := Localize(data, "ar-SA"); got["name"] != "جين دو" || got["job_title"] != "المديرة التقنية" {
+		t.Errorf("Localize(ar-SA) = %v, want the Arabic translation", got)
+	}
+	if got := Localize(data, "fr"); !reflect.DeepEqual(got, data) {
+		t.Errorf("Localize(fr) = %v, want the data unchanged", got)
+	}
+	if data["name"] != "Jane Doe" {
+		t.Errorf("Localize changed the original data")
+	}
+}
+
+func TestValidateLocalization(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want int
+	}{
+		{"valid", map[string]interface{}{"locale": "ar-AE", "translations": map[string]interface{}{"ar": map[string]interface{}{"name": "x"}}}, 0},
+		{"nothing", map[string]interface{}{}, 0},
+		{"bad locale", map[string]interface{}{"locale": "arabic!"}, 1},
+		{"locale not text", map[string]interface{}{"locale": 5}, 1},
+		{"translations not a map", map[string]interface{}{"translations": "ar"}, 1},
+		{"bad entries", map[string]interface{}{"translations": map[string]interface{}{
+			"x":  map[string]interface{}{},
+			"fr": "Jane",
+			"de": map[string]interface{}{"name": 1},
+		}}, 3},
+	}
+	for _, tt := range tests {
+		if problems := ValidateLocalization(tt.data); len(problems) != tt.want {
+			t.Errorf("%s: problems = %q, want %d", tt.name, problems, tt.want)
+		}
+	}
+}
+
+func TestRenderDirection(t *testing.T) {
+	data := map[string]interface{}{
+		"name":   "Jane Doe",
+		"phone":  "+971 4 123 4567",
+		"social": []interface{}{map[string]interface{}{"network": "x", "url": "https://x.com/jane"}},
+	}
+	for _, name := range Names() {
+		ltr, err := Render(name, Input{Data: data})
+		if err != nil {
+			t.Fatalf("Render(%s): %v", name, err)
+		}
+		rtl, err := Render(name, Input{Data: data, Locale: "ar-AE"})
+		if err != nil {
+			t.Fatalf("Render(%s, ar-AE): %v", name, err)
+		}
+
+		for _, want := range []string{`dir="ltr" style="direction: ltr; text-align: left;`, "margin-right:"} {
+			if !strings.Contains(ltr, want) {
+				t.Errorf("%s: LTR signature lacks %q", name, want)
+			}
+		}
+		for _, want := range []string{`dir="rtl" style="direction: rtl; text-align: right;`, "margin-left:"} {
+			if !strings.Contains(rtl, want) {
+				t.Errorf("%s: RTL signature lacks %q", name, want)
+			}
+		}
+		// The table is RTL too, for clients dropping the wrapper's styles
+		if strings.Count(rtl, `dir="rtl"`) < 2 {
+			t.Errorf("%s: RTL table lacks its dir attribute", name)
+		}
+		// Phone numbers stay left to right inside RTL text
+		if !strings.Contains(rtl, `dir="ltr"`) || !strings.Contains(rtl, "&#43;971 4 123 4567") || !strings.Contains(rtl, "unicode-bidi: embed") {
+			t.Errorf("%s: RTL phone number is not embedded left to right", name)
+		}
+	}
+
+	// The locale stored in the data applies without an override
+	if html, _ := Render(DefaultTemplate, Input{Data: map[string]interface{}{"locale": "he"}}); !strings.Contains(html, `dir="rtl"`) {
+		t.Errorf("stored he locale did not render right to left")
+	}
+}
+
+func TestWrapDirection(t *testing.T) {
+	if got := WrapDirection("<p>Hi</p>", "en-US"); got != "<p>Hi</p>" {
+		t.Errorf("WrapDirection(en-US) = %s, want unchanged", got)
+	}
+	if got, want := WrapDirection("<p>Hi</p>", "he-IL"), `<div dir="rtl" style="direction: rtl; text-align: right;"><p>Hi</p></div>`; got != want {
+		t.Errorf("WrapDirection(he-IL) = %s, want %s", got, want)
+	}
+}
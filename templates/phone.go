@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"strings"
+)
+
+// phonePlan is how one country writes its phone numbers
+type phonePlan struct {
+	// Code is the country calling code
+	Code string
+	// Regions use this plan for numbers written without a country code
+	Regions []string
+	// Groups splits national numbers of each length into digit groups
+	Groups map[int][]int
+	// Separator goes between the groups
+	Separator string
+}
+
+// phonePlans are the countries whose numbers are formatted; others are
+// shown as entered
+var phonePlans = []phonePlan{
+	{Code: "1", Regions: []string{"US", "CA"}, Groups: map[int][]int{10: {3, 3, 4}}, Separator: " "},
+	{Code: "20", Regions: []string{"EG"}, Groups: map[int][]int{9: {1, 4, 4}, 10: {2, 4, 4}}, Separator: " "},
+	{Code: "33", Regions: []string{"FR"}, Groups: map[int][]int{9: {1, 2, 2, 2, 2}}, Separator: " "},
+	{Code: "34", Regions: []string{"ES"}, Groups: map[int][]int{9: {3, 3, 3}}, Separator: " "},
+	{Code: "44", Regions: []string{"GB"}, Groups: map[int][]int{10: {4, 6}}, Separator: " "},
+	{Code: "49", Regions: []string{"DE"}, Groups: map[int][]int{10: {3, 7}, 11: {3, 8}}, Separator: " "},
+	{Code: "966", Regions: []string{"SA"}, Groups: map[int][]int{8: {1, 3, 4}, 9: {2, 3, 4}}, Separator: " "},
+	{Code: "971", Regions: []string{"AE"}, Groups: map[int][]int{8: {1, 3, 4}, 9: {2, 3, 4}}, Separator: " "},
+	{Code: "972", Regions: []string{"IL"}, Groups: map[int][]int{8: {1, 3, 4}, 9: {2, 3, 4}}, Separator: "-"},
+}
+
+// Phone is a phone number ready for display and for tel: links
+type Phone struct {
+	Display string
+	// URI is the number as dialled, in international form when known
+	URI string
+}
+
+// FormatPhone formats the number with its country's grouping. Numbers
+// without a country code are read as national numbers of the locale's
+// region. Numbers that cannot be read are shown as entered.
+func FormatPhone(number, locale string) Phone {
+	number = strings.TrimSpace(number)
+	entered := Phone{Display: number, URI: dialable(number)}
+
+	digits := dialable(number)
+	var plan *phonePlan
+	var national string
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		plan, national = planForCode(digits[1:])
+	case strings.HasPrefix(digits, "00"):
+		plan, national = planForCode(digits[2:])
+	default:
+		plan = planForRegion(Region(locale))
+		// Drop the trunk prefix dialled before national numbers
+		national = strings.TrimPrefix(digits, "0")
+		if plan != nil && plan.Code == "1" {
+			national = strings.TrimPrefix(digits, "1")
+		}
+	}
+	if plan == nil {
+		return entered
+	}
+
+	groups, ok := plan.Groups[len(national)]
+	if !ok {
+		return entered
+	}
+	parts := make([]string, 0, len(groups))
+	rest := national
+	for _, size := range groups {
+		parts = append(parts, rest[:size])
+		rest = rest[size:]
+	}
+	return Phone{
+		Display: "+" + plan.Code + " " + strings.Join(parts, plan.Separator),
+		URI:     "+" + plan.Code + national,
+	}
+}
+
+// dialable strips everything but digits and a leading plus sign
+func dialable(number string) string {
+	var b strings.Builder
+	for i, r := range number {
+		if r >= '0' && r <= '9' || r == '+' && i == 0 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// planForCode finds the plan whose calling code starts the digits and
+// returns the national number after it
+func planForCode(digits string) (*phonePlan, string) {
+	for i := range phonePlans {
+		if strings.HasPrefix(digits, phonePlans[i].Code) {
+			return &phonePlans[i], digits[len(phonePlans[i].Code):]
+		}
+	}
+	return nil, ""
+}
+
+// planForRegion finds the plan used in a region
+func planForRegion(region string) *phonePlan {
+	for i := range phonePlans {
+		for _, r := range phonePlans[i].Regions {
+			if r == region {
+				return &phonePlans[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package templates
+
+import "testing"
+
+func TestFormatPhone(t *testing.T) {
+	tests := []struct {
+		number, locale string
+		want           Phone
+	}{
+		{"+1 (555) 123-4567", "de-DE", Phone{"+1 555 123 4567", "+15551234567"}},
+		{"(555) 123-4567", "en-US", Phone{"+1 555 123 4567", "+15551234567"}},
+		{"1-555-123-4567", "en-CA", Phone{"+1 555 123 4567", "+15551234567"}},
+		{"01 23 45 67 89", "fr-FR", Phone{"+33 1 23 45 67 89", "+33123456789"}},
+		{"0044 20 7946 0958", "en-US", Phone{"+44 2079 460958", "+442079460958"}},
+		{"030 12345678", "de_DE", Phone{"+49 301 2345678", "+493012345678"}},
+		{"03-123-4567", "he-IL", Phone{"+972 3-123-4567", "+97231234567"}},
+		{"+966 50 123 4567", "ar", Phone{"+966 50 123 4567", "+966501234567"}},
+		{"+971 4 123 4567", "ar-AE", Phone{"+971 4 123 4567", "+97141234567"}},
+		// Numbers that cannot be read are shown as entered
+		{" +81 3 1234 5678 ", "ja-JP", Phone{"+81 3 1234 5678", "+81312345678"}},
+		{"555 1234", "en", Phone{"555 1234", "5551234"}},
+		{"+1 555 1234", "en-US", Phone{"+1 555 1234", "+15551234"}},
+		{"ext. 12", "en-US", Phone{"ext. 12", "12"}},
+		{"", "en-US", Phone{}},
+	}
+	for _, tt := range tests {
+		if got := FormatPhone(tt.number, tt.locale); got != tt.want {
+			t.Errorf("FormatPhone(%q, %q) = %+v, want %+v", tt.number, tt.locale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package templates
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var pixelTemplate = template.Must(template.New("pixel").Parse(pixelHTML))
+
+// RenderPixel renders an invisible 1x1 image loading the URL, used to
+// count how often a signature is opened
+func RenderPixel(url string) (string, error) {
+	var buf bytes.Buffer
+	if err := pixelTemplate.Execute(&buf, url); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+const pixelHTML = `
+        <img src="{{.}}" width="1" height="1" alt="" style="display: block; width: 1px; height: 1px; border: 0;">`
@@ -0,0 +1,278 @@
+package templates
+
+import (
+	"bytes"
+	"email-signature-backend/social"
+	"errors"
+	"html/template"
+	"sort"
+)
+
+// DefaultTemplate is used when a request does not name a template
+const DefaultTemplate = "basic"
+
+// ErrUnknownTemplate is returned when a template name is not registered
+var ErrUnknownTemplate = errors.New("unknown template")
+
+// registry holds every built-in signature template keyed by name
+var registry = map[string]*template.Template{
+	"basic":  template.Must(template.New("basic").Parse(basicHTML)),
+	"modern": template.Must(template.New("modern").Parse(modernHTML)),
+}
+
+// Names returns the registered template names in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Exists reports whether a template with the given name is registered
+func Exists(name string) bool {
+	_, ok := registry[name]
+	return ok
+}
+
+// Input is everything a template needs to render one signature
+type Input struct {
+	Data  map[string]interface{}
+	Brand Brand
+	// Locale overrides the locale stored in the data
+	Locale string
+}
+
+// Render renders the named template for a signature
+func Render(name string, input Input) (string, error) {
+	tmpl, ok := registry[name]
+	if !ok {
+		return "", ErrUnknownTemplate
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newView(input)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// view is the value templates are executed against
+type view struct {
+	Name     string
+	JobTitle string
+	Company  string
+	Phone    Phone
+	Website  string
+	Social   []socialLink
+	Headshot image
+	Brand    brandView
+	Labels   Labels
+	// Dir is the text direction; Start and End are the sides lines begin
+	// and end on, for alignment and margins
+	Dir   string
+	Start string
+	End   string
+}
+
+// socialLink is a social profile link, shown as an icon when the brand
+// kit uses an image icon style and as the network name otherwise
+type socialLink struct {
+	Name string
+	URL  string
+	Icon image
+}
+
+// image is an <img> with the explicit dimensions email clients need
+type image struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// newView extracts the known fields from the template data, leaving
+// missing or mistyped fields empty instead of failing
+func newView(input Input) view {
+	locale := input.Locale
+	if locale == "" {
+		locale = Locale(input.Data)
+	}
+	data := Localize(input.Data, locale)
+	brand := newBrandView(input.Brand)
+
+	// Headshots are only shown when their dimensions are known, see the
+	// /api/assets upload response
+	headshotData, _ := data["headshot"].(map[string]interface{})
+	headshot := image{
+		URL:    stringField(headshotData, "url"),
+		Width:  intField(headshotData, "width"),
+		Height: intField(headshotData, "height"),
+	}
+	if headshot.Width <= 0 || headshot.Height <= 0 {
+		headshot = image{}
+	}
+
+	v := view{
+		Name:     stringField(data, "name"),
+		JobTitle: stringField(data, "job_title"),
+		Company:  stringField(data, "company"),
+		Phone:    FormatPhone(stringField(data, "phone"), locale),
+		Website:  stringField(data, "website"),
+		Social:   newSocialLinks(data, brand.IconStyle),
+		Headshot: headshot,
+		Brand:    brand,
+		Labels:   LabelsFor(locale),
+		Dir:      "ltr",
+		Start:    "left",
+		End:      "right",
+	}
+	if IsRTL(locale) {
+		v.Dir, v.Start, v.End = "rtl", "right", "left"
+	}
+	return v
+}
+
+// newSocialLinks resolves the signature's social entries against the
+// network catalog, skipping networks it does not know
+func newSocialLinks(data map[string]interface{}, iconStyle string) []socialLink {
+	size := intField(data, "social_icon_size")
+	if !social.ValidSize(size) {
+		size = social.DefaultSize
+	}
+
+	var links []socialLink
+	for _, entry := range social.Entries(data) {
+		network, ok := social.Lookup(entry.Network)
+		if !ok {
+			continue
+		}
+
+		link := socialLink{Name: network.Name, URL: entry.URL}
+		if iconStyle == IconStyleColor || iconStyle == IconStyleMono {
+			link.Icon = image{URL: social.IconURL(iconStyle, size, network.Key), Width: size, Height: size}
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// stringField returns data[key] if it is a string, or an empty string
+func stringField(data map[string]interface{}, key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// intField returns data[key] if it is a number, or zero. JSON numbers
+// decode as float64.
+func intField(data map[string]interface{}, key string) int {
+	switch value := data[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	}
+	return 0
+}
+
+const basicHTML = `
+        <meta name="color-scheme" content="light dark">
+        <meta name="supported-color-schemes" content="light dark">
+        <style>{{.Brand.DarkCSS}}</style>
+        <div class="sig-text" dir="{{.Dir}}" style="direction: {{.Dir}}; text-align: {{.Start}}; font-family: {{.Brand.Font "Arial, sans-serif"}}; color: {{.Brand.Color "text" "#444"}}; font-size: 14px; line-height: 1.5;">
+            <table dir="{{.Dir}}">
+                {{- if .Brand.LogoURL}}
+                <tr>
+                    <td>
+                        <img class="sig-logo-light" src="{{.Brand.LogoURL}}" width="{{.Brand.LogoWidth}}" height="{{.Brand.LogoHeight}}" alt="{{.Company}}" style="display: block; border: 0;">
+                        {{- if .Brand.DarkLogoURL}}
+                        <img class="sig-logo-dark" src="{{.Brand.DarkLogoURL}}" width="{{.Brand.DarkLogoWidth}}" height="{{.Brand.DarkLogoHeight}}" alt="{{.Company}}" style="display: none; mso-hide: all; max-height: 0; border: 0;">
+                        {{- end}}
+                    </td>
+                </tr>
+                {{- end}}
+                {{- if .Headshot.URL}}
+                <tr>
+                    <td>
+                        <img src="{{.Headshot.URL}}" width="{{.Headshot.Width}}" height="{{.Headshot.Height}}" alt="{{.Name}}" style="display: block; border: 0; border-radius: 50%;">
+                    </td>
+                </tr>
+                {{- end}}
+                <tr>
+                    <td>
+                        <div class="sig-heading" style="font-size: 18px; font-weight: bold; color: {{.Brand.Color "heading" "#222"}};">{{.Name}}</div>
+                        <div class="sig-secondary" style="color: {{.Brand.Color "secondary" "#666"}};">{{.JobTitle}}</div>
+                        <div class="sig-muted" style="color: {{.Brand.Color "muted" "#999"}}; font-size: 12px;">{{.Company}}</div>
+                    </td>
+                </tr>
+                <tr>
+                    <td>
+                        <div style="margin-top: 10px;">
+                            <a class="sig-link" href="tel:{{.Phone.URI}}" dir="ltr" style="color: {{.Brand.Link}}; text-decoration: none; unicode-bidi: embed;">{{.Phone.Display}}</a> |
+                            <a class="sig-link" href="{{.Website}}" style="color: {{.Brand.Link}}; text-decoration: none;">{{.Website}}</a>
+                        </div>
+                        {{- if .Social}}
+                        <div style="margin-top: 10px;">
+                            {{- range .Social}}
+                            <a class="sig-link" href="{{.URL}}" style="color: {{$.Brand.Link}}; text-decoration: none; margin-{{$.End}}: 10px;">
+                                {{- if .Icon.URL}}<img src="{{.Icon.URL}}" width="{{.Icon.Width}}" height="{{.Icon.Height}}" alt="{{.Name}}" style="border: 0; vertical-align: middle;">{{else}}{{.Name}}{{end -}}
+                            </a>
+                            {{- end}}
+                        </div>
+                        {{- end}}
+                    </td>
+                </tr>
+            </table>
+        </div>
+    `
+
+const modernHTML = `
+        <meta name="color-scheme" content="light dark">
+        <meta name="supported-color-schemes" content="light dark">
+        <style>{{.Brand.DarkCSS}}</style>
+        <div class="sig-text" dir="{{.Dir}}" style="direction: {{.Dir}}; text-align: {{.Start}}; font-family: {{.Brand.Font "Verdana, sans-serif"}}; color: {{.Brand.Color "text" "#222"}}; font-size: 16px; line-height: 1.8;">
+            <table class="sig-bg" dir="{{.Dir}}" style="width: 100%; border-spacing: 10px; background-color: {{.Brand.Color "background" "#f9f9f9"}}; padding: 10px;">
+                {{- if .Brand.LogoURL}}
+                <tr>
+                    <td style="padding: 5px;">
+                        <img class="sig-logo-light" src="{{.Brand.LogoURL}}" width="{{.Brand.LogoWidth}}" height="{{.Brand.LogoHeight}}" alt="{{.Company}}" style="display: block; border: 0;">
+                        {{- if .Brand.DarkLogoURL}}
+                        <img class="sig-logo-dark" src="{{.Brand.DarkLogoURL}}" width="{{.Brand.DarkLogoWidth}}" height="{{.Brand.DarkLogoHeight}}" alt="{{.Company}}" style="display: none; mso-hide: all; max-height: 0; border: 0;">
+                        {{- end}}
+                    </td>
+                </tr>
+                {{- end}}
+                {{- if .Headshot.URL}}
+                <tr>
+                    <td style="padding: 5px;">
+                        <img src="{{.Headshot.URL}}" width="{{.Headshot.Width}}" height="{{.Headshot.Height}}" alt="{{.Name}}" style="display: block; border: 0; border-radius: 50%;">
+                    </td>
+                </tr>
+                {{- end}}
+                <tr>
+                    <td style="padding: 5px;">
+                        <div class="sig-heading" style="font-size: 20px; font-weight: bold; color: {{.Brand.Color "heading" "#222"}};">{{.Name}}</div>
+                        <div class="sig-secondary" style="color: {{.Brand.Color "secondary" "#555"}};">{{.JobTitle}}</div>
+                        <div class="sig-muted" style="font-size: 12px; color: {{.Brand.Color "muted" "#777"}};">{{.Company}}</div>
+                    </td>
+                </tr>
+                <tr>
+                    <td style="padding: 5px;">
+                        <a class="sig-link" href="tel:{{.Phone.URI}}" style="color: {{.Brand.Link}}; text-decoration: none; font-size: 14px;">{{.Labels.Call}}: <span dir="ltr" style="unicode-bidi: embed;">{{.Phone.Display}}</span></a><br>
+                        <a class="sig-link" href="{{.Website}}" style="color: {{.Brand.Link}}; text-decoration: none; font-size: 14px;">{{.Labels.Website}}: <span dir="ltr" style="unicode-bidi: embed;">{{.Website}}</span></a>
+                    </td>
+                </tr>
+                {{- if .Social}}
+                <tr>
+                    <td style="padding: 5px;">
+                        {{- range .Social}}
+                        <a class="sig-link" href="{{.URL}}" style="color: {{$.Brand.Link}}; text-decoration: none; margin-{{$.End}}: 15px;">
+                            {{- if .Icon.URL}}<img src="{{.Icon.URL}}" width="{{.Icon.Width}}" height="{{.Icon.Height}}" alt="{{.Name}}" style="border: 0; vertical-align: middle;">{{else}}{{.Name}}{{end -}}
+                        </a>
+                        {{- end}}
+                    </td>
+                </tr>
+                {{- end}}
+            </table>
+        </div>
+    `
@@ -0,0 +1,100 @@
+package tracking
+
+import (
+	"sync"
+	"time"
+)
+
+// BurstDetector finds clicks from one IP address on different links of a
+// signature within a window, the way gateways follow every link of a
+// message on delivery. It works on the IP address as received, before the
+// privacy mode truncates or hashes it, and keeps it in memory only for
+// the window. Each instance sees only its own clicks, so bursts spread
+// over several instances by a load balancer can go unnoticed.
+type BurstDetector struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	recent    map[burstKey][]burstClick
+	lastSweep time.Time
+}
+
+type burstKey struct {
+	ip    string
+	group string
+}
+
+type burstClick struct {
+	linkID  string
+	clickID string
+	at      time.Time
+	flagged bool
+}
+
+// Bursts detects bursts within ScanBurstWindow
+var Bursts = NewBurstDetector(ScanBurstWindow)
+
+// NewBurstDetector returns a detector for bursts within window
+func NewBurstDetector(window time.Duration) *BurstDetector {
+	return &BurstDetector{window: window, recent: map[burstKey][]burstClick{}}
+}
+
+// Record notes a click from ip on a link of group, usually the link's
+// signature, and returns the IDs of the clicks that turn out to be part
+// of a burst and have not been returned before, this one included
+func (d *BurstDetector) Record(ip, group, linkID, clickID string, at time.Time) []string {
+	if ip == "" || group == "" {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(at)
+
+	key := burstKey{ip: ip, group: group}
+	clicks := append(d.live(d.recent[key], at), burstClick{linkID: linkID, clickID: clickID, at: at})
+	d.recent[key] = clicks
+
+	links := map[string]bool{}
+	for _, click := range clicks {
+		links[click.linkID] = true
+	}
+	if len(links) < 2 {
+		return nil
+	}
+
+	var flagged []string
+	for i := range clicks {
+		if !clicks[i].flagged {
+			clicks[i].flagged = true
+			flagged = append(flagged, clicks[i].clickID)
+		}
+	}
+	return flagged
+}
+
+// live returns the clicks still within the window at the given time
+func (d *BurstDetector) live(clicks []burstClick, at time.Time) []burstClick {
+	kept := clicks[:0]
+	for _, click := range clicks {
+		if at.Sub(click.at) < d.window {
+			kept = append(kept, click)
+		}
+	}
+	return kept
+}
+
+// sweep drops IP addresses without clicks in the window, once per window
+func (d *BurstDetector) sweep(at time.Time) {
+	if at.Sub(d.lastSweep) < d.window {
+		return
+	}
+	d.lastSweep = at
+	for key, clicks := range d.recent {
+		if clicks = d.live(clicks, at); len(clicks) == 0 {
+			delete(d.recent, key)
+		} else {
+			d.recent[key] = clicks
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tracking
+
+import (
+	"strings"
+)
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+)
+
+// Device is what a request was made with, read from its user agent.
+// Values that cannot be told are empty.
+type Device struct {
+	Type    string
+	OS      string
+	Browser string
+}
+
+// agentRule names what a user agent containing any of the markers runs
+type agentRule struct {
+	name    string
+	markers []string
+}
+
+// osRules and browserRules are tried in order; browsers built on another
+// one's engine come first since their user agents name both
+var (
+	osRules = []agentRule{
+		{"iOS", []string{"iphone", "ipad", "ipod"}},
+		{"Android", []string{"android"}},
+		{"Windows", []string{"windows"}},
+		{"ChromeOS", []string{"cros"}},
+		{"macOS", []string{"macintosh", "mac os x"}},
+		{"Linux", []string{"linux"}},
+	}
+	browserRules = []agentRule{
+		{"Outlook", []string{"microsoft outlook", "msoffice"}},
+		{"Edge", []string{"edg/", "edga/", "edgios/"}},
+		{"Opera", []string{"opr/", "opera"}},
+		{"Samsung Internet", []string{"samsungbrowser"}},
+		{"Chrome", []string{"chrome/", "crios/"}},
+		{"Firefox", []string{"firefox/", "fxios/"}},
+		{"Internet Explorer", []string{"msie ", "trident/"}},
+		{"Safari", []string{"safari/"}},
+	}
+)
+
+// ParseDevice reads the device type, operating system and browser from a
+// user agent
+func ParseDevice(userAgent string) Device {
+	agent := strings.ToLower(userAgent)
+	if agent == "" {
+		return Device{}
+	}
+
+	device := Device{OS: matchAgent(agent, osRules), Browser: matchAgent(agent, browserRules)}
+	switch {
+	case strings.Contains(agent, "ipad") || strings.Contains(agent, "tablet") ||
+		device.OS == "Android" && !strings.Contains(agent, "mobile"):
+		device.Type = DeviceTablet
+	case strings.Contains(agent, "mobile") || strings.Contains(agent, "iphone") || strings.Contains(agent, "ipod"):
+		device.Type = DeviceMobile
+	case device.OS != "":
+		device.Type = DeviceDesktop
+	}
+	return device
+}
+
+func matchAgent(agent string, rules []agentRule) string {
+	for _, rule := range rules {
+		for _, marker := range rule.markers {
+			if strings.Contains(agent, marker) {
+				return rule.name
+			}
+		}
+	}
+	return ""
+}
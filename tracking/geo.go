@@ -0,0 +1,68 @@
+package tracking
+
+import (
+	"log"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is where a request came from, as far as the geo-IP database
+// knows. Country is an ISO 3166-1 code; Region and City are English names.
+type Location struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// geoDB is the database loaded by Setup, if any
+var geoDB *maxminddb.Reader
+
+// geoRecord is the part of a GeoIP2 or GeoLite2 City or Country record
+// that Locate reads
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// setupGeo opens the MaxMind database in GEOIP_DATABASE_FILE, if set
+func setupGeo() {
+	path := os.Getenv("GEOIP_DATABASE_FILE")
+	if path == "" {
+		return
+	}
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open geo-IP database: %v", err)
+	}
+	geoDB = db
+	log.Printf("Geo-IP database: %s\n", db.Metadata.DatabaseType)
+}
+
+// Locate looks the IP address up in the geo-IP database. Without a
+// database, or for unknown and private addresses, the location is empty.
+func Locate(ip string) Location {
+	addr := net.ParseIP(ip)
+	if geoDB == nil || addr == nil {
+		return Location{}
+	}
+
+	var record geoRecord
+	if err := geoDB.Lookup(addr, &record); err != nil {
+		log.Printf("Failed to look up IP address: %v\n", err)
+		return Location{}
+	}
+	location := Location{Country: record.Country.ISOCode, City: record.City.Names["en"]}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].Names["en"]
+	}
+	return location
+}
@@ -0,0 +1,151 @@
+package tracking
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"email-signature-backend/database"
+	"encoding/hex"
+	"log"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How IP addresses of clicks and opens are stored
+const (
+	IPModeFull = "full"
+	// IPModeTruncate zeroes the host part: the last octet of IPv4
+	// addresses and all but the first 48 bits of IPv6 addresses
+	IPModeTruncate = "truncate"
+	// IPModeHash stores a keyed hash that changes every day, so addresses
+	// can be told apart within a day but not recovered or followed across
+	// days
+	IPModeHash = "hash"
+)
+
+// IPMode is the mode set in CLICK_IP_MODE, IPModeFull by default
+var IPMode = IPModeFull
+
+// DefaultRetentionDays is how long clicks and opens of signatures outside
+// organizations, or of organizations without their own retention period,
+// are kept; 0 keeps them forever
+var DefaultRetentionDays int
+
+// setupPrivacy reads CLICK_IP_MODE and CLICK_RETENTION_DAYS
+func setupPrivacy() {
+	switch mode := os.Getenv("CLICK_IP_MODE"); mode {
+	case "":
+	case IPModeFull, IPModeTruncate, IPModeHash:
+		IPMode = mode
+	default:
+		log.Fatalf("Unknown CLICK_IP_MODE %q", mode)
+	}
+
+	if days := os.Getenv("CLICK_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid CLICK_RETENTION_DAYS %q", days)
+		}
+		DefaultRetentionDays = n
+	}
+}
+
+// ProtectIP returns the IP address as IPMode stores it
+func ProtectIP(ctx context.Context, ip string) (string, error) {
+	switch IPMode {
+	case IPModeTruncate:
+		return TruncateIP(ip), nil
+	case IPModeHash:
+		salt, err := DailySalt(ctx)
+		if err != nil {
+			return "", err
+		}
+		return Hash(salt, ip), nil
+	}
+	return ip, nil
+}
+
+// VisitorKey identifies a visitor for the current day without storing
+// anything that identifies them: a keyed hash of their IP address and user
+// agent with the day's salt. Keys change every day.
+func VisitorKey(ctx context.Context, ip, userAgent string) (string, error) {
+	salt, err := DailySalt(ctx)
+	if err != nil {
+		return "", err
+	}
+	return Hash(salt, ip, userAgent), nil
+}
+
+// TruncateIP zeroes the host part of an IP address. Values that are not
+// IP addresses are dropped.
+func TruncateIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
+
+// Hash returns a keyed hash of the values
+func Hash(salt []byte, values ...string) string {
+	mac := hmac.New(sha256.New, salt)
+	for _, value := range values {
+		mac.Write([]byte(value))
+		// Separate values so ("ab", "c") and ("a", "bc") differ
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// saltCache holds the salt of the current day
+var saltCache struct {
+	sync.Mutex
+	day  string
+	salt []byte
+}
+
+// DailySalt returns the secret salt of the current day, creating it on
+// first use. Salts are shared through the database so every instance
+// hashes alike, and deleted by the retention job once the day is over.
+func DailySalt(ctx context.Context) ([]byte, error) {
+	day := time.Now().UTC().Format(time.DateOnly)
+
+	saltCache.Lock()
+	defer saltCache.Unlock()
+	if saltCache.day == day {
+		return saltCache.salt, nil
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	err := database.DB.QueryRow(
+		ctx,
+		`INSERT INTO tracking_salts (day, salt) VALUES ($1, $2)
+         ON CONFLICT (day) DO UPDATE SET day = EXCLUDED.day
+         RETURNING salt`,
+		day,
+		salt,
+	).Scan(&salt)
+	if err != nil {
+		return nil, err
+	}
+
+	saltCache.day = day
+	saltCache.salt = salt
+	return salt, nil
+}
@@ -0,0 +1,31 @@
+package tracking
+
+import (
+	"strings"
+)
+
+// Image proxies that fetch images on behalf of recipients. Their requests
+// say that a message was delivered or prefetched, not that a person read
+// it.
+const (
+	ProxyGmail = "gmail"
+	// ProxyApple is Apple Mail Privacy Protection, which fetches the
+	// images of every message as it arrives
+	ProxyApple = "apple_mail"
+	ProxyYahoo = "yahoo"
+)
+
+// ImageProxy returns the image proxy that sent a request with the user
+// agent, or an empty string for requests from a mail client
+func ImageProxy(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "GoogleImageProxy"):
+		return ProxyGmail
+	case strings.Contains(userAgent, "YahooMailProxy"):
+		return ProxyYahoo
+	// Apple's proxy sends a bare user agent no browser or mail client uses
+	case strings.TrimSpace(userAgent) == "Mozilla/5.0":
+		return ProxyApple
+	}
+	return ""
+}
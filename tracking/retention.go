@@ -0,0 +1,72 @@
+package tracking
+
+import (
+	"context"
+	"email-signature-backend/database"
+	"log"
+	"time"
+)
+
+// retentionTick is how often expired clicks and opens are purged
+const retentionTick = time.Hour
+
+// StartRetention purges clicks and opens older than their organization's
+// retention period, or DefaultRetentionDays, every hour until the context
+// is cancelled. It also deletes the salts of past days.
+func StartRetention(ctx context.Context) {
+	go func() {
+		purgeExpired(ctx)
+		ticker := time.NewTicker(retentionTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purgeExpired(ctx)
+			}
+		}
+	}()
+}
+
+// retentionDays is the retention period of rows whose signature is
+// aliased s, given DefaultRetentionDays in $1
+const retentionDays = `COALESCE((SELECT click_retention_days FROM organizations WHERE id = s.organization_id), $1)`
+
+func purgeExpired(ctx context.Context) {
+	clicks, err := database.DB.Exec(
+		ctx,
+		`DELETE FROM clicks WHERE id IN (
+             SELECT c.id FROM clicks c
+             JOIN links l ON l.id = c.link_id
+             LEFT JOIN signatures s ON s.id = l.signature_id
+             WHERE `+retentionDays+` > 0 AND c.timestamp < NOW() - make_interval(days => `+retentionDays+`)
+         )`,
+		DefaultRetentionDays,
+	)
+	if err != nil {
+		log.Printf("Failed to purge expired clicks: %v\n", err)
+	}
+
+	opens, err := database.DB.Exec(
+		ctx,
+		`DELETE FROM opens WHERE id IN (
+             SELECT o.id FROM opens o
+             JOIN signatures s ON s.id = o.signature_id
+             WHERE `+retentionDays+` > 0 AND o.timestamp < NOW() - make_interval(days => `+retentionDays+`)
+         )`,
+		DefaultRetentionDays,
+	)
+	if err != nil {
+		log.Printf("Failed to purge expired opens: %v\n", err)
+	}
+
+	if clicks.RowsAffected() > 0 || opens.RowsAffected() > 0 {
+		log.Printf("Purged %d clicks and %d opens past their retention period\n", clicks.RowsAffected(), opens.RowsAffected())
+	}
+
+	_, err = database.DB.Exec(ctx, "DELETE FROM tracking_salts WHERE day < $1", time.Now().UTC().Format(time.DateOnly))
+	if err != nil {
+		log.Printf("Failed to delete past salts: %v\n", err)
+	}
+}
@@ -0,0 +1,221 @@
+package tracking
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
+func TestLoadScannerRanges(t *testing.T) {
+	ranges, err := LoadScannerRanges("scanner_ranges.txt")
+	if err != nil {
+		t.Fatalf("LoadScannerRanges: %v", err)
+	}
+	if len(ranges) == 0 {
+		t.Fatalf("the bundled list has no ranges")
+	}
+
+	path := filepath.Join(t.TempDir(), "ranges.txt")
+	os.WriteFile(path, []byte("# comment\n\n10.1.2.3/16 gateway\n2001:db8::/32\n"), 0o600)
+	ranges, err = LoadScannerRanges(path)
+	if err != nil {
+		t.Fatalf("LoadScannerRanges: %v", err)
+	}
+	if len(ranges) != 2 || ranges[0].Prefix.String() != "10.1.0.0/16" || ranges[0].Name != "gateway" || ranges[1].Name != "scanner" {
+		t.Errorf("ranges = %+v", ranges)
+	}
+
+	os.WriteFile(path, []byte("10.0.0.0/8\nnot-a-range\n"), 0o600)
+	if _, err := LoadScannerRanges(path); err == nil {
+		t.Errorf("invalid range loaded without error")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.txt")
+	os.WriteFile(path, []byte("203.0.113.0/24 safelinks\n"), 0o600)
+	ranges, err := LoadScannerRanges(path)
+	if err != nil {
+		t.Fatalf("LoadScannerRanges: %v", err)
+	}
+	ScannerRanges = ranges
+	t.Cleanup(func() { ScannerRanges = nil })
+
+	tests := []struct {
+		ip, userAgent string
+		want          Classification
+	}{
+		{"198.51.100.7", chromeWindows, Classification{Traffic: TrafficHuman}},
+		{"203.0.113.9", chromeWindows, Classification{Traffic: TrafficScanner, Reason: "ip_range:safelinks"}},
+		{"::ffff:203.0.113.9", chromeWindows, Classification{Traffic: TrafficScanner, Reason: "ip_range:safelinks"}},
+		{"198.51.100.7", "Mimecast URL Protect", Classification{Traffic: TrafficScanner, Reason: "user_agent:mimecast"}},
+		{"198.51.100.7", "Googlebot/2.1", Classification{Traffic: TrafficBot, Reason: "user_agent:bot"}},
+		{"198.51.100.7", "curl/8.4.0", Classification{Traffic: TrafficBot, Reason: "user_agent:curl/"}},
+		{"198.51.100.7", " ", Classification{Traffic: TrafficBot, Reason: "user_agent:empty"}},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.ip, tt.userAgent); got != tt.want {
+			t.Errorf("Classify(%s, %q) = %+v, want %+v", tt.ip, tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSendTime(t *testing.T) {
+	sent := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	human := Classification{Traffic: TrafficHuman}
+	tests := []struct {
+		name    string
+		c       Classification
+		sent    time.Time
+		clicked time.Time
+		want    Classification
+	}{
+		{"unknown send time", human, time.Time{}, sent, human},
+		{"seconds after sending", human, sent, sent.Add(3 * time.Second), Classification{Traffic: TrafficScanner, Reason: ReasonSendTime}},
+		{"minutes after sending", human, sent, sent.Add(2 * time.Minute), human},
+		{"before sending", human, sent, sent.Add(-time.Second), human},
+		{"bots stay bots", Classification{Traffic: TrafficBot, Reason: "user_agent:curl/"}, sent, sent, Classification{Traffic: TrafficBot, Reason: "user_agent:curl/"}},
+	}
+	for _, tt := range tests {
+		if got := tt.c.CheckSendTime(tt.sent, tt.clicked); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBurstDetector(t *testing.T) {
+	d := NewBurstDetector(5 * time.Second)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if got := d.Record("203.0.113.9", "sig-1", "link-a", "click-1", start); got != nil {
+		t.Errorf("first click = %v, want no burst", got)
+	}
+	// The same link again is a person clicking twice
+	if got := d.Record("203.0.113.9", "sig-1", "link-a", "click-2", start.Add(time.Second)); got != nil {
+		t.Errorf("repeat click = %v, want no burst", got)
+	}
+	// Another IP address or signature is someone else
+	d.Record("198.51.100.7", "sig-1", "link-b", "click-x", start.Add(time.Second))
+	d.Record("203.0.113.9", "sig-2", "link-c", "click-y", start.Add(time.Second))
+
+	got := d.Record("203.0.113.9", "sig-1", "link-b", "click-3", start.Add(2*time.Second))
+	sort.Strings(got)
+	if want := []string{"click-1", "click-2", "click-3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("burst = %v, want %v", got, want)
+	}
+	if got := d.Record("203.0.113.9", "sig-1", "link-c", "click-4", start.Add(3*time.Second)); !reflect.DeepEqual(got, []string{"click-4"}) {
+		t.Errorf("click joining a burst = %v, want only the new click", got)
+	}
+
+	// Clicks outside the window are not part of the burst
+	if got := d.Record("198.51.100.7", "sig-1", "link-c", "click-z", start.Add(10*time.Second)); got != nil {
+		t.Errorf("click after the window = %v, want no burst", got)
+	}
+	if got := d.Record("", "sig-1", "link-a", "click-5", start); got != nil {
+		t.Errorf("click without IP address = %v, want no burst", got)
+	}
+
+	d.Record("192.0.2.1", "sig-3", "link-d", "click-6", start.Add(time.Minute))
+	if len(d.recent) != 1 {
+		t.Errorf("%d IP addresses kept, want those without clicks in the window swept", len(d.recent))
+	}
+}
+
+func TestParseDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      Device
+	}{
+		{chromeWindows, Device{Type: DeviceDesktop, OS: "Windows", Browser: "Chrome"}},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", Device{Type: DeviceMobile, OS: "iOS", Browser: "Safari"}},
+		{"Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", Device{Type: DeviceTablet, OS: "Android", Browser: "Chrome"}},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0", Device{Type: DeviceDesktop, OS: "Windows", Browser: "Edge"}},
+		{"Microsoft Office/16.0 (Windows NT 10.0; Microsoft Outlook 16.0.17126; Pro)", Device{Type: DeviceDesktop, OS: "Windows", Browser: "Outlook"}},
+		{"", Device{}},
+	}
+	for _, tt := range tests {
+		if got := ParseDevice(tt.userAgent); got != tt.want {
+			t.Errorf("ParseDevice(%q) = %+v, want %+v", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestUTM(t *testing.T) {
+	utm := UTM{Campaign: "spring"}.Or(UTM{Source: "newsletter", Campaign: "ignored"}).Or(UTM{Source: DefaultUTMSource, Medium: DefaultUTMMedium})
+	if utm != (UTM{Source: "newsletter", Medium: DefaultUTMMedium, Campaign: "spring"}) {
+		t.Errorf("Or = %+v", utm)
+	}
+
+	tests := []struct {
+		destination string
+		want        string
+	}{
+		{"https://example.com/pricing", "https://example.com/pricing?utm_campaign=spring&utm_medium=email&utm_source=newsletter"},
+		{"https://example.com/?ref=x&utm_source=partner", "https://example.com/?ref=x&utm_source=partner&utm_campaign=spring&utm_medium=email"},
+		{"mailto:sales@example.com", "mailto:sales@example.com"},
+	}
+	for _, tt := range tests {
+		if got := utm.Apply(tt.destination); got != tt.want {
+			t.Errorf("Apply(%s) = %s, want %s", tt.destination, got, tt.want)
+		}
+	}
+
+	disabled := utm.Or(UTM{Disabled: true})
+	if got := disabled.Apply("https://example.com"); got != "https://example.com" {
+		t.Errorf("disabled Apply = %s", got)
+	}
+
+	long := make([]byte, maxUTMLength+1)
+	for i := range long {
+		long[i] = 'x'
+	}
+	if err := (UTM{Content: string(long)}).Validate(); err == nil {
+		t.Errorf("overlong utm_content passed validation")
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.9":           "203.0.113.0",
+		"::ffff:203.0.113.9":    "203.0.113.0",
+		"2001:db8:1234:5678::1": "2001:db8:1234::",
+		"not-an-ip":             "",
+	}
+	for ip, want := range tests {
+		if got := TruncateIP(ip); got != want {
+			t.Errorf("TruncateIP(%s) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	salt := []byte("salt")
+	if Hash(salt, "ab", "c") == Hash(salt, "a", "bc") {
+		t.Errorf("values are not separated")
+	}
+	if Hash(salt, "203.0.113.9") == Hash([]byte("other"), "203.0.113.9") {
+		t.Errorf("the salt does not change the hash")
+	}
+	if len(Hash(salt, "x")) != 32 {
+		t.Errorf("hash = %s, want 16 bytes in hex", Hash(salt, "x"))
+	}
+}
+
+func TestImageProxy(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Windows NT 5.1; rv:11.0) Gecko Firefox/11.0 (via ggpht.com GoogleImageProxy)": ProxyGmail,
+		"YahooMailProxy; https://help.yahoo.com/kb/yahoo-mail-proxy-SLN28749.html":                  ProxyYahoo,
+		"Mozilla/5.0": ProxyApple,
+		chromeWindows: "",
+	}
+	for userAgent, want := range tests {
+		if got := ImageProxy(userAgent); got != want {
+			t.Errorf("ImageProxy(%q) = %q, want %q", userAgent, got, want)
+		}
+	}
+}
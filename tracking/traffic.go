@@ -0,0 +1,155 @@
+package tracking
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kinds of traffic a click can come from
+const (
+	TrafficHuman = "human"
+	// TrafficBot is crawlers, link previews and HTTP libraries
+	TrafficBot = "bot"
+	// TrafficScanner is mail security gateways such as Microsoft Safe
+	// Links, Mimecast and Proofpoint, which follow every link of a message
+	// before the recipient sees it
+	TrafficScanner = "scanner"
+)
+
+// ScanBurstWindow is how close together clicks from one IP address on
+// different links of a signature have to be to count as a gateway
+// scanning the message on delivery
+const ScanBurstWindow = 5 * time.Second
+
+// ReasonBurst explains clicks classified by ScanBurstWindow
+const ReasonBurst = "burst"
+
+// SendWindow is how soon after a message was sent a click has to come to
+// count as a gateway scanning it on delivery; people take longer to open
+// a message and click
+const SendWindow = 10 * time.Second
+
+// ReasonSendTime explains clicks classified by SendWindow
+const ReasonSendTime = "send_time"
+
+// Classification is the kind of traffic a click came from and the rule
+// that decided it
+type Classification struct {
+	Traffic string
+	Reason  string
+}
+
+// ScannerRange is an IP range used by a mail security gateway
+type ScannerRange struct {
+	Prefix netip.Prefix
+	Name   string
+}
+
+// ScannerRanges are the known gateway ranges loaded by Setup
+var ScannerRanges []ScannerRange
+
+// scannerAgents mark user agents of mail security gateways
+var scannerAgents = []string{"mimecast", "proofpoint", "barracuda", "safelinks", "forcepoint", "ironport", "trendmicro"}
+
+// botAgents mark user agents of crawlers, link previews and HTTP clients
+var botAgents = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "facebookexternalhit", "headlesschrome", "phantomjs",
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client", "java/", "okhttp", "libwww-perl",
+}
+
+// Setup reads the privacy settings and loads the scanner IP ranges in
+// SCANNER_IP_RANGES_FILE and the geo-IP database in GEOIP_DATABASE_FILE,
+// if set
+func Setup() {
+	setupPrivacy()
+	setupGeo()
+
+	path := os.Getenv("SCANNER_IP_RANGES_FILE")
+	if path == "" {
+		return
+	}
+	ranges, err := LoadScannerRanges(path)
+	if err != nil {
+		log.Fatalf("Failed to load scanner IP ranges: %v", err)
+	}
+	ScannerRanges = ranges
+	log.Printf("Loaded %d scanner IP ranges\n", len(ranges))
+}
+
+// LoadScannerRanges reads a file with one CIDR range per line, each
+// optionally followed by the gateway's name. Blank lines and lines
+// starting with # are skipped.
+func LoadScannerRanges(path string) ([]ScannerRange, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ranges []ScannerRange
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		name := "scanner"
+		if len(fields) > 1 {
+			name = fields[1]
+		}
+		ranges = append(ranges, ScannerRange{Prefix: prefix.Masked(), Name: name})
+	}
+	return ranges, scanner.Err()
+}
+
+// Classify decides the traffic a click came from by its IP address and
+// user agent. Bursts of clicks are caught separately, see BurstDetector,
+// and clicks right after sending by CheckSendTime.
+func Classify(ip, userAgent string) Classification {
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		addr = addr.Unmap()
+		for _, r := range ScannerRanges {
+			if r.Prefix.Contains(addr) {
+				return Classification{Traffic: TrafficScanner, Reason: "ip_range:" + r.Name}
+			}
+		}
+	}
+
+	agent := strings.ToLower(strings.TrimSpace(userAgent))
+	if agent == "" {
+		return Classification{Traffic: TrafficBot, Reason: "user_agent:empty"}
+	}
+	for _, marker := range scannerAgents {
+		if strings.Contains(agent, marker) {
+			return Classification{Traffic: TrafficScanner, Reason: "user_agent:" + marker}
+		}
+	}
+	for _, marker := range botAgents {
+		if strings.Contains(agent, marker) {
+			return Classification{Traffic: TrafficBot, Reason: "user_agent:" + marker}
+		}
+	}
+	return Classification{Traffic: TrafficHuman}
+}
+
+// CheckSendTime reclassifies a human click as a scanner when it came
+// within SendWindow of the message being sent. sent is zero when the send
+// time is unknown.
+func (c Classification) CheckSendTime(sent, clicked time.Time) Classification {
+	if c.Traffic != TrafficHuman || sent.IsZero() {
+		return c
+	}
+	if elapsed := clicked.Sub(sent); elapsed >= 0 && elapsed < SendWindow {
+		return Classification{Traffic: TrafficScanner, Reason: ReasonSendTime}
+	}
+	return c
+}
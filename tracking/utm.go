@@ -0,0 +1,91 @@
+package tracking
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Default UTM parameters of signature links
+const (
+	DefaultUTMSource = "email_signature"
+	DefaultUTMMedium = "email"
+)
+
+// maxUTMLength bounds each UTM value
+const maxUTMLength = 100
+
+// UTM holds the UTM parameters added to a link's destination. Empty values
+// are left to the next, less specific, set of parameters.
+type UTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Content  string `json:"content,omitempty"`
+	// Disabled leaves destinations untouched
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Validate checks the length of each value
+func (u UTM) Validate() error {
+	for name, value := range map[string]string{"source": u.Source, "medium": u.Medium, "campaign": u.Campaign, "content": u.Content} {
+		if len(value) > maxUTMLength {
+			return fmt.Errorf("UTM %s must be at most %d characters", name, maxUTMLength)
+		}
+	}
+	return nil
+}
+
+// Or fills in the values u leaves empty from fallback. Either one can
+// disable tagging.
+func (u UTM) Or(fallback UTM) UTM {
+	if u.Source == "" {
+		u.Source = fallback.Source
+	}
+	if u.Medium == "" {
+		u.Medium = fallback.Medium
+	}
+	if u.Campaign == "" {
+		u.Campaign = fallback.Campaign
+	}
+	if u.Content == "" {
+		u.Content = fallback.Content
+	}
+	u.Disabled = u.Disabled || fallback.Disabled
+	return u
+}
+
+// Apply adds the parameters to an http(s) destination. Parameters the
+// destination already has are kept, as are destinations that are not
+// http(s) URLs.
+func (u UTM) Apply(destination string) string {
+	if u.Disabled {
+		return destination
+	}
+	parsed, err := url.Parse(destination)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return destination
+	}
+
+	// Append rather than re-encode so the destination's own query is
+	// passed on unchanged
+	query := parsed.Query()
+	added := url.Values{}
+	for _, param := range []struct{ name, value string }{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+		{"utm_content", u.Content},
+	} {
+		if param.value != "" && !query.Has(param.name) {
+			added.Set(param.name, param.value)
+		}
+	}
+	if len(added) == 0 {
+		return destination
+	}
+	if parsed.RawQuery != "" {
+		parsed.RawQuery += "&"
+	}
+	parsed.RawQuery += added.Encode()
+	return parsed.String()
+}